@@ -0,0 +1,57 @@
+package app
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"github.com/mokiat/lacking-gl/render/capture"
+	"github.com/mokiat/lacking/render"
+)
+
+func startCapture(path string, api render.API, width, height int) (*captureSession, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create capture file: %w", err)
+	}
+	writer := bufio.NewWriter(file)
+	recorder, err := capture.NewRecorder(api, writer, width, height)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to create recorder: %w", err)
+	}
+	return &captureSession{
+		file:     file,
+		writer:   writer,
+		recorder: recorder,
+	}, nil
+}
+
+// captureSession records all render API calls of a loop to a file.
+type captureSession struct {
+	file     *os.File
+	writer   *bufio.Writer
+	recorder *capture.Recorder
+}
+
+// EndFrame marks the end of a rendered frame. It is safe to call
+// on a nil session.
+func (s *captureSession) EndFrame() {
+	if s != nil {
+		s.recorder.EndFrame()
+	}
+}
+
+// Close completes the capture and logs any errors, since there is
+// nothing else that can be done about them at that point.
+func (s *captureSession) Close() {
+	if err := s.recorder.Close(); err != nil {
+		appLogger.Error("Error recording capture: %v", err)
+	}
+	if err := s.writer.Flush(); err != nil {
+		appLogger.Error("Error writing capture file: %v", err)
+	}
+	if err := s.file.Close(); err != nil {
+		appLogger.Error("Error closing capture file: %v", err)
+	}
+}
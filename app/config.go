@@ -20,20 +20,25 @@ func NewConfig(title string, width, height int) *Config {
 
 // Config represents an application window configuration.
 type Config struct {
-	locator       resource.ReadLocator
-	title         string
-	width         int
-	height        int
-	minWidth      *int
-	maxWidth      *int
-	minHeight     *int
-	maxHeight     *int
-	swapInterval  int
-	maximized     bool
-	fullscreen    bool
-	cursorVisible bool
-	cursor        *app.CursorDefinition
-	icon          string
+	locator         resource.ReadLocator
+	title           string
+	width           int
+	height          int
+	minWidth        *int
+	maxWidth        *int
+	minHeight       *int
+	maxHeight       *int
+	swapInterval    int
+	msaaSamples     int
+	maximized       bool
+	fullscreen      bool
+	cursorVisible   bool
+	cursor          *app.CursorDefinition
+	icon            string
+	captureFile     string
+	validation      bool
+	programCacheDir string
+	shaderDir       string
 }
 
 // SetMinSize sets a minimum size for the window.
@@ -92,6 +97,22 @@ func (c *Config) VSync() bool {
 	return c.swapInterval != 0
 }
 
+// SetMSAASamples specifies the number of samples per pixel of the default
+// framebuffer, which enables multisample anti-aliasing when it is larger
+// than one. The driver may pick a different count.
+//
+// This has no effect on headless applications, which render to a
+// single-sample offscreen framebuffer.
+func (c *Config) SetMSAASamples(samples int) {
+	c.msaaSamples = samples
+}
+
+// MSAASamples returns the number of samples per pixel that will be
+// requested for the default framebuffer.
+func (c *Config) MSAASamples() int {
+	return c.msaaSamples
+}
+
 // SetMaximized specifies whether the window should be
 // created in maximized state.
 func (c *Config) SetMaximized(maximized bool) {
@@ -164,3 +185,68 @@ func (c *Config) SetLocator(locator resource.ReadLocator) {
 func (c *Config) Locator() resource.ReadLocator {
 	return c.locator
 }
+
+// SetCaptureFile specifies a file to which all rendering calls will be
+// recorded, so that they can be replayed later with the lacking-gl-replay
+// tool.
+//
+// An empty string value indicates that no capture should be made.
+func (c *Config) SetCaptureFile(path string) {
+	c.captureFile = path
+}
+
+// CaptureFile returns the file to which rendering calls will be
+// recorded.
+func (c *Config) CaptureFile() string {
+	return c.captureFile
+}
+
+// SetRenderValidation specifies whether all render API calls should be
+// validated. Misuse of the API is logged together with the stack of the
+// caller.
+//
+// Validation has a performance cost and is meant for development only.
+func (c *Config) SetRenderValidation(enabled bool) {
+	c.validation = enabled
+}
+
+// RenderValidation returns whether render API calls will be validated.
+func (c *Config) RenderValidation() bool {
+	return c.validation
+}
+
+// SetProgramCacheDir specifies a directory in which linked shader
+// programs are cached, so that they need not be compiled on subsequent
+// runs. The cache is invalidated automatically when the graphics driver
+// changes.
+//
+// An empty string value indicates that programs should not be cached.
+func (c *Config) SetProgramCacheDir(dir string) {
+	c.programCacheDir = dir
+}
+
+// ProgramCacheDir returns the directory in which linked shader programs
+// are cached.
+func (c *Config) ProgramCacheDir() string {
+	return c.programCacheDir
+}
+
+// SetShaderDir specifies a directory from which shader templates are
+// loaded instead of the ones that are embedded into the binary. The
+// directory is watched for changes and affected programs are rebuilt
+// at the next frame. Programs that fail to build keep their last good
+// version. This is meant to be used during development.
+//
+// The directory needs to contain all templates, which can be achieved
+// by pointing it to the shader directory of this module's source code.
+//
+// An empty string value indicates that the embedded templates are used.
+func (c *Config) SetShaderDir(dir string) {
+	c.shaderDir = dir
+}
+
+// ShaderDir returns the directory from which shader templates are
+// loaded.
+func (c *Config) ShaderDir() string {
+	return c.shaderDir
+}
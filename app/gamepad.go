@@ -22,6 +22,19 @@ func newGamepad(joystick glfw.Joystick) *Gamepad {
 	}
 }
 
+// newDisconnectedGamepad creates a Gamepad that is not backed by any
+// joystick and always reports that it is disconnected.
+func newDisconnectedGamepad() *Gamepad {
+	return &Gamepad{
+		isDirty:     false,
+		isConnected: false,
+		isSupported: false,
+
+		deadzoneStick:   0.1,
+		deadzoneTrigger: 0.0,
+	}
+}
+
 type Gamepad struct {
 	joystick glfw.Joystick
 
@@ -0,0 +1,75 @@
+package app
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/go-gl/gl/v4.6-core/gl"
+
+	"github.com/mokiat/lacking-gl/render/validation"
+	"github.com/mokiat/lacking/app"
+)
+
+// RunHeadless starts a new application that has no window and instead
+// renders to an offscreen framebuffer of the configured size.
+//
+// The OpenGL context is created through EGL without a surface, which
+// allows the application to run on machines that have no display (e.g.
+// CI servers). When no GPU is available, Mesa's software rasterizer can
+// be used by setting the LIBGL_ALWAYS_SOFTWARE=1 environment variable.
+//
+// Window-specific settings of the configuration (e.g. fullscreen, icon,
+// cursor) are ignored.
+//
+// The specified controller will be used to send notifications
+// on window state changes.
+func RunHeadless(cfg *Config, controller app.Controller) error {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	context, err := newHeadlessContext()
+	if err != nil {
+		return fmt.Errorf("failed to create headless context: %w", err)
+	}
+	defer context.Destroy()
+
+	if err := gl.InitWithProcAddrFunc(context.ProcAddress); err != nil {
+		return fmt.Errorf("failed to initialize opengl: %w", err)
+	}
+
+	if glLogger.DebugEnabled() {
+		enableDebugOutput()
+	}
+
+	l := newHeadlessLoop(cfg.title, cfg.width, cfg.height, controller)
+
+	if cfg.programCacheDir != "" {
+		if err := useProgramCache(cfg.programCacheDir, l.renderAPI); err != nil {
+			return fmt.Errorf("failed to set up program cache: %w", err)
+		}
+	}
+
+	if cfg.shaderDir != "" {
+		watcher, err := watchShaders(cfg.shaderDir, l, l.renderAPI)
+		if err != nil {
+			return fmt.Errorf("failed to watch shaders: %w", err)
+		}
+		defer watcher.Close()
+	}
+
+	if cfg.captureFile != "" {
+		session, err := startCapture(cfg.captureFile, l.renderAPI, cfg.width, cfg.height)
+		if err != nil {
+			return fmt.Errorf("failed to start capture: %w", err)
+		}
+		defer session.Close()
+		l.renderAPI = session.recorder
+		l.capture = session
+	}
+
+	if cfg.validation {
+		l.renderAPI = validation.NewAPI(l.renderAPI, nil)
+	}
+
+	return l.Run()
+}
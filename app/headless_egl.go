@@ -0,0 +1,116 @@
+//go:build linux
+
+package app
+
+/*
+#cgo LDFLAGS: -lEGL
+#include <stdlib.h>
+#include <EGL/egl.h>
+#include <EGL/eglext.h>
+
+static EGLDisplay lackingGetHeadlessDisplay() {
+	PFNEGLGETPLATFORMDISPLAYEXTPROC getPlatformDisplay =
+		(PFNEGLGETPLATFORMDISPLAYEXTPROC)eglGetProcAddress("eglGetPlatformDisplayEXT");
+	if (getPlatformDisplay != NULL) {
+		EGLDisplay display = getPlatformDisplay(EGL_PLATFORM_SURFACELESS_MESA, EGL_DEFAULT_DISPLAY, NULL);
+		if (display != EGL_NO_DISPLAY) {
+			return display;
+		}
+	}
+	return eglGetDisplay(EGL_DEFAULT_DISPLAY);
+}
+
+static EGLContext lackingCreateHeadlessContext(EGLDisplay display) {
+	const EGLint configAttribs[] = {
+		EGL_SURFACE_TYPE, EGL_PBUFFER_BIT,
+		EGL_RENDERABLE_TYPE, EGL_OPENGL_BIT,
+		EGL_NONE,
+	};
+	EGLConfig config;
+	EGLint configCount = 0;
+	if (!eglChooseConfig(display, configAttribs, &config, 1, &configCount) || configCount == 0) {
+		return EGL_NO_CONTEXT;
+	}
+	// Software rasterizers do not always support OpenGL 4.6, in which
+	// case 4.5 is used, since it already provides all the DSA functions
+	// that are needed by the renderer.
+	const EGLint minorVersions[] = {6, 5};
+	for (int i = 0; i < 2; i++) {
+		const EGLint contextAttribs[] = {
+			EGL_CONTEXT_MAJOR_VERSION, 4,
+			EGL_CONTEXT_MINOR_VERSION, minorVersions[i],
+			EGL_CONTEXT_OPENGL_PROFILE_MASK, EGL_CONTEXT_OPENGL_CORE_PROFILE_BIT,
+			EGL_CONTEXT_OPENGL_FORWARD_COMPATIBLE, EGL_TRUE,
+			EGL_NONE,
+		};
+		EGLContext context = eglCreateContext(display, config, EGL_NO_CONTEXT, contextAttribs);
+		if (context != EGL_NO_CONTEXT) {
+			return context;
+		}
+	}
+	return EGL_NO_CONTEXT;
+}
+
+static void* lackingGetProcAddress(const char* name) {
+	return (void*)eglGetProcAddress(name);
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+)
+
+func newHeadlessContext() (*headlessContext, error) {
+	display := C.lackingGetHeadlessDisplay()
+	if display == C.EGLDisplay(C.EGL_NO_DISPLAY) {
+		return nil, errors.New("failed to get egl display")
+	}
+	if C.eglInitialize(display, nil, nil) == C.EGL_FALSE {
+		return nil, fmt.Errorf("failed to initialize egl: %w", eglError())
+	}
+	if C.eglBindAPI(C.EGL_OPENGL_API) == C.EGL_FALSE {
+		err := eglError()
+		C.eglTerminate(display)
+		return nil, fmt.Errorf("failed to bind opengl api: %w", err)
+	}
+	context := C.lackingCreateHeadlessContext(display)
+	if context == C.EGLContext(C.EGL_NO_CONTEXT) {
+		err := eglError()
+		C.eglTerminate(display)
+		return nil, fmt.Errorf("failed to create egl context: %w", err)
+	}
+	if C.eglMakeCurrent(display, C.EGLSurface(C.EGL_NO_SURFACE), C.EGLSurface(C.EGL_NO_SURFACE), context) == C.EGL_FALSE {
+		err := eglError()
+		C.eglDestroyContext(display, context)
+		C.eglTerminate(display)
+		return nil, fmt.Errorf("failed to make egl context current: %w", err)
+	}
+	return &headlessContext{
+		display: display,
+		context: context,
+	}, nil
+}
+
+type headlessContext struct {
+	display C.EGLDisplay
+	context C.EGLContext
+}
+
+func (c *headlessContext) ProcAddress(name string) unsafe.Pointer {
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+	return C.lackingGetProcAddress(cName)
+}
+
+func (c *headlessContext) Destroy() {
+	C.eglMakeCurrent(c.display, C.EGLSurface(C.EGL_NO_SURFACE), C.EGLSurface(C.EGL_NO_SURFACE), C.EGLContext(C.EGL_NO_CONTEXT))
+	C.eglDestroyContext(c.display, c.context)
+	C.eglTerminate(c.display)
+}
+
+func eglError() error {
+	return fmt.Errorf("egl error 0x%x", int(C.eglGetError()))
+}
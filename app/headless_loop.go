@@ -0,0 +1,200 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-gl/gl/v4.6-core/gl"
+	glrender "github.com/mokiat/lacking-gl/render"
+	"github.com/mokiat/lacking/app"
+	"github.com/mokiat/lacking/audio"
+	"github.com/mokiat/lacking/render"
+)
+
+func newHeadlessLoop(title string, width, height int, controller app.Controller) *headlessLoop {
+	return &headlessLoop{
+		title:      title,
+		width:      width,
+		height:     height,
+		controller: controller,
+		renderAPI:  glrender.NewOffscreenAPI(width, height),
+		tasks:      make(chan func(), taskQueueSize),
+		wake:       make(chan struct{}, 1),
+		shouldStop: false,
+		shouldDraw: true,
+		shouldWake: true,
+		gamepads: [4]*Gamepad{
+			newDisconnectedGamepad(),
+			newDisconnectedGamepad(),
+			newDisconnectedGamepad(),
+			newDisconnectedGamepad(),
+		},
+	}
+}
+
+var _ app.Window = (*headlessLoop)(nil)
+
+type headlessLoop struct {
+	title      string
+	width      int
+	height     int
+	controller app.Controller
+	renderAPI  render.API
+	capture    *captureSession
+	tasks      chan func()
+	wake       chan struct{}
+	shouldStop bool
+	shouldDraw bool
+	shouldWake bool
+	gamepads   [4]*Gamepad
+}
+
+func (l *headlessLoop) Run() error {
+	l.controller.OnCreate(l)
+	l.controller.OnResize(l, l.width, l.height)
+	l.controller.OnFramebufferResize(l, l.width, l.height)
+
+	for !l.shouldStop {
+		if l.shouldWake {
+			l.shouldWake = false
+		} else {
+			l.waitEvents()
+		}
+
+		if !l.processTasks(taskProcessingTimeout) {
+			// Not all events were processed, loop should not
+			// block on next iteration.
+			l.shouldWake = true
+		}
+
+		if l.shouldDraw {
+			l.shouldDraw = false
+			l.controller.OnRender(l)
+			l.capture.EndFrame()
+			gl.Flush()
+		}
+	}
+
+	l.controller.OnDestroy(l)
+
+	// Give any async tasks a chance to complete.
+	if !l.processTasks(5 * time.Second) {
+		return fmt.Errorf("failed to cleanup within timeout")
+	}
+
+	return nil
+}
+
+func (l *headlessLoop) Title() string {
+	return l.title
+}
+
+func (l *headlessLoop) SetTitle(title string) {
+	l.title = title
+}
+
+func (l *headlessLoop) Size() (int, int) {
+	return l.width, l.height
+}
+
+func (l *headlessLoop) SetSize(width, height int) {
+	// The offscreen framebuffer has a fixed size.
+}
+
+func (l *headlessLoop) FramebufferSize() (int, int) {
+	return l.width, l.height
+}
+
+func (l *headlessLoop) Gamepads() [4]app.Gamepad {
+	var result [4]app.Gamepad
+	for i := range result {
+		result[i] = l.gamepads[i]
+	}
+	return result
+}
+
+func (l *headlessLoop) Schedule(fn func()) {
+	select {
+	case l.tasks <- fn:
+		l.postEmptyEvent()
+	default:
+		panic(fmt.Errorf("failed to queue task; queue is full"))
+	}
+}
+
+func (l *headlessLoop) Invalidate() {
+	if !l.shouldDraw {
+		l.shouldDraw = true
+		if !l.shouldWake {
+			l.shouldWake = true
+			l.postEmptyEvent()
+		}
+	}
+}
+
+func (l *headlessLoop) CreateCursor(definition app.CursorDefinition) app.Cursor {
+	return &headlessCursor{}
+}
+
+func (l *headlessLoop) UseCursor(cursor app.Cursor) {}
+
+func (l *headlessLoop) CursorVisible() bool {
+	return false
+}
+
+func (l *headlessLoop) SetCursorVisible(visible bool) {}
+
+func (l *headlessLoop) SetCursorLocked(locked bool) {}
+
+func (l *headlessLoop) RenderAPI() render.API {
+	return l.renderAPI
+}
+
+func (l *headlessLoop) AudioAPI() audio.API {
+	return nil
+}
+
+func (l *headlessLoop) Close() {
+	if !l.shouldStop {
+		l.shouldStop = true
+		l.postEmptyEvent()
+	}
+}
+
+// waitEvents blocks until a task is scheduled or the loop is woken up.
+func (l *headlessLoop) waitEvents() {
+	select {
+	case task := <-l.tasks:
+		task()
+	case <-l.wake:
+	}
+}
+
+func (l *headlessLoop) postEmptyEvent() {
+	select {
+	case l.wake <- struct{}{}:
+	default:
+		// There is already a pending wake up.
+	}
+}
+
+func (l *headlessLoop) processTasks(limit time.Duration) bool {
+	startTime := time.Now()
+	for time.Since(startTime) < limit {
+		select {
+		case task := <-l.tasks:
+			// There was a task in the queue so run it.
+			task()
+		default:
+			// No more tasks, we have consumed everything there
+			// is for now.
+			return true
+		}
+	}
+	// We did not consume all available tasks within our time window.
+	return false
+}
+
+type headlessCursor struct{}
+
+func (c *headlessCursor) Destroy() {}
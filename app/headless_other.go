@@ -0,0 +1,20 @@
+//go:build !linux
+
+package app
+
+import (
+	"errors"
+	"unsafe"
+)
+
+func newHeadlessContext() (*headlessContext, error) {
+	return nil, errors.New("headless mode is only supported on linux")
+}
+
+type headlessContext struct{}
+
+func (c *headlessContext) ProcAddress(name string) unsafe.Pointer {
+	return nil
+}
+
+func (c *headlessContext) Destroy() {}
@@ -46,6 +46,7 @@ type loop struct {
 	window        *glfw.Window
 	controller    app.Controller
 	renderAPI     render.API
+	capture       *captureSession
 	tasks         chan func()
 	shouldStop    bool
 	shouldDraw    bool
@@ -103,6 +104,7 @@ func (l *loop) Run() error {
 		if l.shouldDraw {
 			l.shouldDraw = false
 			l.controller.OnRender(l)
+			l.capture.EndFrame()
 			l.window.SwapBuffers()
 		}
 	}
@@ -243,6 +245,7 @@ func (l *loop) processTasks(limit time.Duration) bool {
 
 func (l *loop) onGLFWRefresh(w *glfw.Window) {
 	l.controller.OnRender(l)
+	l.capture.EndFrame()
 	l.window.SwapBuffers()
 }
 
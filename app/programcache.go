@@ -0,0 +1,18 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/mokiat/lacking-gl/render/ext"
+	"github.com/mokiat/lacking-gl/render/programcache"
+	"github.com/mokiat/lacking/render"
+)
+
+func useProgramCache(dir string, api render.API) error {
+	cache, err := programcache.NewDiskCache(dir, programcache.DefaultMaxSize)
+	if err != nil {
+		return fmt.Errorf("failed to create disk cache: %w", err)
+	}
+	api.(ext.API).SetProgramCache(cache)
+	return nil
+}
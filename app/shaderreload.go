@@ -0,0 +1,20 @@
+package app
+
+import (
+	"github.com/mokiat/lacking-gl/render/ext"
+	"github.com/mokiat/lacking-gl/shader"
+	"github.com/mokiat/lacking/app"
+	"github.com/mokiat/lacking/render"
+)
+
+func watchShaders(dir string, window app.Window, api render.API) (*shader.Watcher, error) {
+	extAPI := api.(ext.API)
+	return shader.WatchDir(dir, func() {
+		// The programs are rebuilt on the render thread, before the
+		// next frame is drawn.
+		window.Schedule(func() {
+			extAPI.ReloadPrograms(shader.Regenerate)
+			window.Invalidate()
+		})
+	})
+}
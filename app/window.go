@@ -9,6 +9,7 @@ import (
 	"github.com/go-gl/gl/v4.6-core/gl"
 	"github.com/go-gl/glfw/v3.3/glfw"
 
+	"github.com/mokiat/lacking-gl/render/validation"
 	"github.com/mokiat/lacking/app"
 	"github.com/mokiat/lacking/log"
 )
@@ -50,6 +51,9 @@ func Run(cfg *Config, controller app.Controller) error {
 	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
 	glfw.WindowHint(glfw.OpenGLForwardCompatible, glfw.True)
 	glfw.WindowHint(glfw.SRGBCapable, glfw.True)
+	if cfg.msaaSamples > 1 {
+		glfw.WindowHint(glfw.Samples, cfg.msaaSamples)
+	}
 	if cfg.maximized {
 		glfw.WindowHint(glfw.Maximized, glfw.True)
 	}
@@ -97,23 +101,40 @@ func Run(cfg *Config, controller app.Controller) error {
 	}
 
 	if glLogger.DebugEnabled() {
-		gl.Enable(gl.DEBUG_OUTPUT)
-		gl.DebugMessageCallback(func(source uint32, gltype uint32, id uint32, severity uint32, length int32, message string, userParam unsafe.Pointer) {
-			switch severity {
-			case gl.DEBUG_SEVERITY_LOW:
-				glLogger.Debug(message)
-			case gl.DEBUG_SEVERITY_MEDIUM:
-				glLogger.Warn(message)
-			case gl.DEBUG_SEVERITY_HIGH:
-				glLogger.Error(message)
-			default:
-				glLogger.Debug(message)
-			}
-		}, gl.PtrOffset(0))
+		enableDebugOutput()
 	}
 
 	l := newLoop(cfg.locator, cfg.title, window, controller)
 
+	if cfg.programCacheDir != "" {
+		if err := useProgramCache(cfg.programCacheDir, l.renderAPI); err != nil {
+			return fmt.Errorf("failed to set up program cache: %w", err)
+		}
+	}
+
+	if cfg.shaderDir != "" {
+		watcher, err := watchShaders(cfg.shaderDir, l, l.renderAPI)
+		if err != nil {
+			return fmt.Errorf("failed to watch shaders: %w", err)
+		}
+		defer watcher.Close()
+	}
+
+	if cfg.captureFile != "" {
+		width, height := window.GetFramebufferSize()
+		session, err := startCapture(cfg.captureFile, l.renderAPI, width, height)
+		if err != nil {
+			return fmt.Errorf("failed to start capture: %w", err)
+		}
+		defer session.Close()
+		l.renderAPI = session.recorder
+		l.capture = session
+	}
+
+	if cfg.validation {
+		l.renderAPI = validation.NewAPI(l.renderAPI, nil)
+	}
+
 	if cfg.cursor != nil {
 		cursor := l.CreateCursor(*cfg.cursor)
 		defer cursor.Destroy()
@@ -127,3 +148,19 @@ func Run(cfg *Config, controller app.Controller) error {
 
 	return l.Run()
 }
+
+func enableDebugOutput() {
+	gl.Enable(gl.DEBUG_OUTPUT)
+	gl.DebugMessageCallback(func(source uint32, gltype uint32, id uint32, severity uint32, length int32, message string, userParam unsafe.Pointer) {
+		switch severity {
+		case gl.DEBUG_SEVERITY_LOW:
+			glLogger.Debug(message)
+		case gl.DEBUG_SEVERITY_MEDIUM:
+			glLogger.Warn(message)
+		case gl.DEBUG_SEVERITY_HIGH:
+			glLogger.Error(message)
+		default:
+			glLogger.Debug(message)
+		}
+	}, gl.PtrOffset(0))
+}
@@ -0,0 +1,108 @@
+// Command lacking-gl-replay replays a capture file that was recorded
+// through the app.Config.SetCaptureFile setting and writes the image of
+// each recorded frame as a PNG file.
+//
+// Usage:
+//
+//	lacking-gl-replay [-out directory] <capture-file>
+//
+// The replay runs in a headless context, so no display is required.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+
+	glapp "github.com/mokiat/lacking-gl/app"
+	glrender "github.com/mokiat/lacking-gl/render"
+	"github.com/mokiat/lacking-gl/render/capture"
+	"github.com/mokiat/lacking/app"
+)
+
+func main() {
+	outDir := flag.String("out", ".", "directory to which frame images are written")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] <capture-file>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(flag.Arg(0), *outDir); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(capturePath, outDir string) error {
+	file, err := os.Open(capturePath)
+	if err != nil {
+		return fmt.Errorf("failed to open capture file: %w", err)
+	}
+	defer file.Close()
+
+	replayer, err := capture.NewReplayer(file)
+	if err != nil {
+		return fmt.Errorf("failed to read capture file: %w", err)
+	}
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	header := replayer.Header()
+	controller := &replayController{
+		replayer: replayer,
+		outDir:   outDir,
+		width:    header.Width,
+		height:   header.Height,
+	}
+	cfg := glapp.NewConfig("lacking-gl-replay", header.Width, header.Height)
+	if err := glapp.RunHeadless(cfg, controller); err != nil {
+		return fmt.Errorf("failed to run replay: %w", err)
+	}
+	return errors.Join(controller.errs...)
+}
+
+type replayController struct {
+	app.NopController
+	replayer *capture.Replayer
+	outDir   string
+	width    int
+	height   int
+	errs     []error
+}
+
+func (c *replayController) OnRender(window app.Window) {
+	defer window.Close()
+
+	api := window.RenderAPI()
+	err := c.replayer.Replay(api, func(index int) {
+		img := glrender.ReadPixels(api, api.DefaultFramebuffer(), c.width, c.height)
+		path := filepath.Join(c.outDir, fmt.Sprintf("frame_%04d.png", index))
+		if err := writePNG(path, img); err != nil {
+			c.errs = append(c.errs, err)
+		}
+	})
+	if err != nil {
+		c.errs = append(c.errs, err)
+	}
+}
+
+func writePNG(path string, img *image.RGBA) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create image file: %w", err)
+	}
+	defer file.Close()
+	if err := png.Encode(file, img); err != nil {
+		return fmt.Errorf("failed to encode image %q: %w", path, err)
+	}
+	return nil
+}
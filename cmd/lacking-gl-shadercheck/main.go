@@ -0,0 +1,57 @@
+// Command lacking-gl-shadercheck builds every shader variant that the
+// game and UI shader collections can produce and reports the ones that
+// fail to compile or link, along with their diagnostics.
+//
+// Usage:
+//
+//	lacking-gl-shadercheck [-v]
+//
+// The check runs in a headless context, so no display is required. The
+// command exits with a non-zero status if any variant fails.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/mokiat/lacking-gl/game"
+	"github.com/mokiat/lacking-gl/shader/shadercheck"
+	"github.com/mokiat/lacking-gl/ui"
+)
+
+func main() {
+	verbose := flag.Bool("v", false, "list all checked variants")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	variants := append(
+		shadercheck.GameVariants(game.NewShaderCollection()),
+		shadercheck.UIVariants(ui.NewShaderCollection())...,
+	)
+	if *verbose {
+		for _, variant := range variants {
+			fmt.Println(variant.Name)
+		}
+	}
+
+	failures, err := shadercheck.Check(variants)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	for _, failure := range failures {
+		fmt.Fprintln(os.Stderr, failure)
+	}
+	fmt.Printf("%d variants checked, %d failed\n", len(variants), len(failures))
+	if len(failures) > 0 {
+		os.Exit(1)
+	}
+}
@@ -7,23 +7,40 @@ import (
 	"github.com/mokiat/lacking/game/graphics"
 )
 
+// NewShaderCollection creates a shader collection that uses the
+// templates of the default shader engine.
 func NewShaderCollection() graphics.ShaderCollection {
+	return NewEngineShaderCollection(shader.Default)
+}
+
+// NewEngineShaderCollection creates a shader collection that uses the
+// templates of the specified shader engine, which allows templates to be
+// overridden. Templates that fail to execute produce shaders that fail
+// to compile, instead of crashing the application.
+func NewEngineShaderCollection(engine *shader.Engine) graphics.ShaderCollection {
+	sets := shaderSets{
+		engine: engine,
+	}
 	return graphics.ShaderCollection{
-		ShadowMappingSet:    newShadowMappingSet,
-		PBRGeometrySet:      newPBRGeometrySet,
-		DirectionalLightSet: newDirectionalLightShaderSet,
-		AmbientLightSet:     newAmbientLightShaderSet,
-		PointLightSet:       newPointLightShaderSet,
-		SpotLightSet:        newSpotLightShaderSet,
-		SkyboxSet:           newSkyboxShaderSet,
-		SkycolorSet:         newSkycolorShaderSet,
-		DebugSet:            newDebugShaderSet,
-		ExposureSet:         newExposureShaderSet,
-		PostprocessingSet:   newPostprocessingShaderSet,
+		ShadowMappingSet:    sets.newShadowMappingSet,
+		PBRGeometrySet:      sets.newPBRGeometrySet,
+		DirectionalLightSet: sets.newDirectionalLightShaderSet,
+		AmbientLightSet:     sets.newAmbientLightShaderSet,
+		PointLightSet:       sets.newPointLightShaderSet,
+		SpotLightSet:        sets.newSpotLightShaderSet,
+		SkyboxSet:           sets.newSkyboxShaderSet,
+		SkycolorSet:         sets.newSkycolorShaderSet,
+		DebugSet:            sets.newDebugShaderSet,
+		ExposureSet:         sets.newExposureShaderSet,
+		PostprocessingSet:   sets.newPostprocessingShaderSet,
 	}
 }
 
-func newShadowMappingSet(cfg graphics.ShadowMappingShaderConfig) graphics.ShaderSet {
+type shaderSets struct {
+	engine *shader.Engine
+}
+
+func (sets shaderSets) newShadowMappingSet(cfg graphics.ShadowMappingShaderConfig) graphics.ShaderSet {
 	var settings struct {
 		UseArmature bool
 	}
@@ -31,12 +48,12 @@ func newShadowMappingSet(cfg graphics.ShadowMappingShaderConfig) graphics.Shader
 		settings.UseArmature = true
 	}
 	return graphics.ShaderSet{
-		VertexShader:   shader.RunTemplate("shadow.vert.glsl", settings),
-		FragmentShader: shader.RunTemplate("shadow.frag.glsl", settings),
+		VertexShader:   sets.engine.Source("shadow.vert.glsl", settings),
+		FragmentShader: sets.engine.Source("shadow.frag.glsl", settings),
 	}
 }
 
-func newPBRGeometrySet(cfg graphics.PBRGeometryShaderConfig) graphics.ShaderSet {
+func (sets shaderSets) newPBRGeometrySet(cfg graphics.PBRGeometryShaderConfig) graphics.ShaderSet {
 	var settings struct {
 		UseArmature       bool
 		UseAlphaTest      bool
@@ -58,72 +75,78 @@ func newPBRGeometrySet(cfg graphics.PBRGeometryShaderConfig) graphics.ShaderSet
 		settings.UseAlbedoTexture = true
 	}
 	return graphics.ShaderSet{
-		VertexShader:   shader.RunTemplate("pbr_geometry.vert.glsl", settings),
-		FragmentShader: shader.RunTemplate("pbr_geometry.frag.glsl", settings),
+		VertexShader:   sets.engine.Source("pbr_geometry.vert.glsl", settings),
+		FragmentShader: sets.engine.Source("pbr_geometry.frag.glsl", settings),
 	}
 }
 
-func newAmbientLightShaderSet() graphics.ShaderSet {
+func (sets shaderSets) newAmbientLightShaderSet() graphics.ShaderSet {
 	return graphics.ShaderSet{
-		VertexShader:   shader.RunTemplate("ambient_light.vert.glsl", struct{}{}),
-		FragmentShader: shader.RunTemplate("ambient_light.frag.glsl", struct{}{}),
+		VertexShader:   sets.engine.Source("ambient_light.vert.glsl", struct{}{}),
+		FragmentShader: sets.engine.Source("ambient_light.frag.glsl", struct{}{}),
 	}
 }
 
-func newPointLightShaderSet() graphics.ShaderSet {
+func (sets shaderSets) newPointLightShaderSet() graphics.ShaderSet {
 	return graphics.ShaderSet{
-		VertexShader:   shader.RunTemplate("point_light.vert.glsl", struct{}{}),
-		FragmentShader: shader.RunTemplate("point_light.frag.glsl", struct{}{}),
+		VertexShader:   sets.engine.Source("point_light.vert.glsl", struct{}{}),
+		FragmentShader: sets.engine.Source("point_light.frag.glsl", struct{}{}),
 	}
 }
 
-func newSpotLightShaderSet() graphics.ShaderSet {
+func (sets shaderSets) newSpotLightShaderSet() graphics.ShaderSet {
 	return graphics.ShaderSet{
-		VertexShader:   shader.RunTemplate("spot_light.vert.glsl", struct{}{}),
-		FragmentShader: shader.RunTemplate("spot_light.frag.glsl", struct{}{}),
+		VertexShader:   sets.engine.Source("spot_light.vert.glsl", struct{}{}),
+		FragmentShader: sets.engine.Source("spot_light.frag.glsl", struct{}{}),
 	}
 }
 
-func newDirectionalLightShaderSet() graphics.ShaderSet {
+func (sets shaderSets) newDirectionalLightShaderSet() graphics.ShaderSet {
+	// The collection has no setting for shadow mapping, so it is always
+	// enabled.
+	return sets.directionalLightShaderSet(true)
+}
+
+func (sets shaderSets) directionalLightShaderSet(useShadowMapping bool) graphics.ShaderSet {
 	var settings struct {
 		UseShadowMapping bool
 	}
-	settings.UseShadowMapping = true // TODO
+	settings.UseShadowMapping = useShadowMapping
 	return graphics.ShaderSet{
-		VertexShader:   shader.RunTemplate("directional_light.vert.glsl", settings),
-		FragmentShader: shader.RunTemplate("directional_light.frag.glsl", settings),
+		VertexShader:   sets.engine.Source("directional_light.vert.glsl", settings),
+		FragmentShader: sets.engine.Source("directional_light.frag.glsl", settings),
 	}
 }
 
-func newSkyboxShaderSet() graphics.ShaderSet {
+func (sets shaderSets) newSkyboxShaderSet() graphics.ShaderSet {
 	return graphics.ShaderSet{
-		VertexShader:   shader.RunTemplate("skybox.vert.glsl", struct{}{}),
-		FragmentShader: shader.RunTemplate("skybox.frag.glsl", struct{}{}),
+		VertexShader:   sets.engine.Source("skybox.vert.glsl", struct{}{}),
+		FragmentShader: sets.engine.Source("skybox.frag.glsl", struct{}{}),
 	}
 }
 
-func newSkycolorShaderSet() graphics.ShaderSet {
+func (sets shaderSets) newSkycolorShaderSet() graphics.ShaderSet {
 	return graphics.ShaderSet{
-		VertexShader:   shader.RunTemplate("skycolor.vert.glsl", struct{}{}),
-		FragmentShader: shader.RunTemplate("skycolor.frag.glsl", struct{}{}),
+		VertexShader:   sets.engine.Source("skycolor.vert.glsl", struct{}{}),
+		FragmentShader: sets.engine.Source("skycolor.frag.glsl", struct{}{}),
 	}
 }
 
-func newDebugShaderSet() graphics.ShaderSet {
+func (sets shaderSets) newDebugShaderSet() graphics.ShaderSet {
 	return graphics.ShaderSet{
-		VertexShader:   shader.RunTemplate("debug.vert.glsl", struct{}{}),
-		FragmentShader: shader.RunTemplate("debug.frag.glsl", struct{}{}),
+		VertexShader:   sets.engine.Source("debug.vert.glsl", struct{}{}),
+		FragmentShader: sets.engine.Source("debug.frag.glsl", struct{}{}),
 	}
 }
 
-func newExposureShaderSet() graphics.ShaderSet {
+func (sets shaderSets) newExposureShaderSet() graphics.ShaderSet {
 	return graphics.ShaderSet{
-		VertexShader:   shader.RunTemplate("exposure.vert.glsl", struct{}{}),
-		FragmentShader: shader.RunTemplate("exposure.frag.glsl", struct{}{}),
+		VertexShader:   sets.engine.Source("exposure.vert.glsl", struct{}{}),
+		FragmentShader: sets.engine.Source("exposure.frag.glsl", struct{}{}),
 	}
 }
 
-func newPostprocessingShaderSet(cfg graphics.PostprocessingShaderConfig) graphics.ShaderSet {
+func (sets shaderSets) newPostprocessingShaderSet(cfg graphics.PostprocessingShaderConfig) graphics.ShaderSet {
 	var settings struct {
 		UseReinhard    bool
 		UseExponential bool
@@ -137,7 +160,7 @@ func newPostprocessingShaderSet(cfg graphics.PostprocessingShaderConfig) graphic
 		panic(fmt.Errorf("unknown tone mapping mode: %s", cfg.ToneMapping))
 	}
 	return graphics.ShaderSet{
-		VertexShader:   shader.RunTemplate("postprocess.vert.glsl", settings),
-		FragmentShader: shader.RunTemplate("postprocess.frag.glsl", settings),
+		VertexShader:   sets.engine.Source("postprocess.vert.glsl", settings),
+		FragmentShader: sets.engine.Source("postprocess.frag.glsl", settings),
 	}
 }
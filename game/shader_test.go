@@ -0,0 +1,32 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/mokiat/lacking-gl/shader"
+	"github.com/mokiat/lacking-gl/shader/shadercheck"
+)
+
+func TestShaderVariants(t *testing.T) {
+	variants := shadercheck.GameVariants(NewShaderCollection())
+
+	// The collection always enables shadow mapping for directional
+	// lights, so the variant without it is added explicitly.
+	sets := shaderSets{
+		engine: shader.Default,
+	}
+	set := sets.directionalLightShaderSet(false)
+	variants = append(variants, shadercheck.Variant{
+		Name:           "game/DirectionalLight{UseShadowMapping:false}",
+		VertexShader:   set.VertexShader,
+		FragmentShader: set.FragmentShader,
+	})
+
+	failures, err := shadercheck.Check(variants)
+	if err != nil {
+		t.Skipf("shader variants cannot be checked: %v", err)
+	}
+	for _, failure := range failures {
+		t.Errorf("shader variant failed to build: %s", failure)
+	}
+}
@@ -1,35 +1,81 @@
 package render
 
 import (
+	"fmt"
+
+	"github.com/mokiat/lacking-gl/render/ext"
 	"github.com/mokiat/lacking-gl/render/internal"
 	"github.com/mokiat/lacking/render"
 )
 
 func NewAPI() render.API {
+	capabilities := internal.DetectCapabilities()
+	return &API{
+		renderer:           internal.NewRenderer(capabilities),
+		defaultFramebuffer: internal.DefaultFramebuffer,
+		capabilities:       capabilities,
+	}
+}
+
+// NewOffscreenAPI creates a new API that renders to an offscreen
+// framebuffer with the specified size instead of to the default one.
+//
+// This is useful when there is no window surface available, as is the
+// case with headless contexts.
+func NewOffscreenAPI(width, height int) render.API {
+	capabilities := internal.DetectCapabilities()
 	return &API{
-		renderer: internal.NewRenderer(),
+		renderer:           internal.NewRenderer(capabilities),
+		defaultFramebuffer: internal.NewOffscreenFramebuffer(width, height),
+		capabilities:       capabilities,
 	}
 }
 
+var (
+	_ ext.API          = (*API)(nil)
+	_ ext.CommandQueue = (*internal.CommandQueue)(nil)
+	_ ext.Texture      = (*internal.Texture)(nil)
+)
+
 type API struct {
-	renderer *internal.Renderer
+	renderer           *internal.Renderer
+	defaultFramebuffer *internal.Framebuffer
+	capabilities       ext.Capabilities
+	diagnosticsHandler ext.DiagnosticsHandler
+	programCache       *internal.ProgramCache
+	asyncCompile       bool
+	programs           []*internal.Program
+	prunedPrograms     int
 }
 
 func (a *API) Capabilities() render.Capabilities {
 	return render.Capabilities{
-		Quality: render.QualityHigh,
+		Quality: a.capabilities.Quality,
 	}
 }
 
+// ExtendedCapabilities returns detailed information about the OpenGL
+// implementation. The information is queried once, when the API is
+// created.
+func (a *API) ExtendedCapabilities() ext.Capabilities {
+	return a.capabilities
+}
+
 func (a *API) DefaultFramebuffer() render.Framebuffer {
-	return internal.DefaultFramebuffer
+	return a.defaultFramebuffer
 }
 
 func (a *API) CreateFramebuffer(info render.FramebufferInfo) render.Framebuffer {
 	return internal.NewFramebuffer(info)
 }
 
+// CreateColorTexture2D creates a new 2D texture. Block-compressed formats
+// (e.g. ext.DataFormatBC7) are decompressed to RGBA8 if the driver does not
+// support them.
 func (a *API) CreateColorTexture2D(info render.ColorTexture2DInfo) render.Texture {
+	if ext.BlockSize(info.Format) > 0 {
+		return internal.NewCompressedColorTexture2D(info, a.capabilities)
+	}
 	return internal.NewColorTexture2D(info)
 }
 
@@ -41,6 +87,43 @@ func (a *API) CreateDepthTexture2D(info render.DepthTexture2DInfo) render.Textur
 	return internal.NewDepthTexture2D(info)
 }
 
+// CreateFramebufferWithAttachments creates a new framebuffer that renders
+// into the specified layers and mipmap levels of textures.
+func (a *API) CreateFramebufferWithAttachments(info ext.FramebufferInfo) render.Framebuffer {
+	return internal.NewFramebufferWithAttachments(info)
+}
+
+// CreateColorTexture2DMultisample creates a new multisample color texture.
+func (a *API) CreateColorTexture2DMultisample(info ext.ColorTexture2DMultisampleInfo) render.Texture {
+	return internal.NewColorTexture2DMultisample(info)
+}
+
+// CreateDepthTexture2DMultisample creates a new multisample depth texture.
+func (a *API) CreateDepthTexture2DMultisample(info ext.DepthTexture2DMultisampleInfo) render.Texture {
+	return internal.NewDepthTexture2DMultisample(info)
+}
+
+// CreateColorTexture2DArray creates a new 2D array texture.
+func (a *API) CreateColorTexture2DArray(info ext.ColorTexture2DArrayInfo) render.Texture {
+	return internal.NewColorTexture2DArray(info)
+}
+
+// CreateColorTexture3D creates a new 3D texture.
+func (a *API) CreateColorTexture3D(info ext.ColorTexture3DInfo) render.Texture {
+	return internal.NewColorTexture3D(info)
+}
+
+// CreateDepthTexture2DArray creates a new 2D array depth texture.
+func (a *API) CreateDepthTexture2DArray(info ext.DepthTexture2DArrayInfo) render.Texture {
+	return internal.NewDepthTexture2DArray(info)
+}
+
+// CreateDepthTexture2DWithFormat creates a new depth texture with the
+// specified precision.
+func (a *API) CreateDepthTexture2DWithFormat(info render.DepthTexture2DInfo, format render.DataFormat) render.Texture {
+	return internal.NewDepthTexture2DWithFormat(info, format)
+}
+
 func (a *API) CreateStencilTexture2D(info render.StencilTexture2DInfo) render.Texture {
 	return internal.NewStencilTexture2D(info)
 }
@@ -50,15 +133,24 @@ func (a *API) CreateDepthStencilTexture2D(info render.DepthStencilTexture2DInfo)
 }
 
 func (a *API) CreateVertexShader(info render.ShaderInfo) render.Shader {
-	return internal.NewVertexShader(info)
+	shader := a.newShader(ext.ShaderStageVertex, info)
+	a.reportShaderDiagnostics(shader)
+	return shader
 }
 
 func (a *API) CreateFragmentShader(info render.ShaderInfo) render.Shader {
-	return internal.NewFragmentShader(info)
+	shader := a.newShader(ext.ShaderStageFragment, info)
+	a.reportShaderDiagnostics(shader)
+	return shader
 }
 
 func (a *API) CreateProgram(info render.ProgramInfo) render.Program {
-	return internal.NewProgram(info)
+	pending := pendingShaders(info.VertexShader, info.FragmentShader)
+	program := internal.NewProgram(info, a.programCache, a.asyncCompile)
+	a.renderer.TrackLinkingProgram(program)
+	a.reportProgramDiagnostics(program, pending)
+	a.trackProgram(program)
+	return program
 }
 
 func (a *API) CreateVertexBuffer(info render.BufferInfo) render.Buffer {
@@ -77,12 +169,100 @@ func (a *API) CreateUniformBuffer(info render.BufferInfo) render.Buffer {
 	return internal.NewUniformBuffer(info)
 }
 
+// CreateComputeShader creates a new compute shader.
+func (a *API) CreateComputeShader(info render.ShaderInfo) render.Shader {
+	shader := a.newShader(ext.ShaderStageCompute, info)
+	a.reportShaderDiagnostics(shader)
+	return shader
+}
+
+// CreateComputeProgram creates a new program that consists of a single
+// compute shader.
+func (a *API) CreateComputeProgram(info ext.ComputeProgramInfo) render.Program {
+	pending := pendingShaders(info.ComputeShader)
+	program := internal.NewComputeProgram(info, a.programCache, a.asyncCompile)
+	a.renderer.TrackLinkingProgram(program)
+	a.reportProgramDiagnostics(program, pending)
+	a.trackProgram(program)
+	return program
+}
+
+// CreateGeometryShader creates a new geometry shader.
+func (a *API) CreateGeometryShader(info render.ShaderInfo) render.Shader {
+	shader := a.newShader(ext.ShaderStageGeometry, info)
+	a.reportShaderDiagnostics(shader)
+	return shader
+}
+
+// CreateTessControlShader creates a new tessellation control shader.
+func (a *API) CreateTessControlShader(info render.ShaderInfo) render.Shader {
+	shader := a.newShader(ext.ShaderStageTessControl, info)
+	a.reportShaderDiagnostics(shader)
+	return shader
+}
+
+// CreateTessEvaluationShader creates a new tessellation evaluation
+// shader.
+func (a *API) CreateTessEvaluationShader(info render.ShaderInfo) render.Shader {
+	shader := a.newShader(ext.ShaderStageTessEvaluation, info)
+	a.reportShaderDiagnostics(shader)
+	return shader
+}
+
+// CreateGraphicsProgram creates a new program that can have geometry and
+// tessellation shaders in addition to vertex and fragment ones.
+func (a *API) CreateGraphicsProgram(info ext.GraphicsProgramInfo) render.Program {
+	pending := pendingShaders(
+		info.VertexShader,
+		info.TessControlShader,
+		info.TessEvaluationShader,
+		info.GeometryShader,
+		info.FragmentShader,
+	)
+	program := internal.NewGraphicsProgram(info, a.programCache, a.asyncCompile)
+	a.renderer.TrackLinkingProgram(program)
+	a.reportProgramDiagnostics(program, pending)
+	a.trackProgram(program)
+	return program
+}
+
+// CreateStorageBuffer creates a new shader storage buffer.
+func (a *API) CreateStorageBuffer(info render.BufferInfo) render.Buffer {
+	return internal.NewStorageBuffer(info)
+}
+
+// CreateIndirectBuffer creates a new buffer for indirect draw parameters.
+func (a *API) CreateIndirectBuffer(info render.BufferInfo) render.Buffer {
+	return internal.NewIndirectBuffer(info)
+}
+
+// CreateQuery creates a new query object.
+func (a *API) CreateQuery(info ext.QueryInfo) ext.Query {
+	query := internal.NewQuery(info)
+	a.renderer.TrackQuery(query)
+	return query
+}
+
+// CreateSampler creates a new sampler object.
+func (a *API) CreateSampler(info ext.SamplerInfo) ext.Sampler {
+	return internal.NewSampler(info)
+}
+
 func (a *API) CreateVertexArray(info render.VertexArrayInfo) render.VertexArray {
 	return internal.NewVertexArray(info)
 }
 
 func (a *API) CreatePipeline(info render.PipelineInfo) render.Pipeline {
-	return internal.NewPipeline(info)
+	if info.Topology == ext.TopologyPatches {
+		panic(fmt.Errorf("pipelines with patch topology need to be created through CreateExtendedPipeline"))
+	}
+	return internal.NewPipeline(info, a.capabilities)
+}
+
+// CreateExtendedPipeline creates a new pipeline with OpenGL-specific
+// settings.
+func (a *API) CreateExtendedPipeline(info ext.PipelineInfo) render.Pipeline {
+	return internal.NewExtendedPipeline(info, a.capabilities)
 }
 
 func (a *API) CreateCommandQueue() render.CommandQueue {
@@ -153,6 +333,108 @@ func (a *API) CopyContentToTexture(info render.CopyContentToTextureInfo) {
 	a.renderer.CopyContentToTexture(info)
 }
 
+// BindComputeProgram makes the specified compute program current.
+func (a *API) BindComputeProgram(program render.Program) {
+	a.renderer.BindComputeProgram(program)
+}
+
+// StorageBufferUnit binds the whole buffer to the specified storage
+// buffer binding point.
+func (a *API) StorageBufferUnit(index int, buffer render.Buffer) {
+	a.renderer.StorageBufferUnit(index, buffer)
+}
+
+// StorageBufferUnitRange binds a region of the buffer to the specified
+// storage buffer binding point.
+func (a *API) StorageBufferUnitRange(index int, buffer render.Buffer, offset, size int) {
+	a.renderer.StorageBufferUnitRange(index, buffer, offset, size)
+}
+
+// ImageUnit binds a texture to the specified image unit.
+func (a *API) ImageUnit(index int, texture render.Texture, info ext.ImageUnitInfo) {
+	a.renderer.ImageUnit(index, texture, info)
+}
+
+// Dispatch runs the current compute program.
+func (a *API) Dispatch(groupsX, groupsY, groupsZ int) {
+	a.renderer.Dispatch(groupsX, groupsY, groupsZ)
+}
+
+// DispatchIndirect runs the current compute program with the number of
+// work groups read from the specified buffer.
+func (a *API) DispatchIndirect(buffer render.Buffer, offset int) {
+	a.renderer.DispatchIndirect(buffer, offset)
+}
+
+// DrawIndirect draws primitives with parameters read from the specified
+// buffer.
+func (a *API) DrawIndirect(buffer render.Buffer, offset int) {
+	a.renderer.DrawIndirect(buffer, offset)
+}
+
+// DrawIndexedIndirect draws indexed primitives with parameters read from
+// the specified buffer.
+func (a *API) DrawIndexedIndirect(buffer render.Buffer, offset int) {
+	a.renderer.DrawIndexedIndirect(buffer, offset)
+}
+
+// MultiDrawIndexedIndirect makes a sequence of indexed draws with
+// parameters read from an indirect buffer.
+func (a *API) MultiDrawIndexedIndirect(info ext.MultiDrawIndexedIndirectInfo) {
+	a.renderer.MultiDrawIndexedIndirect(info)
+}
+
+// MemoryBarrier makes shader writes visible to the specified kinds of
+// subsequent accesses.
+func (a *API) MemoryBarrier(barrier ext.Barrier) {
+	a.renderer.MemoryBarrier(barrier)
+}
+
+// WriteTimestamp records the GPU time at which all preceding commands
+// have completed.
+func (a *API) WriteTimestamp(query ext.Query) {
+	a.renderer.WriteTimestamp(query)
+}
+
+// BeginQuery starts measuring subsequent commands with the specified
+// query.
+func (a *API) BeginQuery(query ext.Query) {
+	a.renderer.BeginQuery(query)
+}
+
+// EndQuery stops the measurement of the specified query.
+func (a *API) EndQuery(query ext.Query) {
+	a.renderer.EndQuery(query)
+}
+
+// UpdateTextureData replaces a region of one level of the specified
+// texture.
+func (a *API) UpdateTextureData(texture render.Texture, info ext.TextureUpdateInfo) {
+	a.renderer.UpdateTextureData(texture, info)
+}
+
+// GenerateTextureMipmaps regenerates all levels of the specified texture
+// from its first level.
+func (a *API) GenerateTextureMipmaps(texture render.Texture) {
+	a.renderer.GenerateTextureMipmaps(texture)
+}
+
+// CopyTextureToBuffer copies a region of one level of the specified
+// texture into a pixel transfer buffer.
+func (a *API) CopyTextureToBuffer(texture render.Texture, info ext.TextureCopyToBufferInfo) {
+	a.renderer.CopyTextureToBuffer(texture, info)
+}
+
+// SamplerUnit binds a sampler to the specified texture unit.
+func (a *API) SamplerUnit(index int, sampler ext.Sampler) {
+	a.renderer.SamplerUnit(index, sampler)
+}
+
+// BlitFramebuffer copies a region of one framebuffer into another one.
+func (a *API) BlitFramebuffer(info ext.BlitFramebufferInfo) {
+	a.renderer.BlitFramebuffer(info)
+}
+
 func (a *API) SubmitQueue(queue render.CommandQueue) {
 	a.renderer.SubmitQueue(queue.(*internal.CommandQueue))
 }
@@ -0,0 +1,179 @@
+// Package capture provides recording of render API usage to a file and
+// replaying of such recordings, so that rendering issues can be
+// reproduced without the original application.
+//
+// A capture file starts with a magic string, followed by a gob-encoded
+// Header and a sequence of gob-encoded records. Resource creation calls
+// are stored together with the OpenGL names of the created objects and
+// command queues are stored in their native binary form. During replay,
+// newly created objects are mapped to the original names and all names
+// that are referenced by queued commands are translated accordingly.
+//
+// Pipelines are not stored explicitly, since binding a pipeline expands
+// to a command that holds all of its state.
+package capture
+
+import (
+	"github.com/mokiat/lacking/render"
+)
+
+// FormatVersion is the version of the capture file format that is
+// produced by Recorder and supported by Replayer.
+const FormatVersion = 2
+
+const magic = "LGLCAP"
+
+// Header holds information about a capture file.
+type Header struct {
+
+	// Version is the format version of the capture file.
+	Version uint32
+
+	// Width is the width of the default framebuffer.
+	Width int
+
+	// Height is the height of the default framebuffer.
+	Height int
+}
+
+type recordKind uint8
+
+const (
+	recordKindColorTexture2D recordKind = iota
+	recordKindColorTextureCube
+	recordKindDepthTexture2D
+	recordKindStencilTexture2D
+	recordKindDepthStencilTexture2D
+	recordKindFramebuffer
+	recordKindVertexShader
+	recordKindFragmentShader
+	recordKindProgram
+	recordKindUniformLocation
+	recordKindVertexBuffer
+	recordKindIndexBuffer
+	recordKindPixelTransferBuffer
+	recordKindUniformBuffer
+	recordKindBufferUpdate
+	recordKindVertexArray
+	recordKindBeginRenderPass
+	recordKindEndRenderPass
+	recordKindInvalidate
+	recordKindCopyContentToTexture
+	recordKindQueue
+	recordKindFrame
+)
+
+// record is a single entry in a capture file. Only the payload that
+// corresponds to the kind of the record is set.
+type record struct {
+	Kind recordKind
+
+	ColorTexture2D        *colorTexture2DRecord
+	ColorTextureCube      *colorTextureCubeRecord
+	DepthTexture2D        *depthTexture2DRecord
+	StencilTexture2D      *stencilTexture2DRecord
+	DepthStencilTexture2D *depthStencilTexture2DRecord
+	Framebuffer           *framebufferRecord
+	Shader                *shaderRecord
+	Program               *programRecord
+	UniformLocation       *uniformLocationRecord
+	Buffer                *bufferRecord
+	BufferUpdate          *bufferUpdateRecord
+	VertexArray           *vertexArrayRecord
+	RenderPass            *renderPassRecord
+	CopyContentToTexture  *copyContentToTextureRecord
+	Queue                 *queueRecord
+}
+
+type colorTexture2DRecord struct {
+	ID   uint32
+	Info render.ColorTexture2DInfo
+}
+
+type colorTextureCubeRecord struct {
+	ID   uint32
+	Info render.ColorTextureCubeInfo
+}
+
+type depthTexture2DRecord struct {
+	ID   uint32
+	Info render.DepthTexture2DInfo
+}
+
+type stencilTexture2DRecord struct {
+	ID   uint32
+	Info render.StencilTexture2DInfo
+}
+
+type depthStencilTexture2DRecord struct {
+	ID   uint32
+	Info render.DepthStencilTexture2DInfo
+}
+
+type framebufferRecord struct {
+	ID                     uint32
+	ColorAttachments       [4]uint32
+	DepthAttachment        uint32
+	StencilAttachment      uint32
+	DepthStencilAttachment uint32
+}
+
+type shaderRecord struct {
+	ID   uint32
+	Info render.ShaderInfo
+}
+
+type programRecord struct {
+	ID             uint32
+	VertexShader   uint32
+	FragmentShader uint32
+}
+
+type uniformLocationRecord struct {
+	ProgramID uint32
+	Name      string
+	Location  int32
+}
+
+type bufferRecord struct {
+	ID   uint32
+	Info render.BufferInfo
+}
+
+type bufferUpdateRecord struct {
+	ID   uint32
+	Info render.BufferUpdateInfo
+}
+
+type vertexArrayRecord struct {
+	ID          uint32
+	Bindings    []vertexArrayBindingRecord
+	Attributes  []render.VertexArrayAttributeInfo
+	IndexBuffer uint32
+	IndexFormat render.IndexFormat
+}
+
+type vertexArrayBindingRecord struct {
+	VertexBuffer uint32
+	Stride       int
+}
+
+type renderPassRecord struct {
+	DefaultFramebuffer bool
+	FramebufferID      uint32
+
+	// Info holds the render pass settings. Its Framebuffer field is
+	// always nil.
+	Info render.RenderPassInfo
+}
+
+type copyContentToTextureRecord struct {
+	TextureID uint32
+
+	// Info holds the copy settings. Its Texture field is always nil.
+	Info render.CopyContentToTextureInfo
+}
+
+type queueRecord struct {
+	Data []byte
+}
@@ -0,0 +1,316 @@
+package capture
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/mokiat/lacking-gl/render/internal"
+	"github.com/mokiat/lacking/render"
+)
+
+func TestRoundTrip(t *testing.T) {
+	var out bytes.Buffer
+	recordAPI := newFakeAPI(1, 7)
+	recorder, err := NewRecorder(recordAPI, &out, 640, 480)
+	if err != nil {
+		t.Fatalf("failed to create recorder: %v", err)
+	}
+
+	vertexShader := recorder.CreateVertexShader(render.ShaderInfo{SourceCode: "vertex"})
+	fragmentShader := recorder.CreateFragmentShader(render.ShaderInfo{SourceCode: "fragment"})
+	program := recorder.CreateProgram(render.ProgramInfo{
+		VertexShader:   vertexShader,
+		FragmentShader: fragmentShader,
+	})
+	location := program.UniformLocation("color")
+	vertexBuffer := recorder.CreateVertexBuffer(render.BufferInfo{Data: []byte{1, 2, 3, 4}})
+	indexBuffer := recorder.CreateIndexBuffer(render.BufferInfo{Data: []byte{0, 0, 1, 0}})
+	bindings := []render.VertexArrayBindingInfo{
+		{VertexBuffer: vertexBuffer, Stride: 4},
+	}
+	vertexArray := recorder.CreateVertexArray(render.VertexArrayInfo{
+		Bindings:    bindings,
+		IndexBuffer: indexBuffer,
+		IndexFormat: render.IndexFormatUnsignedShort,
+	})
+	if bindings[0].VertexBuffer != vertexBuffer {
+		t.Errorf("expected the bindings of the caller to remain unchanged")
+	}
+	vertexBuffer.Update(render.BufferUpdateInfo{Data: []byte{5, 6}, Offset: 2})
+
+	queue := recorder.CreateCommandQueue()
+	// Pipelines can only be created from objects of this module, so the
+	// command that binds one is pushed directly.
+	intQueue := queue.(*recordedQueue).CommandQueue.(*internal.CommandQueue)
+	internal.PushCommand(intQueue, internal.CommandHeader{
+		Kind: internal.CommandKindBindPipeline,
+	})
+	internal.PushCommand(intQueue, internal.CommandBindPipeline{
+		ProgramID: objectID(program),
+		VertexArray: internal.CommandBindVertexArray{
+			VertexArrayID: objectID(vertexArray),
+		},
+	})
+	queue.Uniform1f(location, 0.5)
+	queue.DrawIndexed(0, 2, 1)
+	recorder.SubmitQueue(queue)
+	recorder.Draw(1, 3, 1)
+	recorder.EndFrame()
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("failed to close recorder: %v", err)
+	}
+
+	expectedRecordCalls := []string{
+		"CreateVertexShader 1 vertex",
+		"CreateFragmentShader 2 fragment",
+		"CreateProgram 3 1 2",
+		"UniformLocation 3 color 7",
+		"CreateVertexBuffer 4 [1 2 3 4]",
+		"CreateIndexBuffer 5 [0 0 1 0]",
+		"CreateVertexArray 6 4 5",
+		"Update 4 [5 6] 2",
+		"SubmitQueue",
+		"Draw 1 3 1",
+	}
+	if !reflect.DeepEqual(expectedRecordCalls, recordAPI.calls) {
+		t.Errorf("expected %v, got %v", expectedRecordCalls, recordAPI.calls)
+	}
+
+	replayer, err := NewReplayer(&out)
+	if err != nil {
+		t.Fatalf("failed to create replayer: %v", err)
+	}
+	expectedHeader := Header{
+		Version: FormatVersion,
+		Width:   640,
+		Height:  480,
+	}
+	if header := replayer.Header(); header != expectedHeader {
+		t.Errorf("expected %v, got %v", expectedHeader, header)
+	}
+
+	replayAPI := newFakeAPI(101, 11)
+	var frames []int
+	err = replayer.Replay(replayAPI, func(index int) {
+		frames = append(frames, index)
+	})
+	if err != nil {
+		t.Fatalf("failed to replay: %v", err)
+	}
+	if expectedFrames := []int{0}; !reflect.DeepEqual(expectedFrames, frames) {
+		t.Errorf("expected %v, got %v", expectedFrames, frames)
+	}
+
+	expectedReplayCalls := []string{
+		"CreateVertexShader 101 vertex",
+		"CreateFragmentShader 102 fragment",
+		"CreateProgram 103 101 102",
+		"UniformLocation 103 color 11",
+		"CreateVertexBuffer 104 [1 2 3 4]",
+		"CreateIndexBuffer 105 [0 0 1 0]",
+		"CreateVertexArray 106 104 105",
+		"Update 104 [5 6] 2",
+		"SubmitQueue",
+		"SubmitQueue",
+	}
+	if !reflect.DeepEqual(expectedReplayCalls, replayAPI.calls) {
+		t.Errorf("expected %v, got %v", expectedReplayCalls, replayAPI.calls)
+	}
+
+	// The uniform location of the immediate draw is translated through
+	// the program that was bound by the earlier queue.
+	expectedQueues := [][]any{
+		{
+			internal.CommandBindPipeline{
+				ProgramID: 103,
+				VertexArray: internal.CommandBindVertexArray{
+					VertexArrayID: 106,
+				},
+			},
+			internal.CommandUniform1f{
+				Location: 11,
+				Value:    0.5,
+			},
+			internal.CommandDrawIndexed{
+				IndexOffset:   0,
+				IndexCount:    2,
+				InstanceCount: 1,
+			},
+		},
+		{
+			internal.CommandDraw{
+				VertexOffset:  1,
+				VertexCount:   3,
+				InstanceCount: 1,
+			},
+		},
+	}
+	if !reflect.DeepEqual(expectedQueues, replayAPI.queues) {
+		t.Errorf("expected %v, got %v", expectedQueues, replayAPI.queues)
+	}
+}
+
+func TestReplayerRejectsInvalidInput(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input []byte
+	}{
+		{
+			name:  "empty",
+			input: nil,
+		},
+		{
+			name:  "wrong magic",
+			input: []byte("NOTCAP"),
+		},
+		{
+			name:  "missing header",
+			input: []byte(magic),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := NewReplayer(bytes.NewReader(tc.input)); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
+
+// newFakeAPI creates a render.API that assigns consecutive names, starting
+// from the specified one, to the objects that it creates and that returns
+// the specified location for all uniforms.
+func newFakeAPI(firstID uint32, location int32) *fakeAPI {
+	return &fakeAPI{
+		nextID:   firstID,
+		location: location,
+	}
+}
+
+// fakeAPI is a render.API that logs the calls that are made to it. Calls
+// that are not implemented panic.
+type fakeAPI struct {
+	render.API
+	nextID   uint32
+	location int32
+	calls    []string
+	queues   [][]any
+}
+
+func (a *fakeAPI) log(format string, args ...any) {
+	a.calls = append(a.calls, fmt.Sprintf(format, args...))
+}
+
+func (a *fakeAPI) newObject() *fakeObject {
+	object := &fakeObject{
+		api: a,
+		id:  a.nextID,
+	}
+	a.nextID++
+	return object
+}
+
+func (a *fakeAPI) CreateVertexShader(info render.ShaderInfo) render.Shader {
+	shader := a.newObject()
+	a.log("CreateVertexShader %d %s", shader.id, info.SourceCode)
+	return shader
+}
+
+func (a *fakeAPI) CreateFragmentShader(info render.ShaderInfo) render.Shader {
+	shader := a.newObject()
+	a.log("CreateFragmentShader %d %s", shader.id, info.SourceCode)
+	return shader
+}
+
+func (a *fakeAPI) CreateProgram(info render.ProgramInfo) render.Program {
+	program := a.newObject()
+	a.log("CreateProgram %d %d %d", program.id, objectID(info.VertexShader), objectID(info.FragmentShader))
+	return program
+}
+
+func (a *fakeAPI) CreateVertexBuffer(info render.BufferInfo) render.Buffer {
+	buffer := a.newObject()
+	a.log("CreateVertexBuffer %d %v", buffer.id, info.Data)
+	return buffer
+}
+
+func (a *fakeAPI) CreateIndexBuffer(info render.BufferInfo) render.Buffer {
+	buffer := a.newObject()
+	a.log("CreateIndexBuffer %d %v", buffer.id, info.Data)
+	return buffer
+}
+
+func (a *fakeAPI) CreateVertexArray(info render.VertexArrayInfo) render.VertexArray {
+	vertexArray := a.newObject()
+	// The type assertions check that the delegate receives its own
+	// buffers and not the wrappers of the recorder.
+	a.log("CreateVertexArray %d %d %d",
+		vertexArray.id,
+		info.Bindings[0].VertexBuffer.(*fakeObject).id,
+		info.IndexBuffer.(*fakeObject).id,
+	)
+	return vertexArray
+}
+
+func (a *fakeAPI) CreateCommandQueue() render.CommandQueue {
+	return internal.NewCommandQueue()
+}
+
+func (a *fakeAPI) Draw(vertexOffset, vertexCount, instanceCount int) {
+	a.log("Draw %d %d %d", vertexOffset, vertexCount, instanceCount)
+}
+
+func (a *fakeAPI) SubmitQueue(queue render.CommandQueue) {
+	a.log("SubmitQueue")
+	intQueue := queue.(*internal.CommandQueue)
+	var commands []any
+	for internal.MoreCommands(intQueue) {
+		header := internal.PopCommand[internal.CommandHeader](intQueue)
+		switch header.Kind {
+		case internal.CommandKindBindPipeline:
+			commands = append(commands, internal.PopCommand[internal.CommandBindPipeline](intQueue))
+		case internal.CommandKindUniform1f:
+			commands = append(commands, internal.PopCommand[internal.CommandUniform1f](intQueue))
+		case internal.CommandKindDraw:
+			commands = append(commands, internal.PopCommand[internal.CommandDraw](intQueue))
+		case internal.CommandKindDrawIndexed:
+			commands = append(commands, internal.PopCommand[internal.CommandDrawIndexed](intQueue))
+		default:
+			panic(fmt.Errorf("unexpected command kind: %d", header.Kind))
+		}
+	}
+	a.queues = append(a.queues, commands)
+	intQueue.Reset()
+}
+
+// fakeObject is a resource of fakeAPI that can act as any kind of
+// resource.
+type fakeObject struct {
+	render.ShaderObject
+	render.ProgramObject
+	render.BufferObject
+	render.VertexArrayObject
+	api *fakeAPI
+	id  uint32
+}
+
+func (o *fakeObject) ID() uint32 {
+	return o.id
+}
+
+func (o *fakeObject) UniformLocation(name string) render.UniformLocation {
+	o.api.log("UniformLocation %d %s %d", o.id, name, o.api.location)
+	return o.api.location
+}
+
+func (o *fakeObject) Update(info render.BufferUpdateInfo) {
+	o.api.log("Update %d %v %d", o.id, info.Data, info.Offset)
+}
+
+func (o *fakeObject) Fetch(info render.BufferFetchInfo) {
+	panic("not implemented")
+}
+
+func (o *fakeObject) Release() {}
@@ -0,0 +1,504 @@
+package capture
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+
+	"github.com/mokiat/lacking-gl/render/internal"
+	"github.com/mokiat/lacking/render"
+)
+
+// NewRecorder creates a new Recorder that records all calls made to the
+// delegate API into the specified writer.
+//
+// The delegate needs to be an API created through this module's render
+// package, since the OpenGL names of created objects are recorded. The
+// width and height should describe the size of the default framebuffer.
+func NewRecorder(delegate render.API, out io.Writer, width, height int) (*Recorder, error) {
+	if _, err := io.WriteString(out, magic); err != nil {
+		return nil, fmt.Errorf("failed to write magic: %w", err)
+	}
+	encoder := gob.NewEncoder(out)
+	header := Header{
+		Version: FormatVersion,
+		Width:   width,
+		Height:  height,
+	}
+	if err := encoder.Encode(header); err != nil {
+		return nil, fmt.Errorf("failed to write header: %w", err)
+	}
+	return &Recorder{
+		delegate:       delegate,
+		encoder:        encoder,
+		immediateQueue: internal.NewCommandQueue(),
+	}, nil
+}
+
+var _ render.API = (*Recorder)(nil)
+
+// Recorder is an implementation of render.API that records all resource
+// creation calls and rendering commands before forwarding them to a
+// delegate API.
+//
+// Immediate rendering calls are converted to queued commands, which are
+// stored as a separate queue whenever a call that cannot be queued is
+// made.
+type Recorder struct {
+	delegate       render.API
+	encoder        *gob.Encoder
+	immediateQueue *internal.CommandQueue
+	err            error
+}
+
+// Err returns the first error that was encountered while writing the
+// capture. Once an error has occurred, no further records are written.
+func (r *Recorder) Err() error {
+	return r.err
+}
+
+// EndFrame marks the end of a frame. The replayer produces an image
+// of the default framebuffer for each frame.
+func (r *Recorder) EndFrame() {
+	r.write(record{
+		Kind: recordKindFrame,
+	})
+}
+
+// Close flushes any pending immediate commands and returns the first
+// error that was encountered while writing the capture. It does not
+// close the underlying writer.
+func (r *Recorder) Close() error {
+	r.flushImmediateQueue()
+	r.immediateQueue.Release()
+	return r.err
+}
+
+func (r *Recorder) Capabilities() render.Capabilities {
+	return r.delegate.Capabilities()
+}
+
+func (r *Recorder) DefaultFramebuffer() render.Framebuffer {
+	return r.delegate.DefaultFramebuffer()
+}
+
+func (r *Recorder) DetermineContentFormat(framebuffer render.Framebuffer) render.DataFormat {
+	return r.delegate.DetermineContentFormat(framebuffer)
+}
+
+func (r *Recorder) CreateFramebuffer(info render.FramebufferInfo) render.Framebuffer {
+	framebuffer := r.delegate.CreateFramebuffer(info)
+	rec := &framebufferRecord{
+		ID:                     objectID(framebuffer),
+		DepthAttachment:        objectID(info.DepthAttachment),
+		StencilAttachment:      objectID(info.StencilAttachment),
+		DepthStencilAttachment: objectID(info.DepthStencilAttachment),
+	}
+	for i, attachment := range info.ColorAttachments {
+		rec.ColorAttachments[i] = objectID(attachment)
+	}
+	r.write(record{
+		Kind:        recordKindFramebuffer,
+		Framebuffer: rec,
+	})
+	return framebuffer
+}
+
+func (r *Recorder) CreateColorTexture2D(info render.ColorTexture2DInfo) render.Texture {
+	texture := r.delegate.CreateColorTexture2D(info)
+	r.write(record{
+		Kind: recordKindColorTexture2D,
+		ColorTexture2D: &colorTexture2DRecord{
+			ID:   objectID(texture),
+			Info: info,
+		},
+	})
+	return texture
+}
+
+func (r *Recorder) CreateColorTextureCube(info render.ColorTextureCubeInfo) render.Texture {
+	texture := r.delegate.CreateColorTextureCube(info)
+	r.write(record{
+		Kind: recordKindColorTextureCube,
+		ColorTextureCube: &colorTextureCubeRecord{
+			ID:   objectID(texture),
+			Info: info,
+		},
+	})
+	return texture
+}
+
+func (r *Recorder) CreateDepthTexture2D(info render.DepthTexture2DInfo) render.Texture {
+	texture := r.delegate.CreateDepthTexture2D(info)
+	r.write(record{
+		Kind: recordKindDepthTexture2D,
+		DepthTexture2D: &depthTexture2DRecord{
+			ID:   objectID(texture),
+			Info: info,
+		},
+	})
+	return texture
+}
+
+func (r *Recorder) CreateStencilTexture2D(info render.StencilTexture2DInfo) render.Texture {
+	texture := r.delegate.CreateStencilTexture2D(info)
+	r.write(record{
+		Kind: recordKindStencilTexture2D,
+		StencilTexture2D: &stencilTexture2DRecord{
+			ID:   objectID(texture),
+			Info: info,
+		},
+	})
+	return texture
+}
+
+func (r *Recorder) CreateDepthStencilTexture2D(info render.DepthStencilTexture2DInfo) render.Texture {
+	texture := r.delegate.CreateDepthStencilTexture2D(info)
+	r.write(record{
+		Kind: recordKindDepthStencilTexture2D,
+		DepthStencilTexture2D: &depthStencilTexture2DRecord{
+			ID:   objectID(texture),
+			Info: info,
+		},
+	})
+	return texture
+}
+
+func (r *Recorder) CreateVertexShader(info render.ShaderInfo) render.Shader {
+	shader := r.delegate.CreateVertexShader(info)
+	r.write(record{
+		Kind: recordKindVertexShader,
+		Shader: &shaderRecord{
+			ID:   objectID(shader),
+			Info: info,
+		},
+	})
+	return shader
+}
+
+func (r *Recorder) CreateFragmentShader(info render.ShaderInfo) render.Shader {
+	shader := r.delegate.CreateFragmentShader(info)
+	r.write(record{
+		Kind: recordKindFragmentShader,
+		Shader: &shaderRecord{
+			ID:   objectID(shader),
+			Info: info,
+		},
+	})
+	return shader
+}
+
+func (r *Recorder) CreateProgram(info render.ProgramInfo) render.Program {
+	program := r.delegate.CreateProgram(info)
+	r.write(record{
+		Kind: recordKindProgram,
+		Program: &programRecord{
+			ID:             objectID(program),
+			VertexShader:   objectID(info.VertexShader),
+			FragmentShader: objectID(info.FragmentShader),
+		},
+	})
+	return &recordedProgram{
+		Program:  program,
+		recorder: r,
+	}
+}
+
+func (r *Recorder) CreateVertexBuffer(info render.BufferInfo) render.Buffer {
+	return r.recordBuffer(recordKindVertexBuffer, r.delegate.CreateVertexBuffer(info), info)
+}
+
+func (r *Recorder) CreateIndexBuffer(info render.BufferInfo) render.Buffer {
+	return r.recordBuffer(recordKindIndexBuffer, r.delegate.CreateIndexBuffer(info), info)
+}
+
+func (r *Recorder) CreatePixelTransferBuffer(info render.BufferInfo) render.Buffer {
+	return r.recordBuffer(recordKindPixelTransferBuffer, r.delegate.CreatePixelTransferBuffer(info), info)
+}
+
+func (r *Recorder) CreateUniformBuffer(info render.BufferInfo) render.Buffer {
+	return r.recordBuffer(recordKindUniformBuffer, r.delegate.CreateUniformBuffer(info), info)
+}
+
+func (r *Recorder) CreateVertexArray(info render.VertexArrayInfo) render.VertexArray {
+	rec := &vertexArrayRecord{
+		Bindings:    make([]vertexArrayBindingRecord, len(info.Bindings)),
+		Attributes:  info.Attributes,
+		IndexBuffer: objectID(info.IndexBuffer),
+		IndexFormat: info.IndexFormat,
+	}
+	delegateInfo := info
+	delegateInfo.Bindings = make([]render.VertexArrayBindingInfo, len(info.Bindings))
+	for i, binding := range info.Bindings {
+		rec.Bindings[i] = vertexArrayBindingRecord{
+			VertexBuffer: objectID(binding.VertexBuffer),
+			Stride:       binding.Stride,
+		}
+		delegateInfo.Bindings[i] = render.VertexArrayBindingInfo{
+			VertexBuffer: unwrapBuffer(binding.VertexBuffer),
+			Stride:       binding.Stride,
+		}
+	}
+	delegateInfo.IndexBuffer = unwrapBuffer(info.IndexBuffer)
+
+	vertexArray := r.delegate.CreateVertexArray(delegateInfo)
+	rec.ID = objectID(vertexArray)
+	r.write(record{
+		Kind:        recordKindVertexArray,
+		VertexArray: rec,
+	})
+	return vertexArray
+}
+
+func (r *Recorder) CreatePipeline(info render.PipelineInfo) render.Pipeline {
+	info.Program = unwrapProgram(info.Program)
+	return r.delegate.CreatePipeline(info)
+}
+
+func (r *Recorder) CreateCommandQueue() render.CommandQueue {
+	return &recordedQueue{
+		CommandQueue: r.delegate.CreateCommandQueue(),
+	}
+}
+
+func (r *Recorder) CreateFence() render.Fence {
+	return r.delegate.CreateFence()
+}
+
+func (r *Recorder) BeginRenderPass(info render.RenderPassInfo) {
+	rec := &renderPassRecord{
+		DefaultFramebuffer: info.Framebuffer == r.delegate.DefaultFramebuffer(),
+		FramebufferID:      objectID(info.Framebuffer),
+		Info:               info,
+	}
+	rec.Info.Framebuffer = nil
+	r.write(record{
+		Kind:       recordKindBeginRenderPass,
+		RenderPass: rec,
+	})
+	r.delegate.BeginRenderPass(info)
+}
+
+func (r *Recorder) EndRenderPass() {
+	r.write(record{
+		Kind: recordKindEndRenderPass,
+	})
+	r.delegate.EndRenderPass()
+}
+
+func (r *Recorder) Invalidate() {
+	r.write(record{
+		Kind: recordKindInvalidate,
+	})
+	r.delegate.Invalidate()
+}
+
+func (r *Recorder) BindPipeline(pipeline render.Pipeline) {
+	r.immediateQueue.BindPipeline(pipeline)
+	r.delegate.BindPipeline(pipeline)
+}
+
+func (r *Recorder) Uniform1f(location render.UniformLocation, value float32) {
+	r.immediateQueue.Uniform1f(location, value)
+	r.delegate.Uniform1f(location, value)
+}
+
+func (r *Recorder) Uniform1i(location render.UniformLocation, value int) {
+	r.immediateQueue.Uniform1i(location, value)
+	r.delegate.Uniform1i(location, value)
+}
+
+func (r *Recorder) Uniform3f(location render.UniformLocation, values [3]float32) {
+	r.immediateQueue.Uniform3f(location, values)
+	r.delegate.Uniform3f(location, values)
+}
+
+func (r *Recorder) Uniform4f(location render.UniformLocation, values [4]float32) {
+	r.immediateQueue.Uniform4f(location, values)
+	r.delegate.Uniform4f(location, values)
+}
+
+func (r *Recorder) UniformMatrix4f(location render.UniformLocation, values [16]float32) {
+	r.immediateQueue.UniformMatrix4f(location, values)
+	r.delegate.UniformMatrix4f(location, values)
+}
+
+func (r *Recorder) UniformBufferUnit(index int, buffer render.Buffer) {
+	buffer = unwrapBuffer(buffer)
+	r.immediateQueue.UniformBufferUnit(index, buffer)
+	r.delegate.UniformBufferUnit(index, buffer)
+}
+
+func (r *Recorder) UniformBufferUnitRange(index int, buffer render.Buffer, offset, size int) {
+	buffer = unwrapBuffer(buffer)
+	r.immediateQueue.UniformBufferUnitRange(index, buffer, offset, size)
+	r.delegate.UniformBufferUnitRange(index, buffer, offset, size)
+}
+
+func (r *Recorder) TextureUnit(index int, texture render.Texture) {
+	r.immediateQueue.TextureUnit(index, texture)
+	r.delegate.TextureUnit(index, texture)
+}
+
+func (r *Recorder) Draw(vertexOffset, vertexCount, instanceCount int) {
+	r.immediateQueue.Draw(vertexOffset, vertexCount, instanceCount)
+	r.delegate.Draw(vertexOffset, vertexCount, instanceCount)
+}
+
+func (r *Recorder) DrawIndexed(indexOffset, indexCount, instanceCount int) {
+	r.immediateQueue.DrawIndexed(indexOffset, indexCount, instanceCount)
+	r.delegate.DrawIndexed(indexOffset, indexCount, instanceCount)
+}
+
+func (r *Recorder) CopyContentToTexture(info render.CopyContentToTextureInfo) {
+	rec := &copyContentToTextureRecord{
+		TextureID: objectID(info.Texture),
+		Info:      info,
+	}
+	rec.Info.Texture = nil
+	r.write(record{
+		Kind:                 recordKindCopyContentToTexture,
+		CopyContentToTexture: rec,
+	})
+	r.delegate.CopyContentToTexture(info)
+}
+
+func (r *Recorder) SubmitQueue(queue render.CommandQueue) {
+	delegateQueue := queue.(*recordedQueue).CommandQueue
+	data := internal.CommandData(delegateQueue.(*internal.CommandQueue))
+	r.write(record{
+		Kind: recordKindQueue,
+		Queue: &queueRecord{
+			Data: data,
+		},
+	})
+	r.delegate.SubmitQueue(delegateQueue)
+}
+
+func (r *Recorder) recordBuffer(kind recordKind, buffer render.Buffer, info render.BufferInfo) render.Buffer {
+	r.write(record{
+		Kind: kind,
+		Buffer: &bufferRecord{
+			ID:   objectID(buffer),
+			Info: info,
+		},
+	})
+	return &recordedBuffer{
+		Buffer:   buffer,
+		recorder: r,
+	}
+}
+
+func (r *Recorder) flushImmediateQueue() {
+	if !internal.MoreCommands(r.immediateQueue) {
+		return
+	}
+	r.encode(record{
+		Kind: recordKindQueue,
+		Queue: &queueRecord{
+			Data: internal.CommandData(r.immediateQueue),
+		},
+	})
+	r.immediateQueue.Reset()
+}
+
+func (r *Recorder) write(rec record) {
+	r.flushImmediateQueue()
+	r.encode(rec)
+}
+
+func (r *Recorder) encode(rec record) {
+	if r.err != nil {
+		return
+	}
+	if err := r.encoder.Encode(rec); err != nil {
+		r.err = fmt.Errorf("failed to write record: %w", err)
+	}
+}
+
+type recordedProgram struct {
+	render.Program
+	recorder *Recorder
+}
+
+func (p *recordedProgram) UniformLocation(name string) render.UniformLocation {
+	location := p.Program.UniformLocation(name)
+	p.recorder.write(record{
+		Kind: recordKindUniformLocation,
+		UniformLocation: &uniformLocationRecord{
+			ProgramID: objectID(p.Program),
+			Name:      name,
+			Location:  location.(int32),
+		},
+	})
+	return location
+}
+
+type recordedBuffer struct {
+	render.Buffer
+	recorder *Recorder
+}
+
+func (b *recordedBuffer) Update(info render.BufferUpdateInfo) {
+	b.recorder.write(record{
+		Kind: recordKindBufferUpdate,
+		BufferUpdate: &bufferUpdateRecord{
+			ID:   objectID(b.Buffer),
+			Info: info,
+		},
+	})
+	b.Buffer.Update(info)
+}
+
+type recordedQueue struct {
+	render.CommandQueue
+}
+
+func (q *recordedQueue) UniformBufferUnit(index int, buffer render.Buffer) {
+	q.CommandQueue.UniformBufferUnit(index, unwrapBuffer(buffer))
+}
+
+func (q *recordedQueue) UniformBufferUnitRange(index int, buffer render.Buffer, offset, size int) {
+	q.CommandQueue.UniformBufferUnitRange(index, unwrapBuffer(buffer), offset, size)
+}
+
+func (q *recordedQueue) CopyContentToBuffer(info render.CopyContentToBufferInfo) {
+	info.Buffer = unwrapBuffer(info.Buffer)
+	q.CommandQueue.CopyContentToBuffer(info)
+}
+
+func (q *recordedQueue) UpdateBufferData(buffer render.Buffer, info render.BufferUpdateInfo) {
+	q.CommandQueue.UpdateBufferData(unwrapBuffer(buffer), info)
+}
+
+func unwrapBuffer(buffer render.Buffer) render.Buffer {
+	if recorded, ok := buffer.(*recordedBuffer); ok {
+		return recorded.Buffer
+	}
+	return buffer
+}
+
+func unwrapProgram(program render.Program) render.Program {
+	if recorded, ok := program.(*recordedProgram); ok {
+		return recorded.Program
+	}
+	return program
+}
+
+// objectID returns the OpenGL name of the specified resource or zero
+// if the resource is nil. The resources of this module's render package
+// expose their names through an ID method.
+func objectID(resource any) uint32 {
+	switch resource := resource.(type) {
+	case nil:
+		return 0
+	case *recordedProgram:
+		return objectID(resource.Program)
+	case *recordedBuffer:
+		return objectID(resource.Buffer)
+	case interface{ ID() uint32 }:
+		return resource.ID()
+	default:
+		panic(fmt.Errorf("unsupported resource type %T", resource))
+	}
+}
@@ -0,0 +1,246 @@
+package capture
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/mokiat/lacking-gl/render/internal"
+	"github.com/mokiat/lacking/render"
+)
+
+// NewReplayer creates a new Replayer that reads a capture from the
+// specified reader.
+func NewReplayer(in io.Reader) (*Replayer, error) {
+	magicBytes := make([]byte, len(magic))
+	if _, err := io.ReadFull(in, magicBytes); err != nil {
+		return nil, fmt.Errorf("failed to read magic: %w", err)
+	}
+	if string(magicBytes) != magic {
+		return nil, errors.New("not a capture file")
+	}
+	decoder := gob.NewDecoder(in)
+	var header Header
+	if err := decoder.Decode(&header); err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	if header.Version != FormatVersion {
+		return nil, fmt.Errorf("unsupported capture version %d", header.Version)
+	}
+	return &Replayer{
+		decoder: decoder,
+		header:  header,
+	}, nil
+}
+
+// Replayer reproduces the calls that were stored in a capture.
+type Replayer struct {
+	decoder *gob.Decoder
+	header  Header
+}
+
+// Header returns the header of the capture.
+func (r *Replayer) Header() Header {
+	return r.header
+}
+
+// Replay executes all recorded calls against the specified API, which
+// needs to be an API created through this module's render package.
+// The onFrame callback, if specified, is called at the end of each
+// recorded frame, at which point the default framebuffer holds the
+// frame image.
+//
+// All resources that are created during the replay remain allocated
+// until the replay completes.
+func (r *Replayer) Replay(api render.API, onFrame func(index int)) error {
+	state := newReplayState(api)
+	defer state.release()
+
+	frameIndex := 0
+	for {
+		var rec record
+		if err := r.decoder.Decode(&rec); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("failed to read record: %w", err)
+		}
+		if rec.Kind == recordKindFrame {
+			if onFrame != nil {
+				onFrame(frameIndex)
+			}
+			frameIndex++
+			continue
+		}
+		if err := state.apply(rec); err != nil {
+			return err
+		}
+	}
+}
+
+func newReplayState(api render.API) *replayState {
+	return &replayState{
+		api:          api,
+		remapper:     internal.NewIDRemapper(),
+		textures:     make(map[uint32]render.Texture),
+		framebuffers: make(map[uint32]render.Framebuffer),
+		shaders:      make(map[uint32]render.Shader),
+		programs:     make(map[uint32]render.Program),
+		buffers:      make(map[uint32]render.Buffer),
+		vertexArrays: make(map[uint32]render.VertexArray),
+		queue:        api.CreateCommandQueue().(*internal.CommandQueue),
+	}
+}
+
+type replayState struct {
+	api          render.API
+	remapper     *internal.IDRemapper
+	textures     map[uint32]render.Texture
+	framebuffers map[uint32]render.Framebuffer
+	shaders      map[uint32]render.Shader
+	programs     map[uint32]render.Program
+	buffers      map[uint32]render.Buffer
+	vertexArrays map[uint32]render.VertexArray
+	queue        *internal.CommandQueue
+}
+
+func (s *replayState) apply(rec record) error {
+	switch rec.Kind {
+	case recordKindColorTexture2D:
+		s.addTexture(rec.ColorTexture2D.ID, s.api.CreateColorTexture2D(rec.ColorTexture2D.Info))
+	case recordKindColorTextureCube:
+		s.addTexture(rec.ColorTextureCube.ID, s.api.CreateColorTextureCube(rec.ColorTextureCube.Info))
+	case recordKindDepthTexture2D:
+		s.addTexture(rec.DepthTexture2D.ID, s.api.CreateDepthTexture2D(rec.DepthTexture2D.Info))
+	case recordKindStencilTexture2D:
+		s.addTexture(rec.StencilTexture2D.ID, s.api.CreateStencilTexture2D(rec.StencilTexture2D.Info))
+	case recordKindDepthStencilTexture2D:
+		s.addTexture(rec.DepthStencilTexture2D.ID, s.api.CreateDepthStencilTexture2D(rec.DepthStencilTexture2D.Info))
+	case recordKindFramebuffer:
+		info := render.FramebufferInfo{
+			DepthAttachment:        s.textures[rec.Framebuffer.DepthAttachment],
+			StencilAttachment:      s.textures[rec.Framebuffer.StencilAttachment],
+			DepthStencilAttachment: s.textures[rec.Framebuffer.DepthStencilAttachment],
+		}
+		for i, id := range rec.Framebuffer.ColorAttachments {
+			info.ColorAttachments[i] = s.textures[id]
+		}
+		s.framebuffers[rec.Framebuffer.ID] = s.api.CreateFramebuffer(info)
+	case recordKindVertexShader:
+		s.shaders[rec.Shader.ID] = s.api.CreateVertexShader(rec.Shader.Info)
+	case recordKindFragmentShader:
+		s.shaders[rec.Shader.ID] = s.api.CreateFragmentShader(rec.Shader.Info)
+	case recordKindProgram:
+		program := s.api.CreateProgram(render.ProgramInfo{
+			VertexShader:   s.shaders[rec.Program.VertexShader],
+			FragmentShader: s.shaders[rec.Program.FragmentShader],
+		})
+		s.programs[rec.Program.ID] = program
+		s.remapper.Programs[rec.Program.ID] = objectID(program)
+	case recordKindUniformLocation:
+		program, ok := s.programs[rec.UniformLocation.ProgramID]
+		if !ok {
+			return fmt.Errorf("unknown program %d", rec.UniformLocation.ProgramID)
+		}
+		locations, ok := s.remapper.UniformLocations[rec.UniformLocation.ProgramID]
+		if !ok {
+			locations = make(map[int32]int32)
+			s.remapper.UniformLocations[rec.UniformLocation.ProgramID] = locations
+		}
+		locations[rec.UniformLocation.Location] = program.UniformLocation(rec.UniformLocation.Name).(int32)
+	case recordKindVertexBuffer:
+		s.addBuffer(rec.Buffer.ID, s.api.CreateVertexBuffer(rec.Buffer.Info))
+	case recordKindIndexBuffer:
+		s.addBuffer(rec.Buffer.ID, s.api.CreateIndexBuffer(rec.Buffer.Info))
+	case recordKindPixelTransferBuffer:
+		s.addBuffer(rec.Buffer.ID, s.api.CreatePixelTransferBuffer(rec.Buffer.Info))
+	case recordKindUniformBuffer:
+		s.addBuffer(rec.Buffer.ID, s.api.CreateUniformBuffer(rec.Buffer.Info))
+	case recordKindBufferUpdate:
+		buffer, ok := s.buffers[rec.BufferUpdate.ID]
+		if !ok {
+			return fmt.Errorf("unknown buffer %d", rec.BufferUpdate.ID)
+		}
+		buffer.Update(rec.BufferUpdate.Info)
+	case recordKindVertexArray:
+		info := render.VertexArrayInfo{
+			Bindings:    make([]render.VertexArrayBindingInfo, len(rec.VertexArray.Bindings)),
+			Attributes:  rec.VertexArray.Attributes,
+			IndexBuffer: s.buffers[rec.VertexArray.IndexBuffer],
+			IndexFormat: rec.VertexArray.IndexFormat,
+		}
+		for i, binding := range rec.VertexArray.Bindings {
+			info.Bindings[i] = render.VertexArrayBindingInfo{
+				VertexBuffer: s.buffers[binding.VertexBuffer],
+				Stride:       binding.Stride,
+			}
+		}
+		vertexArray := s.api.CreateVertexArray(info)
+		s.vertexArrays[rec.VertexArray.ID] = vertexArray
+		s.remapper.VertexArrays[rec.VertexArray.ID] = objectID(vertexArray)
+	case recordKindBeginRenderPass:
+		info := rec.RenderPass.Info
+		if rec.RenderPass.DefaultFramebuffer {
+			info.Framebuffer = s.api.DefaultFramebuffer()
+		} else {
+			framebuffer, ok := s.framebuffers[rec.RenderPass.FramebufferID]
+			if !ok {
+				return fmt.Errorf("unknown framebuffer %d", rec.RenderPass.FramebufferID)
+			}
+			info.Framebuffer = framebuffer
+		}
+		s.api.BeginRenderPass(info)
+	case recordKindEndRenderPass:
+		s.api.EndRenderPass()
+	case recordKindInvalidate:
+		s.api.Invalidate()
+	case recordKindCopyContentToTexture:
+		texture, ok := s.textures[rec.CopyContentToTexture.TextureID]
+		if !ok {
+			return fmt.Errorf("unknown texture %d", rec.CopyContentToTexture.TextureID)
+		}
+		info := rec.CopyContentToTexture.Info
+		info.Texture = texture
+		s.api.CopyContentToTexture(info)
+	case recordKindQueue:
+		internal.PushData(s.queue, rec.Queue.Data)
+		s.remapper.Remap(s.queue)
+		s.api.SubmitQueue(s.queue)
+	default:
+		return fmt.Errorf("unknown record kind: %d", rec.Kind)
+	}
+	return nil
+}
+
+func (s *replayState) addTexture(id uint32, texture render.Texture) {
+	s.textures[id] = texture
+	s.remapper.Textures[id] = objectID(texture)
+}
+
+func (s *replayState) addBuffer(id uint32, buffer render.Buffer) {
+	s.buffers[id] = buffer
+	s.remapper.Buffers[id] = objectID(buffer)
+}
+
+func (s *replayState) release() {
+	s.queue.Release()
+	for _, vertexArray := range s.vertexArrays {
+		vertexArray.Release()
+	}
+	for _, buffer := range s.buffers {
+		buffer.Release()
+	}
+	for _, program := range s.programs {
+		program.Release()
+	}
+	for _, shader := range s.shaders {
+		shader.Release()
+	}
+	for _, framebuffer := range s.framebuffers {
+		framebuffer.Release()
+	}
+	for _, texture := range s.textures {
+		texture.Release()
+	}
+}
@@ -0,0 +1,37 @@
+package render
+
+import (
+	"math"
+
+	"github.com/mokiat/lacking-gl/render/internal"
+	"github.com/mokiat/lacking/log"
+	"github.com/mokiat/lacking/render"
+)
+
+// SetAsyncShaderCompile specifies whether programs are compiled and
+// linked in the background.
+func (a *API) SetAsyncShaderCompile(enabled bool) {
+	if enabled == a.asyncCompile {
+		return
+	}
+	if !a.capabilities.ParallelShaderCompile {
+		log.Warn("Async shader compilation not used, since the driver does not support it")
+		return
+	}
+	if enabled {
+		internal.SetMaxShaderCompilerThreads(a.capabilities, math.MaxUint32)
+	} else {
+		internal.SetMaxShaderCompilerThreads(a.capabilities, 0)
+	}
+	a.asyncCompile = enabled
+}
+
+// ProgramStatus returns whether the specified program is ready to be used.
+func (a *API) ProgramStatus(program render.Program) render.FenceStatus {
+	return program.(*internal.Program).Status()
+}
+
+// WaitProgram blocks until the specified program is ready to be used.
+func (a *API) WaitProgram(program render.Program) {
+	program.(*internal.Program).Wait()
+}
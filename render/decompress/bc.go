@@ -0,0 +1,95 @@
+package decompress
+
+import "encoding/binary"
+
+func decodeBC1(data []byte, block *pixelBlock) {
+	decodeColorBlock(data, block, true)
+}
+
+func decodeBC3(data []byte, block *pixelBlock) {
+	decodeColorBlock(data[8:], block, false)
+	decodeChannelBlock(data[:8], block, 3)
+}
+
+func decodeBC4(data []byte, block *pixelBlock) {
+	decodeChannelBlock(data, block, 0)
+	for i := range block {
+		block[i][1], block[i][2], block[i][3] = 0, 0, 255
+	}
+}
+
+func decodeBC5(data []byte, block *pixelBlock) {
+	decodeChannelBlock(data[:8], block, 0)
+	decodeChannelBlock(data[8:], block, 1)
+	for i := range block {
+		block[i][2], block[i][3] = 0, 255
+	}
+}
+
+// decodeColorBlock decodes the RGB part of BC1, BC2 and BC3 blocks. Only
+// BC1 blocks can use the mode with three colors and transparent black,
+// which is selected by the order of the endpoints.
+func decodeColorBlock(data []byte, block *pixelBlock, punchThrough bool) {
+	color0 := binary.LittleEndian.Uint16(data[0:])
+	color1 := binary.LittleEndian.Uint16(data[2:])
+	var palette [4][4]uint8
+	palette[0] = expandRGB565(color0)
+	palette[1] = expandRGB565(color1)
+	if punchThrough && color0 <= color1 {
+		for c := 0; c < 3; c++ {
+			palette[2][c] = uint8((int(palette[0][c]) + int(palette[1][c])) / 2)
+		}
+		palette[2][3] = 255
+		palette[3] = [4]uint8{0, 0, 0, 0}
+	} else {
+		for c := 0; c < 3; c++ {
+			palette[2][c] = uint8((2*int(palette[0][c]) + int(palette[1][c])) / 3)
+			palette[3][c] = uint8((int(palette[0][c]) + 2*int(palette[1][c])) / 3)
+		}
+		palette[2][3] = 255
+		palette[3][3] = 255
+	}
+	indices := binary.LittleEndian.Uint32(data[4:])
+	for i := range block {
+		block[i] = palette[(indices>>(2*i))&0x3]
+	}
+}
+
+// decodeChannelBlock decodes a single channel that is encoded like the
+// alpha of BC3 blocks into the specified channel of the block.
+func decodeChannelBlock(data []byte, block *pixelBlock, channel int) {
+	value0, value1 := int(data[0]), int(data[1])
+	var palette [8]uint8
+	palette[0] = uint8(value0)
+	palette[1] = uint8(value1)
+	if value0 > value1 {
+		for i := 1; i < 7; i++ {
+			palette[i+1] = uint8(((7-i)*value0 + i*value1 + 3) / 7)
+		}
+	} else {
+		for i := 1; i < 5; i++ {
+			palette[i+1] = uint8(((5-i)*value0 + i*value1 + 2) / 5)
+		}
+		palette[6] = 0
+		palette[7] = 255
+	}
+	var indices uint64
+	for i := 0; i < 6; i++ {
+		indices |= uint64(data[2+i]) << (8 * i)
+	}
+	for i := range block {
+		block[i][channel] = palette[(indices>>(3*i))&0x7]
+	}
+}
+
+func expandRGB565(color uint16) [4]uint8 {
+	r := uint8(color>>11) & 0x1F
+	g := uint8(color>>5) & 0x3F
+	b := uint8(color) & 0x1F
+	return [4]uint8{
+		r<<3 | r>>2,
+		g<<2 | g>>4,
+		b<<3 | b>>2,
+		255,
+	}
+}
@@ -0,0 +1,320 @@
+package decompress
+
+import "encoding/binary"
+
+// bc7Mode describes the layout of the blocks of a BC7 mode.
+type bc7Mode struct {
+	subsets       int
+	partitionBits int
+	rotationBits  int
+	selectionBits int
+	colorBits     int
+	alphaBits     int
+	endpointPBits bool
+	sharedPBits   bool
+	indexBits     int
+	secondaryBits int
+}
+
+var bc7Modes = [8]bc7Mode{
+	{subsets: 3, partitionBits: 4, colorBits: 4, endpointPBits: true, indexBits: 3},
+	{subsets: 2, partitionBits: 6, colorBits: 6, sharedPBits: true, indexBits: 3},
+	{subsets: 3, partitionBits: 6, colorBits: 5, indexBits: 2},
+	{subsets: 2, partitionBits: 6, colorBits: 7, endpointPBits: true, indexBits: 2},
+	{subsets: 1, rotationBits: 2, selectionBits: 1, colorBits: 5, alphaBits: 6, indexBits: 2, secondaryBits: 3},
+	{subsets: 1, rotationBits: 2, colorBits: 7, alphaBits: 8, indexBits: 2, secondaryBits: 2},
+	{subsets: 1, colorBits: 7, alphaBits: 7, endpointPBits: true, indexBits: 4},
+	{subsets: 2, partitionBits: 6, colorBits: 5, alphaBits: 5, endpointPBits: true, indexBits: 2},
+}
+
+func decodeBC7(data []byte, block *pixelBlock) {
+	reader := bitReader{
+		low:  binary.LittleEndian.Uint64(data[0:]),
+		high: binary.LittleEndian.Uint64(data[8:]),
+	}
+	modeIndex := 0
+	for modeIndex < 8 && reader.read(1) == 0 {
+		modeIndex++
+	}
+	if modeIndex == 8 {
+		*block = pixelBlock{} // reserved mode
+		return
+	}
+	mode := bc7Modes[modeIndex]
+
+	partition := reader.read(mode.partitionBits)
+	rotation := reader.read(mode.rotationBits)
+	selection := reader.read(mode.selectionBits)
+
+	// Endpoints are stored channel by channel, with the two endpoints of
+	// each subset next to each other.
+	endpointCount := mode.subsets * 2
+	var endpoints [6][4]int
+	for c := 0; c < 3; c++ {
+		for e := 0; e < endpointCount; e++ {
+			endpoints[e][c] = reader.read(mode.colorBits)
+		}
+	}
+	for e := 0; e < endpointCount; e++ {
+		endpoints[e][3] = reader.read(mode.alphaBits)
+	}
+
+	colorBits, alphaBits := mode.colorBits, mode.alphaBits
+	if mode.endpointPBits || mode.sharedPBits {
+		var pBits [6]int
+		if mode.endpointPBits {
+			for e := 0; e < endpointCount; e++ {
+				pBits[e] = reader.read(1)
+			}
+		} else {
+			for s := 0; s < mode.subsets; s++ {
+				pBit := reader.read(1)
+				pBits[s*2], pBits[s*2+1] = pBit, pBit
+			}
+		}
+		for e := 0; e < endpointCount; e++ {
+			for c := 0; c < 4; c++ {
+				endpoints[e][c] = endpoints[e][c]<<1 | pBits[e]
+			}
+		}
+		colorBits++
+		if alphaBits > 0 {
+			alphaBits++
+		}
+	}
+	for e := 0; e < endpointCount; e++ {
+		for c := 0; c < 3; c++ {
+			endpoints[e][c] = expandBits(endpoints[e][c], colorBits)
+		}
+		if alphaBits > 0 {
+			endpoints[e][3] = expandBits(endpoints[e][3], alphaBits)
+		} else {
+			endpoints[e][3] = 255
+		}
+	}
+
+	var subsetOf [16]int
+	switch mode.subsets {
+	case 2:
+		for i := range subsetOf {
+			subsetOf[i] = int(bc7Partitions2[partition]>>i) & 0x1
+		}
+	case 3:
+		subsetOf = bc7Partitions3[partition]
+	}
+	isAnchor := func(i int) bool {
+		switch {
+		case i == 0:
+			return true
+		case mode.subsets == 2:
+			return i == int(bc7Anchors2[partition])
+		case mode.subsets == 3:
+			return i == int(bc7Anchors3Second[partition]) || i == int(bc7Anchors3Third[partition])
+		default:
+			return false
+		}
+	}
+
+	var indices [16]int
+	for i := range indices {
+		bits := mode.indexBits
+		if isAnchor(i) {
+			bits--
+		}
+		indices[i] = reader.read(bits)
+	}
+	var secondaryIndices [16]int
+	if mode.secondaryBits > 0 {
+		for i := range secondaryIndices {
+			bits := mode.secondaryBits
+			if i == 0 {
+				bits--
+			}
+			secondaryIndices[i] = reader.read(bits)
+		}
+	}
+
+	for i := range block {
+		endpoint0 := endpoints[subsetOf[i]*2]
+		endpoint1 := endpoints[subsetOf[i]*2+1]
+		colorWeight := bc7Weight(mode.indexBits, indices[i])
+		alphaWeight := colorWeight
+		if mode.secondaryBits > 0 {
+			alphaWeight = bc7Weight(mode.secondaryBits, secondaryIndices[i])
+			if selection == 1 {
+				colorWeight, alphaWeight = alphaWeight, colorWeight
+			}
+		}
+		var pixel [4]uint8
+		for c := 0; c < 3; c++ {
+			pixel[c] = uint8(((64-colorWeight)*endpoint0[c] + colorWeight*endpoint1[c] + 32) >> 6)
+		}
+		pixel[3] = uint8(((64-alphaWeight)*endpoint0[3] + alphaWeight*endpoint1[3] + 32) >> 6)
+		if rotation > 0 {
+			pixel[rotation-1], pixel[3] = pixel[3], pixel[rotation-1]
+		}
+		block[i] = pixel
+	}
+}
+
+func bc7Weight(bits, index int) int {
+	switch bits {
+	case 2:
+		return bc7Weights2[index]
+	case 3:
+		return bc7Weights3[index]
+	default:
+		return bc7Weights4[index]
+	}
+}
+
+// expandBits extends a value with the specified number of bits to eight
+// bits, by replicating its highest bits.
+func expandBits(value, bits int) int {
+	value <<= 8 - bits
+	return value | value>>bits
+}
+
+// bitReader reads the bits of a 128-bit block, starting from the least
+// significant one.
+type bitReader struct {
+	low  uint64
+	high uint64
+}
+
+func (r *bitReader) read(count int) int {
+	if count == 0 {
+		return 0
+	}
+	result := r.low & (1<<count - 1)
+	r.low = r.low>>count | r.high<<(64-count)
+	r.high >>= count
+	return int(result)
+}
+
+var (
+	bc7Weights2 = [4]int{0, 21, 43, 64}
+	bc7Weights3 = [8]int{0, 9, 18, 27, 37, 46, 55, 64}
+	bc7Weights4 = [16]int{0, 4, 9, 13, 17, 21, 26, 30, 34, 38, 43, 47, 51, 55, 60, 64}
+)
+
+// bc7Partitions2 holds the two-subset partitions, where each bit selects
+// the subset of the respective pixel.
+var bc7Partitions2 = [64]uint16{
+	0xCCCC, 0x8888, 0xEEEE, 0xECC8, 0xC880, 0xFEEC, 0xFEC8, 0xEC80,
+	0xC800, 0xFFEC, 0xFE80, 0xE800, 0xFFE8, 0xFF00, 0xFFF0, 0xF000,
+	0xF710, 0x008E, 0x7100, 0x08CE, 0x008C, 0x7310, 0x3100, 0x8CCE,
+	0x088C, 0x3110, 0x6666, 0x366C, 0x17E8, 0x0FF0, 0x718E, 0x399C,
+	0xAAAA, 0xF0F0, 0x5A5A, 0x33CC, 0x3C3C, 0x55AA, 0x9696, 0xA55A,
+	0x73CE, 0x13C8, 0x324C, 0x3BDC, 0x6996, 0xC33C, 0x9966, 0x0660,
+	0x0272, 0x04E4, 0x4E40, 0x2720, 0xC936, 0x936C, 0x39C6, 0x639C,
+	0x9336, 0x9CC6, 0x817E, 0xE718, 0xCCF0, 0x0FCC, 0x7744, 0xEE22,
+}
+
+// bc7Partitions3 holds the subset of each pixel of the three-subset
+// partitions.
+var bc7Partitions3 = [64][16]int{
+	{0, 0, 1, 1, 0, 0, 1, 1, 0, 2, 2, 1, 2, 2, 2, 2},
+	{0, 0, 0, 1, 0, 0, 1, 1, 2, 2, 1, 1, 2, 2, 2, 1},
+	{0, 0, 0, 0, 2, 0, 0, 1, 2, 2, 1, 1, 2, 2, 1, 1},
+	{0, 2, 2, 2, 0, 0, 2, 2, 0, 0, 1, 1, 0, 1, 1, 1},
+	{0, 0, 0, 0, 0, 0, 0, 0, 1, 1, 2, 2, 1, 1, 2, 2},
+	{0, 0, 1, 1, 0, 0, 1, 1, 0, 0, 2, 2, 0, 0, 2, 2},
+	{0, 0, 2, 2, 0, 0, 2, 2, 1, 1, 1, 1, 1, 1, 1, 1},
+	{0, 0, 1, 1, 0, 0, 1, 1, 2, 2, 1, 1, 2, 2, 1, 1},
+	{0, 0, 0, 0, 0, 0, 0, 0, 1, 1, 1, 1, 2, 2, 2, 2},
+	{0, 0, 0, 0, 1, 1, 1, 1, 1, 1, 1, 1, 2, 2, 2, 2},
+	{0, 0, 0, 0, 1, 1, 1, 1, 2, 2, 2, 2, 2, 2, 2, 2},
+	{0, 0, 1, 2, 0, 0, 1, 2, 0, 0, 1, 2, 0, 0, 1, 2},
+	{0, 1, 1, 2, 0, 1, 1, 2, 0, 1, 1, 2, 0, 1, 1, 2},
+	{0, 1, 2, 2, 0, 1, 2, 2, 0, 1, 2, 2, 0, 1, 2, 2},
+	{0, 0, 1, 1, 0, 1, 1, 2, 1, 1, 2, 2, 1, 2, 2, 2},
+	{0, 0, 1, 1, 2, 0, 0, 1, 2, 2, 0, 0, 2, 2, 2, 0},
+	{0, 0, 0, 1, 0, 0, 1, 1, 0, 1, 1, 2, 1, 1, 2, 2},
+	{0, 1, 1, 1, 0, 0, 1, 1, 2, 0, 0, 1, 2, 2, 0, 0},
+	{0, 0, 0, 0, 1, 1, 2, 2, 1, 1, 2, 2, 1, 1, 2, 2},
+	{0, 0, 2, 2, 0, 0, 2, 2, 0, 0, 2, 2, 1, 1, 1, 1},
+	{0, 1, 1, 1, 0, 1, 1, 1, 0, 2, 2, 2, 0, 2, 2, 2},
+	{0, 0, 0, 1, 0, 0, 0, 1, 2, 2, 2, 1, 2, 2, 2, 1},
+	{0, 0, 0, 0, 0, 0, 1, 1, 0, 1, 2, 2, 0, 1, 2, 2},
+	{0, 0, 0, 0, 1, 1, 0, 0, 2, 2, 1, 0, 2, 2, 1, 0},
+	{0, 1, 2, 2, 0, 1, 2, 2, 0, 0, 1, 1, 0, 0, 0, 0},
+	{0, 0, 1, 2, 0, 0, 1, 2, 1, 1, 2, 2, 2, 2, 2, 2},
+	{0, 1, 1, 0, 1, 2, 2, 1, 1, 2, 2, 1, 0, 1, 1, 0},
+	{0, 0, 0, 0, 0, 1, 1, 0, 1, 2, 2, 1, 1, 2, 2, 1},
+	{0, 0, 2, 2, 1, 1, 0, 2, 1, 1, 0, 2, 0, 0, 2, 2},
+	{0, 1, 1, 0, 0, 1, 1, 0, 2, 0, 0, 2, 2, 2, 2, 2},
+	{0, 0, 1, 1, 0, 1, 2, 2, 0, 1, 2, 2, 0, 0, 1, 1},
+	{0, 0, 0, 0, 2, 0, 0, 0, 2, 2, 1, 1, 2, 2, 2, 1},
+	{0, 0, 0, 0, 0, 0, 0, 2, 1, 1, 2, 2, 1, 2, 2, 2},
+	{0, 2, 2, 2, 0, 0, 2, 2, 0, 0, 1, 2, 0, 0, 1, 1},
+	{0, 0, 1, 1, 0, 0, 1, 2, 0, 0, 2, 2, 0, 2, 2, 2},
+	{0, 1, 2, 0, 0, 1, 2, 0, 0, 1, 2, 0, 0, 1, 2, 0},
+	{0, 0, 0, 0, 1, 1, 1, 1, 2, 2, 2, 2, 0, 0, 0, 0},
+	{0, 1, 2, 0, 1, 2, 0, 1, 2, 0, 1, 2, 0, 1, 2, 0},
+	{0, 1, 2, 0, 2, 0, 1, 2, 1, 2, 0, 1, 0, 1, 2, 0},
+	{0, 0, 1, 1, 2, 2, 0, 0, 1, 1, 2, 2, 0, 0, 1, 1},
+	{0, 0, 1, 1, 1, 1, 2, 2, 2, 2, 0, 0, 0, 0, 1, 1},
+	{0, 1, 0, 1, 0, 1, 0, 1, 2, 2, 2, 2, 2, 2, 2, 2},
+	{0, 0, 0, 0, 0, 0, 0, 0, 2, 1, 2, 1, 2, 1, 2, 1},
+	{0, 0, 2, 2, 1, 1, 2, 2, 0, 0, 2, 2, 1, 1, 2, 2},
+	{0, 0, 2, 2, 0, 0, 1, 1, 0, 0, 2, 2, 0, 0, 1, 1},
+	{0, 2, 2, 0, 1, 2, 2, 1, 0, 2, 2, 0, 1, 2, 2, 1},
+	{0, 1, 0, 1, 2, 2, 2, 2, 2, 2, 2, 2, 0, 1, 0, 1},
+	{0, 0, 0, 0, 2, 1, 2, 1, 2, 1, 2, 1, 2, 1, 2, 1},
+	{0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 2, 2, 2, 2},
+	{0, 2, 2, 2, 0, 1, 1, 1, 0, 2, 2, 2, 0, 1, 1, 1},
+	{0, 0, 0, 2, 1, 1, 1, 2, 0, 0, 0, 2, 1, 1, 1, 2},
+	{0, 0, 0, 0, 2, 1, 1, 2, 2, 1, 1, 2, 2, 1, 1, 2},
+	{0, 2, 2, 2, 0, 1, 1, 1, 0, 1, 1, 1, 0, 2, 2, 2},
+	{0, 0, 0, 2, 1, 1, 1, 2, 1, 1, 1, 2, 0, 0, 0, 2},
+	{0, 1, 1, 0, 0, 1, 1, 0, 0, 1, 1, 0, 2, 2, 2, 2},
+	{0, 0, 0, 0, 0, 0, 0, 0, 2, 1, 1, 2, 2, 1, 1, 2},
+	{0, 1, 1, 0, 0, 1, 1, 0, 2, 2, 2, 2, 2, 2, 2, 2},
+	{0, 0, 2, 2, 0, 0, 1, 1, 0, 0, 1, 1, 0, 0, 2, 2},
+	{0, 0, 2, 2, 1, 1, 2, 2, 1, 1, 2, 2, 0, 0, 2, 2},
+	{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 2, 1, 1, 2},
+	{0, 0, 0, 2, 0, 0, 0, 1, 0, 0, 0, 2, 0, 0, 0, 1},
+	{0, 2, 2, 2, 1, 2, 2, 2, 0, 2, 2, 2, 1, 2, 2, 2},
+	{0, 1, 0, 1, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2},
+	{0, 1, 1, 1, 2, 0, 1, 1, 2, 2, 0, 1, 2, 2, 2, 0},
+}
+
+// bc7Anchors2 holds the anchor pixel of the second subset of each
+// two-subset partition. The anchor of the first subset is always zero.
+var bc7Anchors2 = [64]uint8{
+	15, 15, 15, 15, 15, 15, 15, 15,
+	15, 15, 15, 15, 15, 15, 15, 15,
+	15, 2, 8, 2, 2, 8, 8, 15,
+	2, 8, 2, 2, 8, 8, 2, 2,
+	15, 15, 6, 8, 2, 8, 15, 15,
+	2, 8, 2, 2, 2, 15, 15, 6,
+	6, 2, 6, 8, 15, 15, 2, 2,
+	15, 15, 15, 15, 15, 2, 2, 15,
+}
+
+// bc7Anchors3Second holds the anchor pixel of the second subset of each
+// three-subset partition.
+var bc7Anchors3Second = [64]uint8{
+	3, 3, 15, 15, 8, 3, 15, 15,
+	8, 8, 6, 6, 6, 5, 3, 3,
+	3, 3, 8, 15, 3, 3, 6, 10,
+	5, 8, 8, 6, 8, 5, 15, 15,
+	8, 15, 3, 5, 6, 10, 8, 15,
+	15, 3, 15, 5, 15, 15, 15, 15,
+	3, 15, 5, 5, 5, 8, 5, 10,
+	5, 10, 8, 13, 15, 12, 3, 3,
+}
+
+// bc7Anchors3Third holds the anchor pixel of the third subset of each
+// three-subset partition.
+var bc7Anchors3Third = [64]uint8{
+	15, 8, 8, 3, 15, 15, 3, 8,
+	15, 15, 15, 15, 15, 15, 15, 8,
+	15, 8, 15, 3, 15, 8, 15, 8,
+	3, 15, 6, 10, 15, 15, 10, 8,
+	15, 3, 15, 10, 10, 8, 9, 10,
+	6, 15, 8, 15, 3, 6, 6, 8,
+	15, 3, 15, 15, 15, 15, 15, 15,
+	15, 15, 15, 15, 3, 15, 15, 8,
+}
@@ -0,0 +1,46 @@
+package decompress
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDecodeBC7(t *testing.T) {
+	// The expected pixels are those of Mesa's decoder. Apart from the mode
+	// bits, the blocks hold arbitrary data, so that partitions, rotations,
+	// index selections and p-bits are covered.
+	testCases := []struct {
+		name     string
+		block    string
+		expected string
+	}{
+		{name: "mode 0", block: "f3ff4d451e429e182215aaee06a2d64b", expected: "f73f55fff7bba1fff7d9b3fff7bba1ffb90707ffb90707fff70000ffa10909fff70000ffcd0505ff8d0c0cff8d0c0cff35daa4ff7280a0ff99469dff99469dff"},
+		{name: "mode 1", block: "6e1aadc9e5031e4b99bf11ae0a796ebc", expected: "6a972eff798a3dff6881e5ffc91cbdff887e4cffae38c8ff8365daff6a972eff798a3dffc91cbdff7673dfffd33e97ffbb2ac3ff6881e5ffd33e97ffb55779ff"},
+		{name: "mode 2", block: "44c85fd174bfccf43cb5f561cd0040e8", expected: "6ac355ff21f74affff5a6bff21f74aff21f74aff21f74aff21f74aff21f74aff5a315aff5a315aff7c79c9ff5a315aff31f784ff6ff291ffefe7adff6ff291ff"},
+		{name: "mode 3", block: "586209385c6601ddb3fc1472b881d99c", expected: "b13359ff70f6c8ff7a298eff70f6c8ffb13359ff70a028ffb13359ff70f6c8ff70a028ff3f20c7ff70bc5dff0816fcff70a028ff0816fcff70bc5dff3f20c7ff"},
+		{name: "mode 4", block: "9028183c3fae7166ecbd7cc3ba26c55e", expected: "325bb8e308c6ff91189ce3693a46aae32087d569189ce3e310b1f169189ce3e310b1f1912087d5bb2087d569325bb8692087d591189ce36908c6ffbb325bb8bb"},
+		{name: "mode 5", block: "205169c92f2f4691fae28d00f74ecaf2", expected: "a34ae56ca5fd8b6ca5fd8ba4a485c7a4a485c789a34ae5a4a5fd8b51a5fd8b6ca4c2a989a485c789a34ae551a485c7a4a34ae589a34ae551a34ae5a4a4c2a9a4"},
+		{name: "mode 6", block: "40a41c0d577477c4b2615a7b07d8dd0a", expected: "96d21f78cedc8e8396d21f78b3d7587ec9db8482acd64b7dcedc8e83b8d8627fb8d8627f91d11577bdd96d80d8dea386d8dea386d8dea386c9db848291d11577"},
+		{name: "mode 7", block: "808f6026f0560c8be735092e6fd13226", expected: "2bbb6e762bbb6e7661698a9246917d8510e3616946917d8546917d852bbb6e762bbb6e7646917d852bbb6e7610e361690c347dbe342c3c04272f5141342c3c04"},
+		{name: "reserved mode", block: "00df4d6b15c52422554e2ca75f2c3053", expected: "00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assertBlock(t, decodeBC7, testCase.block, testCase.expected)
+		})
+	}
+}
+
+// assertBlock decodes the specified block and compares the pixels against
+// the expected ones, both of which are hex encoded.
+func assertBlock(t *testing.T, decode func(data []byte, block *pixelBlock), block, expected string) {
+	t.Helper()
+	var pixels pixelBlock
+	decode(mustDecodeHex(t, block), &pixels)
+	expectedPixels := mustDecodeHex(t, expected)
+	for i, pixel := range pixels {
+		if !bytes.Equal(pixel[:], expectedPixels[i*4:i*4+4]) {
+			t.Errorf("pixel %d: expected %v, got %v", i, expectedPixels[i*4:i*4+4], pixel)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package decompress
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+// bcIndices holds the 3-bit indices 0 to 7, twice, as used by the alpha
+// and single channel blocks.
+const bcIndices = "88c6fa88c6fa"
+
+func TestDecodeBC1(t *testing.T) {
+	testCases := []struct {
+		name  string
+		block string
+		row   [4][4]uint8
+	}{
+		{
+			// Red and blue endpoints, where color0 > color1 selects the
+			// four color mode.
+			name:  "four colors",
+			block: "00f81f00e4e4e4e4",
+			row: [4][4]uint8{
+				{255, 0, 0, 255},
+				{0, 0, 255, 255},
+				{170, 0, 85, 255},
+				{85, 0, 170, 255},
+			},
+		},
+		{
+			// Black and gray endpoints, where color0 <= color1 selects
+			// the three color mode with transparent black.
+			name:  "three colors and transparency",
+			block: "00001084e4e4e4e4",
+			row: [4][4]uint8{
+				{0, 0, 0, 255},
+				{132, 130, 132, 255},
+				{66, 65, 66, 255},
+				{0, 0, 0, 0},
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var block pixelBlock
+			decodeBC1(mustDecodeHex(t, testCase.block), &block)
+			for i, pixel := range block {
+				if expected := testCase.row[i%4]; pixel != expected {
+					t.Errorf("pixel %d: expected %v, got %v", i, expected, pixel)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeBC3(t *testing.T) {
+	// The alpha block uses the six value mode, while the color block has
+	// color0 <= color1, which BC3 still decodes in the four color mode.
+	alpha := [8]uint8{0, 250, 50, 100, 150, 200, 0, 255}
+	colors := [4][3]uint8{
+		{0, 0, 255},
+		{255, 0, 0},
+		{85, 0, 170},
+		{170, 0, 85},
+	}
+	var block pixelBlock
+	decodeBC3(mustDecodeHex(t, "00fa"+bcIndices+"1f0000f8e4e4e4e4"), &block)
+	for i, pixel := range block {
+		color := colors[i%4]
+		expected := [4]uint8{color[0], color[1], color[2], alpha[i%8]}
+		if pixel != expected {
+			t.Errorf("pixel %d: expected %v, got %v", i, expected, pixel)
+		}
+	}
+}
+
+func TestDecodeBC4(t *testing.T) {
+	testCases := []struct {
+		name    string
+		block   string
+		palette [8]uint8
+	}{
+		{
+			name:    "eight values",
+			block:   "8c00" + bcIndices,
+			palette: [8]uint8{140, 0, 120, 100, 80, 60, 40, 20},
+		},
+		{
+			name:    "six values",
+			block:   "00fa" + bcIndices,
+			palette: [8]uint8{0, 250, 50, 100, 150, 200, 0, 255},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var block pixelBlock
+			decodeBC4(mustDecodeHex(t, testCase.block), &block)
+			for i, pixel := range block {
+				if expected := [4]uint8{testCase.palette[i%8], 0, 0, 255}; pixel != expected {
+					t.Errorf("pixel %d: expected %v, got %v", i, expected, pixel)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeBC5(t *testing.T) {
+	red := [8]uint8{140, 0, 120, 100, 80, 60, 40, 20}
+	green := [8]uint8{0, 250, 50, 100, 150, 200, 0, 255}
+	var block pixelBlock
+	decodeBC5(mustDecodeHex(t, "8c00"+bcIndices+"00fa"+bcIndices), &block)
+	for i, pixel := range block {
+		if expected := [4]uint8{red[i%8], green[i%8], 0, 255}; pixel != expected {
+			t.Errorf("pixel %d: expected %v, got %v", i, expected, pixel)
+		}
+	}
+}
+
+func mustDecodeHex(t *testing.T, value string) []byte {
+	t.Helper()
+	result, err := hex.DecodeString(value)
+	if err != nil {
+		t.Fatalf("invalid hex string %q: %v", value, err)
+	}
+	return result
+}
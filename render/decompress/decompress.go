@@ -0,0 +1,61 @@
+// Package decompress decodes block-compressed texture data on the CPU.
+//
+// It is used when the driver does not support a compressed format (see
+// ext.Capabilities.CompressedFormats), so that such textures can still
+// be loaded, at the cost of the memory that compression would have saved.
+package decompress
+
+import (
+	"fmt"
+
+	"github.com/mokiat/lacking-gl/render/ext"
+	"github.com/mokiat/lacking/render"
+)
+
+// RGBA8 decodes an image of the specified size and block-compressed
+// format into RGBA8 pixels. The rows of pixels are in the same order as
+// the rows of blocks.
+//
+// Formats with fewer channels are expanded the same way as when they are
+// sampled (e.g. BC4 becomes red with zero green and blue and opaque
+// alpha).
+func RGBA8(format render.DataFormat, width, height int, data []byte) ([]byte, error) {
+	decodeBlock, ok := blockDecoders[format]
+	if !ok {
+		return nil, fmt.Errorf("unsupported format %v", format)
+	}
+	blockSize := ext.BlockSize(format)
+	blocksX, blocksY := (width+3)/4, (height+3)/4
+	if requiredSize := blocksX * blocksY * blockSize; len(data) < requiredSize {
+		return nil, fmt.Errorf("data is %d bytes long instead of %d", len(data), requiredSize)
+	}
+
+	result := make([]byte, width*height*4)
+	var block pixelBlock
+	for blockY := 0; blockY < blocksY; blockY++ {
+		for blockX := 0; blockX < blocksX; blockX++ {
+			offset := (blockY*blocksX + blockX) * blockSize
+			decodeBlock(data[offset:offset+blockSize], &block)
+			for y := 0; y < 4 && blockY*4+y < height; y++ {
+				for x := 0; x < 4 && blockX*4+x < width; x++ {
+					pixelOffset := ((blockY*4+y)*width + blockX*4 + x) * 4
+					copy(result[pixelOffset:pixelOffset+4], block[y*4+x][:])
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+// pixelBlock holds the RGBA8 pixels of a 4x4 block, row by row.
+type pixelBlock [16][4]uint8
+
+var blockDecoders = map[render.DataFormat]func(data []byte, block *pixelBlock){
+	ext.DataFormatBC1:       decodeBC1,
+	ext.DataFormatBC3:       decodeBC3,
+	ext.DataFormatBC4:       decodeBC4,
+	ext.DataFormatBC5:       decodeBC5,
+	ext.DataFormatBC7:       decodeBC7,
+	ext.DataFormatETC2RGB8:  decodeETC2RGB8,
+	ext.DataFormatETC2RGBA8: decodeETC2RGBA8,
+}
@@ -0,0 +1,48 @@
+package decompress
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mokiat/lacking-gl/render/ext"
+	"github.com/mokiat/lacking/render"
+)
+
+func TestRGBA8(t *testing.T) {
+	t.Run("partial blocks", func(t *testing.T) {
+		// Two BC4 blocks side by side, cropped to a 5x3 image. The first
+		// block is black and the second one is white.
+		data := mustDecodeHex(t, "0000000000000000"+"ffff000000000000")
+		result, err := RGBA8(ext.DataFormatBC4, 5, 3, data)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		black := []byte{0, 0, 0, 255}
+		white := []byte{255, 0, 0, 255}
+		var expected []byte
+		for y := 0; y < 3; y++ {
+			for x := 0; x < 5; x++ {
+				if x < 4 {
+					expected = append(expected, black...)
+				} else {
+					expected = append(expected, white...)
+				}
+			}
+		}
+		if !bytes.Equal(result, expected) {
+			t.Errorf("expected %v, got %v", expected, result)
+		}
+	})
+
+	t.Run("truncated data", func(t *testing.T) {
+		if _, err := RGBA8(ext.DataFormatBC1, 8, 4, make([]byte, 8)); err == nil {
+			t.Errorf("expected an error")
+		}
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		if _, err := RGBA8(render.DataFormatRGBA8, 4, 4, make([]byte, 64)); err == nil {
+			t.Errorf("expected an error")
+		}
+	})
+}
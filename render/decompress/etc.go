@@ -0,0 +1,259 @@
+package decompress
+
+import "encoding/binary"
+
+func decodeETC2RGB8(data []byte, block *pixelBlock) {
+	decodeETC2ColorBlock(data, block)
+}
+
+func decodeETC2RGBA8(data []byte, block *pixelBlock) {
+	decodeETC2ColorBlock(data[8:], block)
+	decodeEACAlphaBlock(data[:8], block)
+}
+
+// decodeETC2ColorBlock decodes the RGB part of ETC2 blocks. In addition
+// to the individual and differential modes of ETC1, ETC2 uses the
+// otherwise invalid differential blocks, where a base color overflows,
+// for the T, H and planar modes.
+//
+// Unlike the BC formats, the pixels of ETC blocks are indexed column by
+// column.
+func decodeETC2ColorBlock(data []byte, block *pixelBlock) {
+	high := binary.BigEndian.Uint32(data[0:])
+	low := binary.BigEndian.Uint32(data[4:])
+
+	if high&0x2 == 0 {
+		baseColor0 := [3]int{
+			expand4(int(high >> 28 & 0xF)),
+			expand4(int(high >> 20 & 0xF)),
+			expand4(int(high >> 12 & 0xF)),
+		}
+		baseColor1 := [3]int{
+			expand4(int(high >> 24 & 0xF)),
+			expand4(int(high >> 16 & 0xF)),
+			expand4(int(high >> 8 & 0xF)),
+		}
+		decodeETC1Subblocks(high, low, baseColor0, baseColor1, block)
+		return
+	}
+
+	red := int(high >> 27 & 0x1F)
+	green := int(high >> 19 & 0x1F)
+	blue := int(high >> 11 & 0x1F)
+	red2 := red + signExtend3(int(high>>24&0x7))
+	green2 := green + signExtend3(int(high>>16&0x7))
+	blue2 := blue + signExtend3(int(high>>8&0x7))
+	switch {
+	case red2 < 0 || red2 > 31:
+		decodeETC2TBlock(high, low, block)
+	case green2 < 0 || green2 > 31:
+		decodeETC2HBlock(high, low, block)
+	case blue2 < 0 || blue2 > 31:
+		decodeETC2PlanarBlock(high, low, block)
+	default:
+		baseColor0 := [3]int{expand5(red), expand5(green), expand5(blue)}
+		baseColor1 := [3]int{expand5(red2), expand5(green2), expand5(blue2)}
+		decodeETC1Subblocks(high, low, baseColor0, baseColor1, block)
+	}
+}
+
+// decodeETC1Subblocks decodes a block that is split into two subblocks,
+// each with its own base color and modifier table.
+func decodeETC1Subblocks(high, low uint32, baseColor0, baseColor1 [3]int, block *pixelBlock) {
+	flipped := high&0x1 != 0
+	tables := [2]int{int(high >> 5 & 0x7), int(high >> 2 & 0x7)}
+	baseColors := [2][3]int{baseColor0, baseColor1}
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			subblock := x / 2
+			if flipped {
+				subblock = y / 2
+			}
+			modifiers := etcModifiers[tables[subblock]]
+			var modifier int
+			switch etcPixelIndex(low, x, y) {
+			case 0:
+				modifier = modifiers[0]
+			case 1:
+				modifier = modifiers[1]
+			case 2:
+				modifier = -modifiers[0]
+			case 3:
+				modifier = -modifiers[1]
+			}
+			block[y*4+x] = etcColor(baseColors[subblock], modifier)
+		}
+	}
+}
+
+func decodeETC2TBlock(high, low uint32, block *pixelBlock) {
+	color0 := [3]int{
+		expand4(int(high>>27&0x3)<<2 | int(high>>24&0x3)),
+		expand4(int(high >> 20 & 0xF)),
+		expand4(int(high >> 16 & 0xF)),
+	}
+	color1 := [3]int{
+		expand4(int(high >> 12 & 0xF)),
+		expand4(int(high >> 8 & 0xF)),
+		expand4(int(high >> 4 & 0xF)),
+	}
+	distance := etcDistances[int(high>>2&0x3)<<1|int(high&0x1)]
+	palette := [4][4]uint8{
+		etcColor(color0, 0),
+		etcColor(color1, distance),
+		etcColor(color1, 0),
+		etcColor(color1, -distance),
+	}
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			block[y*4+x] = palette[etcPixelIndex(low, x, y)]
+		}
+	}
+}
+
+func decodeETC2HBlock(high, low uint32, block *pixelBlock) {
+	color0 := [3]int{
+		expand4(int(high >> 27 & 0xF)),
+		expand4(int(high>>24&0x7)<<1 | int(high>>20&0x1)),
+		expand4(int(high>>19&0x1)<<3 | int(high>>15&0x7)),
+	}
+	color1 := [3]int{
+		expand4(int(high >> 11 & 0xF)),
+		expand4(int(high >> 7 & 0xF)),
+		expand4(int(high >> 3 & 0xF)),
+	}
+	// The lowest bit of the distance index is implied by the order of
+	// the two colors.
+	distanceIndex := int(high>>2&0x1)<<2 | int(high&0x1)<<1
+	if color0[0]<<16|color0[1]<<8|color0[2] >= color1[0]<<16|color1[1]<<8|color1[2] {
+		distanceIndex |= 1
+	}
+	distance := etcDistances[distanceIndex]
+	palette := [4][4]uint8{
+		etcColor(color0, distance),
+		etcColor(color0, -distance),
+		etcColor(color1, distance),
+		etcColor(color1, -distance),
+	}
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			block[y*4+x] = palette[etcPixelIndex(low, x, y)]
+		}
+	}
+}
+
+// decodeETC2PlanarBlock decodes a block that holds a color gradient,
+// which is defined by the colors at its origin and at the pixels just
+// outside of its horizontal and vertical edges.
+func decodeETC2PlanarBlock(high, low uint32, block *pixelBlock) {
+	origin := [3]int{
+		expand6(int(high >> 25 & 0x3F)),
+		expand7(int(high>>24&0x1)<<6 | int(high>>17&0x3F)),
+		expand6(int(high>>16&0x1)<<5 | int(high>>11&0x3)<<3 | int(high>>7&0x7)),
+	}
+	horizontal := [3]int{
+		expand6(int(high>>2&0x1F)<<1 | int(high&0x1)),
+		expand7(int(low >> 25 & 0x7F)),
+		expand6(int(low >> 19 & 0x3F)),
+	}
+	vertical := [3]int{
+		expand6(int(low >> 13 & 0x3F)),
+		expand7(int(low >> 6 & 0x7F)),
+		expand6(int(low & 0x3F)),
+	}
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			var pixel [4]uint8
+			for c := 0; c < 3; c++ {
+				value := (x*(horizontal[c]-origin[c]) + y*(vertical[c]-origin[c]) + 4*origin[c] + 2) >> 2
+				pixel[c] = clampByte(value)
+			}
+			pixel[3] = 255
+			block[y*4+x] = pixel
+		}
+	}
+}
+
+// decodeEACAlphaBlock decodes the EAC-compressed alpha of ETC2 RGBA8
+// blocks.
+func decodeEACAlphaBlock(data []byte, block *pixelBlock) {
+	base := int(data[0])
+	multiplier := int(data[1] >> 4)
+	modifiers := eacModifiers[data[1]&0xF]
+	indices := binary.BigEndian.Uint64(data) & 0xFFFFFFFFFFFF
+	for x := 0; x < 4; x++ {
+		for y := 0; y < 4; y++ {
+			index := indices >> (45 - 3*(x*4+y)) & 0x7
+			block[y*4+x][3] = clampByte(base + modifiers[index]*multiplier)
+		}
+	}
+}
+
+// etcPixelIndex returns the two-bit index of the specified pixel, whose
+// high and low bits are stored in separate halves of the index bits.
+func etcPixelIndex(low uint32, x, y int) int {
+	bit := x*4 + y
+	return int(low>>(bit+16)&0x1)<<1 | int(low>>bit&0x1)
+}
+
+func etcColor(baseColor [3]int, modifier int) [4]uint8 {
+	return [4]uint8{
+		clampByte(baseColor[0] + modifier),
+		clampByte(baseColor[1] + modifier),
+		clampByte(baseColor[2] + modifier),
+		255,
+	}
+}
+
+func signExtend3(value int) int {
+	if value >= 4 {
+		return value - 8
+	}
+	return value
+}
+
+func expand4(value int) int {
+	return value<<4 | value
+}
+
+func expand5(value int) int {
+	return value<<3 | value>>2
+}
+
+func expand6(value int) int {
+	return value<<2 | value>>4
+}
+
+func expand7(value int) int {
+	return value<<1 | value>>6
+}
+
+func clampByte(value int) uint8 {
+	return uint8(min(max(value, 0), 255))
+}
+
+var etcModifiers = [8][2]int{
+	{2, 8}, {5, 17}, {9, 29}, {13, 42},
+	{18, 60}, {24, 80}, {33, 106}, {47, 183},
+}
+
+var etcDistances = [8]int{3, 6, 11, 16, 23, 32, 41, 64}
+
+var eacModifiers = [16][8]int{
+	{-3, -6, -9, -15, 2, 5, 8, 14},
+	{-3, -7, -10, -13, 2, 6, 9, 12},
+	{-2, -5, -8, -13, 1, 4, 7, 12},
+	{-2, -4, -6, -13, 1, 3, 5, 12},
+	{-3, -6, -8, -12, 2, 5, 7, 11},
+	{-3, -7, -9, -11, 2, 6, 8, 10},
+	{-4, -7, -8, -11, 3, 6, 7, 10},
+	{-3, -5, -8, -11, 2, 4, 7, 10},
+	{-2, -6, -8, -10, 1, 5, 7, 9},
+	{-2, -5, -8, -10, 1, 4, 7, 9},
+	{-2, -4, -8, -10, 1, 3, 7, 9},
+	{-2, -5, -7, -10, 1, 4, 6, 9},
+	{-3, -4, -7, -10, 2, 3, 6, 9},
+	{-1, -2, -3, -10, 0, 1, 2, 9},
+	{-4, -6, -8, -9, 3, 5, 7, 8},
+	{-3, -5, -7, -9, 2, 4, 6, 8},
+}
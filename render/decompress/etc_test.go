@@ -0,0 +1,43 @@
+package decompress
+
+import "testing"
+
+func TestDecodeETC2RGB8(t *testing.T) {
+	// The expected pixels are those of Mesa's decoder. The modes are
+	// selected through the differential bit and through the overflow of
+	// the red, green and blue differential sums.
+	testCases := []struct {
+		name     string
+		block    string
+		expected string
+	}{
+		{name: "individual mode", block: "c494d150ad051371", expected: "af7cc0ffe9b6faff080800ff80804dffd5a2e6ffe9b6faff80804dff323200ffc390d4ffe9b6faff323200ff565623ffd5a2e6ffd5a2e6ff323200ff323200ff"},
+		{name: "differential mode", block: "aed6b0eae1a7723c", expected: "7ea786ffffffffff93bdacffb9e3d2ff7ea786ff001f00ffb9e3d2ff7fa998ff001f00ffdcffe4ffa5cfbeff7fa998ffffffffff7ea786ffa5cfbeff93bdacff"},
+		{name: "T mode", block: "1c00dd92d4e7d5b2", expected: "dddd99ffe0e09cffe0e09cffdada96ffdada96ffdada96ffcc0000ffcc0000ffdddd99ffdddd99ffdada96ffdada96ffcc0000ffdada96ffcc0000ffdada96ff"},
+		{name: "H mode", block: "45f90dd7afadeadf", expected: "007b6aff487b6aff51fbeaffc8fbeaff487b6aff51fbeaff007b6aff007b6aff007b6aff487b6aff51fbeaff487b6aff007b6aff007b6aff007b6aff007b6aff"},
+		{name: "planar mode", block: "0fc70e579c5491cb", expected: "1cc7b2ff41bd90ff65b26dff8aa84bff3ab991ff5eaf6eff83a44cffa79a29ff57ab6fff7ca14dffa0962affc58c08ff759d4eff99932bffbe8809ffe27e00ff"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assertBlock(t, decodeETC2RGB8, testCase.block, testCase.expected)
+		})
+	}
+}
+
+func TestDecodeETC2RGBA8(t *testing.T) {
+	// The expected pixels are those of Mesa's decoder. A zero multiplier
+	// makes the EAC alpha block constant.
+	testCases := []struct {
+		name     string
+		block    string
+		expected string
+	}{
+		{name: "alpha", block: "c544a78a2b401ab4967031620c6a2984", expected: "a1803ed9a1803ed98c7b41a586753bd9876624ad876624b986753bb98c7b41a5be9d5bf1876624d9827137b986753be1876624b9be9d5b957c6b31ad86753bcd"},
+		{name: "alpha with zero multiplier", block: "740e995414c211331503004c73b9690b", expected: "00000074080000742b090074482600742e1d1d7408000074482600742b0900741a0909741a09097462400d742b09007400000074080000747f5d2a7462400d74"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assertBlock(t, decodeETC2RGBA8, testCase.block, testCase.expected)
+		})
+	}
+}
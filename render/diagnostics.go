@@ -0,0 +1,78 @@
+package render
+
+import (
+	"github.com/mokiat/lacking-gl/render/ext"
+	"github.com/mokiat/lacking-gl/render/internal"
+	"github.com/mokiat/lacking/log"
+	"github.com/mokiat/lacking/render"
+)
+
+// SetDiagnosticsHandler specifies a function that is called with the
+// diagnostics of shader compilations and program links.
+func (a *API) SetDiagnosticsHandler(handler ext.DiagnosticsHandler) {
+	a.diagnosticsHandler = handler
+}
+
+// ShaderErr returns the compilation error of the specified shader.
+func (a *API) ShaderErr(shader render.Shader) error {
+	return shader.(*internal.Shader).Err()
+}
+
+// ProgramErr returns the link error of the specified program.
+func (a *API) ProgramErr(program render.Program) error {
+	return program.(*internal.Program).Err()
+}
+
+// reportShaderDiagnostics reports the diagnostics of a newly created
+// shader. When a program cache or asynchronous compilation is used,
+// shaders are only compiled as part of a program, so their diagnostics
+// are reported with the program instead.
+func (a *API) reportShaderDiagnostics(shader *internal.Shader) {
+	if a.programCache == nil && !a.asyncCompile {
+		a.reportDiagnostics(shader.Diagnostics(), shader.Err())
+	}
+}
+
+// reportProgramDiagnostics reports the diagnostics of a newly created
+// program, preceded by those of the pending shaders that were compiled
+// while creating it. For programs that are linked in the background,
+// this is deferred until the link results are collected.
+func (a *API) reportProgramDiagnostics(program *internal.Program, pending []*internal.Shader) {
+	if program.Linking() {
+		program.SetCompleteCallback(func() {
+			a.reportProgramDiagnostics(program, pending)
+		})
+		return
+	}
+	for _, shader := range pending {
+		if shader.Compiled() {
+			a.reportDiagnostics(shader.Diagnostics(), shader.Err())
+		}
+	}
+	a.reportDiagnostics(program.Diagnostics(), program.Err())
+}
+
+// pendingShaders returns the specified shaders that have not been
+// compiled yet. Shaders that are being compiled in the background are
+// excluded, since they are reported with the program that started it.
+func pendingShaders(shaders ...render.Shader) []*internal.Shader {
+	var result []*internal.Shader
+	for _, shader := range shaders {
+		if intShader, ok := shader.(*internal.Shader); ok && !intShader.Compiled() && !intShader.Compiling() {
+			result = append(result, intShader)
+		}
+	}
+	return result
+}
+
+func (a *API) reportDiagnostics(diagnostics []ext.Diagnostic, err error) {
+	if a.diagnosticsHandler == nil {
+		if err != nil {
+			log.Error("Shader error: %v", err)
+		}
+		return
+	}
+	if len(diagnostics) > 0 || err != nil {
+		a.diagnosticsHandler(diagnostics, err)
+	}
+}
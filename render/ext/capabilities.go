@@ -0,0 +1,146 @@
+package ext
+
+import (
+	"slices"
+
+	"github.com/mokiat/lacking/render"
+)
+
+// Version represents an OpenGL version.
+type Version struct {
+	Major int
+	Minor int
+}
+
+// AtLeast returns whether this version is equal to or newer than the
+// specified one.
+func (v Version) AtLeast(major, minor int) bool {
+	if v.Major != major {
+		return v.Major > major
+	}
+	return v.Minor >= minor
+}
+
+// Capabilities holds information about the OpenGL implementation that
+// was queried from the driver.
+type Capabilities struct {
+
+	// Quality is the quality tier that was picked based on the rest
+	// of the capabilities. It is the same value that is returned through
+	// render.API.Capabilities.
+	Quality render.Quality
+
+	// Version is the OpenGL version of the context.
+	Version Version
+
+	// VersionString is the full version string reported by the driver.
+	VersionString string
+
+	// ShadingLanguageVersion is the GLSL version string reported by
+	// the driver.
+	ShadingLanguageVersion string
+
+	// Vendor is the company responsible for the implementation.
+	Vendor string
+
+	// Renderer is the name of the renderer, which is usually specific
+	// to the hardware.
+	Renderer string
+
+	// Software indicates that the renderer is known to be a software
+	// rasterizer (e.g. llvmpipe).
+	Software bool
+
+	// MaxTextureSize is the largest width or height of a 2D texture.
+	MaxTextureSize int
+
+	// MaxCubeTextureSize is the largest dimension of a cube texture.
+	MaxCubeTextureSize int
+
+	// MaxTextureUnits is the number of texture units that can be used
+	// by a program.
+	MaxTextureUnits int
+
+	// MaxUniformBlockSize is the largest size, in bytes, of a uniform
+	// block.
+	MaxUniformBlockSize int
+
+	// MaxUniformBufferBindings is the number of uniform buffer
+	// binding points.
+	MaxUniformBufferBindings int
+
+	// UniformBufferOffsetAlignment is the alignment, in bytes, that the
+	// offset of a uniform buffer range binding needs to have.
+	UniformBufferOffsetAlignment int
+
+	// MaxAnisotropy is the largest supported anisotropic filtering
+	// level. It is zero if anisotropic filtering is not supported.
+	MaxAnisotropy float32
+
+	// MaxSamples is the largest number of MSAA samples.
+	MaxSamples int
+
+	// MaxColorTextureSamples is the largest number of samples of a
+	// multisample color texture.
+	MaxColorTextureSamples int
+
+	// MaxDepthTextureSamples is the largest number of samples of a
+	// multisample depth texture.
+	MaxDepthTextureSamples int
+
+	// MaxPatchVertices is the largest number of vertices in a patch (see
+	// PipelineInfo.PatchVertices).
+	MaxPatchVertices int
+
+	// IndirectCount indicates that the number of indirect draws can be
+	// read from a buffer (see MultiDrawIndexedIndirectInfo.CountBuffer).
+	IndirectCount bool
+
+	// PipelineStatistics indicates that pipeline statistics queries
+	// (e.g. QueryKindVerticesSubmitted) are supported.
+	PipelineStatistics bool
+
+	// ParallelShaderCompile indicates that shaders can be compiled and
+	// programs linked in the background (see API.SetAsyncShaderCompile).
+	ParallelShaderCompile bool
+
+	// SPIRV indicates that shaders can be created from SPIR-V binaries
+	// (see API.CreateSPIRVShader).
+	SPIRV bool
+
+	// ProgramBinaryFormats is the number of binary formats in which
+	// linked programs can be retrieved and restored. Program caching
+	// is not possible when it is zero.
+	ProgramBinaryFormats int
+
+	// CompressedFormats holds the block-compressed formats (e.g.
+	// DataFormatBC7) that the driver supports. Textures with other
+	// block-compressed formats are decompressed to RGBA8 on the CPU.
+	CompressedFormats []render.DataFormat
+
+	// CompressedSRGBFormats holds the block-compressed formats that the
+	// driver supports with gamma correction. It is a subset of
+	// CompressedFormats.
+	CompressedSRGBFormats []render.DataFormat
+
+	// Extensions holds the names of all supported extensions, sorted
+	// alphabetically.
+	Extensions []string
+}
+
+// HasExtension returns whether the extension with the specified name
+// (e.g. "GL_KHR_parallel_shader_compile") is supported.
+func (c Capabilities) HasExtension(name string) bool {
+	_, found := slices.BinarySearch(c.Extensions, name)
+	return found
+}
+
+// SupportsCompressedFormat returns whether textures with the specified
+// block-compressed format and gamma correction setting are stored
+// compressed, without being decompressed on the CPU.
+func (c Capabilities) SupportsCompressedFormat(format render.DataFormat, gammaCorrection bool) bool {
+	if gammaCorrection {
+		return slices.Contains(c.CompressedSRGBFormats, format)
+	}
+	return slices.Contains(c.CompressedFormats, format)
+}
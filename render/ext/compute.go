@@ -0,0 +1,93 @@
+package ext
+
+import "github.com/mokiat/lacking/render"
+
+// ComputeProgramInfo contains the information needed to create a
+// compute program.
+type ComputeProgramInfo struct {
+
+	// ComputeShader is the shader that is executed for each invocation.
+	// It needs to be created through API.CreateComputeShader.
+	ComputeShader render.Shader
+}
+
+// ImageAccess specifies how a shader accesses an image.
+type ImageAccess int
+
+const (
+	// ImageAccessReadOnly indicates that the image is only read from.
+	ImageAccessReadOnly ImageAccess = iota
+
+	// ImageAccessWriteOnly indicates that the image is only written to.
+	ImageAccessWriteOnly
+
+	// ImageAccessReadWrite indicates that the image is both read from
+	// and written to.
+	ImageAccessReadWrite
+)
+
+// ImageUnitInfo describes how a texture is bound to an image unit for
+// load and store operations.
+type ImageUnitInfo struct {
+
+	// Level is the mipmap level of the texture that is bound.
+	Level int
+
+	// Layered specifies whether all layers (e.g. cube sides) of the
+	// texture are bound. If false, only Layer is bound.
+	Layered bool
+
+	// Layer is the layer that is bound when Layered is false.
+	Layer int
+
+	// Access specifies how the shader accesses the image.
+	Access ImageAccess
+
+	// Format is the format in which the shader accesses the image. It
+	// needs to match the format qualifier in the shader.
+	Format render.DataFormat
+}
+
+// Barrier specifies which kinds of memory accesses need to observe
+// writes made by shaders before a memory barrier.
+type Barrier uint32
+
+const (
+	// BarrierVertexAttribute applies to vertex data read from buffers.
+	BarrierVertexAttribute Barrier = 1 << iota
+
+	// BarrierIndex applies to index data read from buffers.
+	BarrierIndex
+
+	// BarrierUniform applies to uniform buffer reads.
+	BarrierUniform
+
+	// BarrierTextureFetch applies to texture sampling.
+	BarrierTextureFetch
+
+	// BarrierImageAccess applies to image load and store operations.
+	BarrierImageAccess
+
+	// BarrierCommand applies to indirect command parameters read from
+	// buffers.
+	BarrierCommand
+
+	// BarrierPixelBuffer applies to pixel transfers through buffers.
+	BarrierPixelBuffer
+
+	// BarrierTextureUpdate applies to texture uploads and downloads.
+	BarrierTextureUpdate
+
+	// BarrierBufferUpdate applies to buffer uploads, downloads and
+	// copies.
+	BarrierBufferUpdate
+
+	// BarrierFramebuffer applies to framebuffer reads and writes.
+	BarrierFramebuffer
+
+	// BarrierStorageBuffer applies to storage buffer accesses.
+	BarrierStorageBuffer
+
+	// BarrierAll applies to all kinds of memory accesses.
+	BarrierAll Barrier = (BarrierStorageBuffer << 1) - 1
+)
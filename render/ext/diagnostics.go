@@ -0,0 +1,182 @@
+package ext
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ShaderStage identifies the programmable stage of the pipeline that a
+// shader is meant for.
+type ShaderStage int
+
+const (
+	// ShaderStageUnknown is used for diagnostics that are not specific
+	// to a single stage (e.g. program link errors).
+	ShaderStageUnknown ShaderStage = iota
+
+	// ShaderStageVertex is the vertex shader stage.
+	ShaderStageVertex
+
+	// ShaderStageFragment is the fragment shader stage.
+	ShaderStageFragment
+
+	// ShaderStageCompute is the compute shader stage.
+	ShaderStageCompute
+
+	// ShaderStageGeometry is the geometry shader stage.
+	ShaderStageGeometry
+
+	// ShaderStageTessControl is the tessellation control shader stage.
+	ShaderStageTessControl
+
+	// ShaderStageTessEvaluation is the tessellation evaluation shader
+	// stage.
+	ShaderStageTessEvaluation
+)
+
+// String returns a human-readable name of the stage.
+func (s ShaderStage) String() string {
+	switch s {
+	case ShaderStageVertex:
+		return "vertex"
+	case ShaderStageFragment:
+		return "fragment"
+	case ShaderStageCompute:
+		return "compute"
+	case ShaderStageGeometry:
+		return "geometry"
+	case ShaderStageTessControl:
+		return "tessellation control"
+	case ShaderStageTessEvaluation:
+		return "tessellation evaluation"
+	default:
+		return "unknown"
+	}
+}
+
+// DiagnosticSeverity indicates how serious a diagnostic is.
+type DiagnosticSeverity int
+
+const (
+	// DiagnosticSeverityError indicates a problem that prevents the
+	// shader from being compiled or the program from being linked.
+	DiagnosticSeverityError DiagnosticSeverity = iota
+
+	// DiagnosticSeverityWarning indicates a potential problem that does
+	// not prevent compilation.
+	DiagnosticSeverityWarning
+)
+
+// String returns a human-readable name of the severity.
+func (s DiagnosticSeverity) String() string {
+	switch s {
+	case DiagnosticSeverityWarning:
+		return "warning"
+	default:
+		return "error"
+	}
+}
+
+// Diagnostic is a single message that was reported by the driver while
+// compiling a shader or linking a program.
+type Diagnostic struct {
+
+	// Stage is the stage of the shader that produced the diagnostic.
+	Stage ShaderStage
+
+	// Severity indicates whether this is an error or a warning.
+	Severity DiagnosticSeverity
+
+	// SourceString is the source string number, as declared by the
+	// #line directives of the source code. The directives are resolved
+	// by the API before the code is compiled, since drivers are not
+	// consistent in how they report source string numbers.
+	SourceString int
+
+	// File is the name of the file that SourceString refers to, or an
+	// empty string if it is unknown. Names are declared in the source
+	// code through comment lines of the form:
+	//
+	//	// source-string 3: pbr_geometry.frag.glsl
+	//
+	// The shader package emits such comments for its templates.
+	File string
+
+	// Line is the line within the source string, as declared by the
+	// #line directives of the source code, or zero if the driver did not
+	// report one.
+	Line int
+
+	// Column is the column within the line, or zero if the driver did
+	// not report one.
+	Column int
+
+	// Message describes the problem.
+	Message string
+}
+
+// String returns the diagnostic in a file:line:column format that is
+// understood by most editors.
+func (d Diagnostic) String() string {
+	var builder strings.Builder
+	if d.Line > 0 {
+		if d.File != "" {
+			builder.WriteString(d.File)
+		} else {
+			fmt.Fprintf(&builder, "source-string %d", d.SourceString)
+		}
+		fmt.Fprintf(&builder, ":%d", d.Line)
+		if d.Column > 0 {
+			fmt.Fprintf(&builder, ":%d", d.Column)
+		}
+		builder.WriteString(": ")
+	}
+	if d.Stage != ShaderStageUnknown {
+		fmt.Fprintf(&builder, "%s ", d.Stage)
+	}
+	fmt.Fprintf(&builder, "%s: %s", d.Severity, d.Message)
+	return builder.String()
+}
+
+// DiagnosticsHandler is a function that is called with the diagnostics
+// of a shader compilation or a program link. The err parameter is nil
+// if the operation succeeded with warnings only.
+type DiagnosticsHandler func(diagnostics []Diagnostic, err error)
+
+// ShaderError is returned when a shader fails to compile or a program
+// fails to link.
+type ShaderError struct {
+
+	// Link indicates that the error occurred while linking a program.
+	Link bool
+
+	// Diagnostics holds the parsed diagnostics, including warnings.
+	Diagnostics []Diagnostic
+
+	// Log is the raw info log that was reported by the driver.
+	Log string
+}
+
+// Error returns all error diagnostics, one per line. The raw log is
+// returned if it could not be parsed.
+func (e *ShaderError) Error() string {
+	var builder strings.Builder
+	if e.Link {
+		builder.WriteString("program link failed")
+	} else {
+		builder.WriteString("shader compilation failed")
+	}
+	var hasErrors bool
+	for _, diagnostic := range e.Diagnostics {
+		if diagnostic.Severity == DiagnosticSeverityError {
+			builder.WriteString("\n")
+			builder.WriteString(diagnostic.String())
+			hasErrors = true
+		}
+	}
+	if !hasErrors && e.Log != "" {
+		builder.WriteString(":\n")
+		builder.WriteString(strings.TrimSpace(e.Log))
+	}
+	return builder.String()
+}
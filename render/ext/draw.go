@@ -0,0 +1,85 @@
+package ext
+
+import "github.com/mokiat/lacking/render"
+
+// DrawIndirectCommand is the layout of a single non-indexed draw inside
+// an indirect buffer. It matches the DrawArraysIndirectCommand structure
+// of OpenGL and can be written as is by both Go code and shaders.
+type DrawIndirectCommand struct {
+
+	// VertexCount is the number of vertices to draw.
+	VertexCount uint32
+
+	// InstanceCount is the number of instances to draw.
+	InstanceCount uint32
+
+	// FirstVertex is the index of the first vertex to draw.
+	FirstVertex uint32
+
+	// BaseInstance is the instance index offset used when fetching
+	// instanced vertex attributes.
+	BaseInstance uint32
+}
+
+// DrawIndirectCommandSize is the size, in bytes, of a
+// DrawIndirectCommand inside an indirect buffer.
+const DrawIndirectCommandSize = 16
+
+// DrawIndexedIndirectCommand is the layout of a single indexed draw
+// inside an indirect buffer. It matches the DrawElementsIndirectCommand
+// structure of OpenGL and can be written as is by both Go code and
+// shaders.
+type DrawIndexedIndirectCommand struct {
+
+	// IndexCount is the number of indices to draw.
+	IndexCount uint32
+
+	// InstanceCount is the number of instances to draw.
+	InstanceCount uint32
+
+	// FirstIndex is the position of the first index to draw, counted in
+	// indices and not in bytes.
+	FirstIndex uint32
+
+	// BaseVertex is added to each index before a vertex is fetched.
+	BaseVertex int32
+
+	// BaseInstance is the instance index offset used when fetching
+	// instanced vertex attributes.
+	BaseInstance uint32
+}
+
+// DrawIndexedIndirectCommandSize is the size, in bytes, of a
+// DrawIndexedIndirectCommand inside an indirect buffer.
+const DrawIndexedIndirectCommandSize = 20
+
+// MultiDrawIndexedIndirectInfo describes a sequence of indexed draws whose
+// parameters are read from an indirect buffer.
+type MultiDrawIndexedIndirectInfo struct {
+
+	// Buffer is the indirect buffer that holds the draw parameters as
+	// DrawIndexedIndirectCommand entries.
+	Buffer render.Buffer
+
+	// Offset is the position, in bytes, of the first entry in Buffer.
+	Offset int
+
+	// DrawCount is the number of draws. When CountBuffer is specified,
+	// this is the maximum number of draws instead.
+	DrawCount int
+
+	// Stride is the distance, in bytes, between consecutive entries. A
+	// value of zero indicates that the entries are tightly packed.
+	Stride int
+
+	// CountBuffer is an optional buffer from which the actual number of
+	// draws is read as a single uint32 value. This allows the GPU (e.g.
+	// a culling compute shader) to decide how many draws are made.
+	//
+	// Using a count buffer requires Capabilities.IndirectCount.
+	CountBuffer render.Buffer
+
+	// CountOffset is the position, in bytes, of the draw count in
+	// CountBuffer. It needs to be a multiple of four.
+	CountOffset int
+}
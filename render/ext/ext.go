@@ -0,0 +1,253 @@
+// Package ext defines OpenGL-specific extensions to the lacking render API.
+//
+// The API that is created through this module's render package implements
+// the interfaces in this package in addition to render.API. Similarly,
+// command queues created through it implement CommandQueue. Wrappers of
+// render.API (e.g. the validation and capture ones) do not expose these
+// extensions.
+package ext
+
+import "github.com/mokiat/lacking/render"
+
+// API extends render.API with OpenGL-specific functionality.
+type API interface {
+	render.API
+	Commands
+
+	// ExtendedCapabilities returns detailed information about the
+	// OpenGL implementation.
+	ExtendedCapabilities() Capabilities
+
+	// CreateFramebufferWithAttachments creates a new framebuffer that
+	// renders into the specified layers and mipmap levels of textures.
+	CreateFramebufferWithAttachments(info FramebufferInfo) render.Framebuffer
+
+	// CreateExtendedPipeline creates a new pipeline with OpenGL-specific
+	// settings.
+	CreateExtendedPipeline(info PipelineInfo) render.Pipeline
+
+	// CreateColorTexture2DMultisample creates a new multisample color
+	// texture.
+	CreateColorTexture2DMultisample(info ColorTexture2DMultisampleInfo) render.Texture
+
+	// CreateDepthTexture2DMultisample creates a new multisample depth
+	// texture.
+	CreateDepthTexture2DMultisample(info DepthTexture2DMultisampleInfo) render.Texture
+
+	// CreateColorTexture2DArray creates a new 2D array texture.
+	CreateColorTexture2DArray(info ColorTexture2DArrayInfo) render.Texture
+
+	// CreateColorTexture3D creates a new 3D texture.
+	CreateColorTexture3D(info ColorTexture3DInfo) render.Texture
+
+	// CreateDepthTexture2DArray creates a new 2D array depth texture.
+	CreateDepthTexture2DArray(info DepthTexture2DArrayInfo) render.Texture
+
+	// CreateDepthTexture2DWithFormat creates a new depth texture with
+	// the specified precision, which needs to be one of DataFormatDepth16,
+	// DataFormatDepth24 and DataFormatDepth32F. Textures created through
+	// CreateDepthTexture2D use 32-bit unsigned normalized values.
+	CreateDepthTexture2DWithFormat(info render.DepthTexture2DInfo, format render.DataFormat) render.Texture
+
+	// CreateComputeShader creates a new compute shader.
+	CreateComputeShader(info render.ShaderInfo) render.Shader
+
+	// CreateGeometryShader creates a new geometry shader.
+	CreateGeometryShader(info render.ShaderInfo) render.Shader
+
+	// CreateTessControlShader creates a new tessellation control shader.
+	CreateTessControlShader(info render.ShaderInfo) render.Shader
+
+	// CreateTessEvaluationShader creates a new tessellation evaluation
+	// shader.
+	CreateTessEvaluationShader(info render.ShaderInfo) render.Shader
+
+	// CreateGraphicsProgram creates a new program that, in addition to
+	// vertex and fragment shaders, can have geometry and tessellation
+	// shaders.
+	CreateGraphicsProgram(info GraphicsProgramInfo) render.Program
+
+	// CreateSPIRVShader creates a new shader from a SPIR-V binary. All
+	// shaders of a program need to be either SPIR-V or GLSL ones.
+	//
+	// This requires Capabilities.SPIRV. Without it, or for an empty
+	// binary, the shader fails to compile (see ShaderErr).
+	CreateSPIRVShader(info SPIRVShaderInfo) render.Shader
+
+	// CreateComputeProgram creates a new program that consists of a
+	// single compute shader.
+	CreateComputeProgram(info ComputeProgramInfo) render.Program
+
+	// CreateStorageBuffer creates a new shader storage buffer. Storage
+	// buffers can also be used as the source of indirect dispatches.
+	CreateStorageBuffer(info render.BufferInfo) render.Buffer
+
+	// CreateIndirectBuffer creates a new buffer that holds the parameters
+	// of indirect draws. Indirect buffers can also be written to by
+	// compute shaders, when bound as storage buffers.
+	CreateIndirectBuffer(info render.BufferInfo) render.Buffer
+
+	// CreateQuery creates a new query object.
+	CreateQuery(info QueryInfo) Query
+
+	// CreateSampler creates a new sampler object.
+	CreateSampler(info SamplerInfo) Sampler
+
+	// SetDiagnosticsHandler specifies a function that is called with the
+	// diagnostics of each shader compilation and program link that
+	// reports any. When no handler is set, failures are logged.
+	SetDiagnosticsHandler(handler DiagnosticsHandler)
+
+	// ShaderErr returns a *ShaderError if the specified shader failed to
+	// compile and nil otherwise.
+	ShaderErr(shader render.Shader) error
+
+	// ProgramReflection returns the active uniforms, uniform blocks and
+	// vertex attributes of the specified program. This allows layouts to
+	// be validated at load time instead of relying on hard-coded names
+	// and binding indices.
+	ProgramReflection(program render.Program) ProgramReflection
+
+	// ProgramErr returns a *ShaderError if the specified program failed
+	// to link and nil otherwise.
+	ProgramErr(program render.Program) error
+
+	// SetProgramCache specifies a cache for linked program binaries. A
+	// nil value disables caching. It has no effect if the driver does
+	// not support any binary formats (see
+	// Capabilities.ProgramBinaryFormats).
+	//
+	// While a cache is set, shaders are only compiled when a program
+	// that uses them is not found in the cache. Their diagnostics are
+	// then reported when the program is created.
+	SetProgramCache(cache ProgramCache)
+
+	// SetAsyncShaderCompile specifies whether programs are compiled and
+	// linked in the background. It has no effect if the driver does not
+	// support it (see Capabilities.ParallelShaderCompile).
+	//
+	// While enabled, CreateProgram and CreateComputeProgram return
+	// immediately and ProgramStatus reports when a program is ready.
+	// Binding a program that is not ready blocks until it is, once the
+	// bind is executed: immediately or when its command queue is
+	// submitted. Recording the bind does not block. Diagnostics are
+	// reported once a program is found to be ready.
+	SetAsyncShaderCompile(enabled bool)
+
+	// ProgramStatus returns render.FenceStatusSuccess if the specified
+	// program is ready to be used and render.FenceStatusNotReady if it is
+	// still being compiled and linked in the background. A ready program
+	// may still have failed to link (see ProgramErr).
+	ProgramStatus(program render.Program) render.FenceStatus
+
+	// WaitProgram blocks until the specified program is ready to be used.
+	WaitProgram(program render.Program)
+
+	// ReloadPrograms rebuilds each live program for which the rewrite
+	// function returns new source code for any of its shaders. The
+	// function receives the current source code of a shader and returns
+	// the replacement and whether there is one.
+	//
+	// A program that builds successfully is replaced in place, so that
+	// existing pipelines use the new version. A program that fails keeps
+	// its last good version and the failure is reported through the
+	// diagnostics handler. Uniform locations that have been returned for
+	// the program (e.g. through UniformLocation or ProgramReflection)
+	// remain valid and refer to the uniforms with the same names in the
+	// new version. Locations that are derived from them (e.g. by adding
+	// an offset to the location of an array) are not translated.
+	ReloadPrograms(rewrite func(source string) (string, bool))
+}
+
+// CommandQueue extends render.CommandQueue with OpenGL-specific
+// commands.
+type CommandQueue interface {
+	render.CommandQueue
+	Commands
+}
+
+// Commands are the OpenGL-specific commands. They are available both
+// as immediate calls through API and as queued ones through CommandQueue.
+type Commands interface {
+
+	// BindComputeProgram makes the specified compute program current.
+	// Subsequent uniform calls apply to it.
+	BindComputeProgram(program render.Program)
+
+	// StorageBufferUnit binds the whole buffer to the specified storage
+	// buffer binding point.
+	StorageBufferUnit(index int, buffer render.Buffer)
+
+	// StorageBufferUnitRange binds a region of the buffer to the
+	// specified storage buffer binding point.
+	StorageBufferUnitRange(index int, buffer render.Buffer, offset, size int)
+
+	// ImageUnit binds a texture to the specified image unit, for load
+	// and store operations.
+	ImageUnit(index int, texture render.Texture, info ImageUnitInfo)
+
+	// Dispatch runs the current compute program with the specified
+	// number of work groups.
+	Dispatch(groupsX, groupsY, groupsZ int)
+
+	// DispatchIndirect runs the current compute program with the number
+	// of work groups read from the buffer at the specified offset, as
+	// three consecutive uint32 values.
+	DispatchIndirect(buffer render.Buffer, offset int)
+
+	// DrawIndirect draws primitives using the current pipeline, with the
+	// parameters read from the buffer at the specified offset as a
+	// DrawIndirectCommand.
+	DrawIndirect(buffer render.Buffer, offset int)
+
+	// DrawIndexedIndirect draws indexed primitives using the current
+	// pipeline, with the parameters read from the buffer at the specified
+	// offset as a DrawIndexedIndirectCommand.
+	DrawIndexedIndirect(buffer render.Buffer, offset int)
+
+	// MultiDrawIndexedIndirect makes a sequence of indexed draws using the
+	// current pipeline, with the parameters of each draw read from an
+	// indirect buffer.
+	MultiDrawIndexedIndirect(info MultiDrawIndexedIndirectInfo)
+
+	// MemoryBarrier makes sure that writes made by shaders before the
+	// barrier are visible to the specified kinds of accesses after it.
+	MemoryBarrier(barrier Barrier)
+
+	// WriteTimestamp records the GPU time at which all preceding
+	// commands have completed into the specified timestamp query.
+	WriteTimestamp(query Query)
+
+	// BeginQuery starts measuring the subsequent commands with the
+	// specified query. It cannot be used with timestamp queries.
+	BeginQuery(query Query)
+
+	// EndQuery stops the measurement of the specified query, which
+	// needs to be the active query of its kind.
+	EndQuery(query Query)
+
+	// UpdateTextureData replaces a region of one level of the specified
+	// texture with the specified data.
+	UpdateTextureData(texture render.Texture, info TextureUpdateInfo)
+
+	// GenerateTextureMipmaps regenerates all levels of the specified
+	// texture from its first level.
+	GenerateTextureMipmaps(texture render.Texture)
+
+	// CopyTextureToBuffer copies a region of one level of the specified
+	// texture into a pixel transfer buffer.
+	CopyTextureToBuffer(texture render.Texture, info TextureCopyToBufferInfo)
+
+	// SamplerUnit binds a sampler to the specified texture unit, so that
+	// the texture that is bound to the same unit is sampled with its
+	// parameters. A nil sampler restores the parameters of the texture.
+	//
+	// Samplers are unbound from all units when the render pass ends and
+	// when the renderer is invalidated.
+	SamplerUnit(index int, sampler Sampler)
+
+	// BlitFramebuffer copies a region of one framebuffer into another
+	// one, which also resolves multisample framebuffers. It needs to be
+	// used outside of render passes.
+	BlitFramebuffer(info BlitFramebufferInfo)
+}
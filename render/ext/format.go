@@ -0,0 +1,117 @@
+package ext
+
+import "github.com/mokiat/lacking/render"
+
+// Data formats in addition to the ones that are defined by the render
+// package. They can be used wherever the API accepts a render.DataFormat.
+//
+// The block-compressed formats can only be used with
+// render.API.CreateColorTexture2D and Texture.Update. Mipmaps are not
+// generated for them and need to be provided level by level.
+const (
+	// DataFormatR8 is a single unsigned normalized 8-bit channel.
+	DataFormatR8 render.DataFormat = 0x100 + iota
+
+	// DataFormatRG8 is two unsigned normalized 8-bit channels.
+	DataFormatRG8
+
+	// DataFormatR16F is a single 16-bit floating point channel.
+	DataFormatR16F
+
+	// DataFormatRG16F is two 16-bit floating point channels.
+	DataFormatRG16F
+
+	// DataFormatR32F is a single 32-bit floating point channel.
+	DataFormatR32F
+
+	// DataFormatR11G11B10F is three unsigned floating point channels
+	// that are packed into 32 bits. It has no alpha channel and is
+	// usually used for HDR color.
+	DataFormatR11G11B10F
+
+	// DataFormatRGB10A2 is three unsigned normalized 10-bit channels and
+	// a 2-bit alpha channel, packed into 32 bits.
+	DataFormatRGB10A2
+
+	// DataFormatR32UI is a single unsigned 32-bit integer channel (e.g.
+	// for object IDs). Shaders access it through usampler and uimage
+	// types and it can only be sampled with nearest filtering.
+	DataFormatR32UI
+
+	// DataFormatDepth16 is a 16-bit unsigned normalized depth value.
+	DataFormatDepth16
+
+	// DataFormatDepth24 is a 24-bit unsigned normalized depth value. Its
+	// data is transferred as 32-bit unsigned integers.
+	DataFormatDepth24
+
+	// DataFormatDepth32F is a 32-bit floating point depth value.
+	DataFormatDepth32F
+
+	// DataFormatBC1 is the BC1 (DXT1) block-compressed format, with RGB
+	// color and optional 1-bit alpha.
+	DataFormatBC1
+
+	// DataFormatBC3 is the BC3 (DXT5) block-compressed format, with RGB
+	// color and interpolated alpha.
+	DataFormatBC3
+
+	// DataFormatBC4 is the BC4 block-compressed format, with a single
+	// unsigned normalized channel.
+	DataFormatBC4
+
+	// DataFormatBC5 is the BC5 block-compressed format, with two
+	// unsigned normalized channels (e.g. for normal maps).
+	DataFormatBC5
+
+	// DataFormatBC7 is the BC7 block-compressed format, with high
+	// quality RGBA color.
+	DataFormatBC7
+
+	// DataFormatETC2RGB8 is the ETC2 block-compressed format, with RGB
+	// color.
+	DataFormatETC2RGB8
+
+	// DataFormatETC2RGBA8 is the ETC2 block-compressed format, with RGB
+	// color and EAC-compressed alpha.
+	DataFormatETC2RGBA8
+)
+
+// PixelSize returns the size, in bytes, of a single pixel of the specified
+// format when it is transferred to or from a texture. It returns zero for
+// unknown and block-compressed formats.
+func PixelSize(format render.DataFormat) int {
+	switch format {
+	case DataFormatR8:
+		return 1
+	case DataFormatRG8, DataFormatR16F, DataFormatDepth16:
+		return 2
+	case render.DataFormatRGBA8, DataFormatRG16F, DataFormatR32F, DataFormatR11G11B10F,
+		DataFormatRGB10A2, DataFormatR32UI, DataFormatDepth24, DataFormatDepth32F:
+		return 4
+	case render.DataFormatRGBA16F:
+		return 8
+	case render.DataFormatRGBA32F:
+		return 16
+	default:
+		return 0
+	}
+}
+
+// BlockSize returns the size, in bytes, of a single 4x4 block of the
+// specified block-compressed format. It returns zero for formats that
+// are not block-compressed.
+//
+// Block-compressed data holds the rows of blocks one after the other,
+// where partial blocks at the right and top edges of an image are still
+// stored as whole ones.
+func BlockSize(format render.DataFormat) int {
+	switch format {
+	case DataFormatBC1, DataFormatBC4, DataFormatETC2RGB8:
+		return 8
+	case DataFormatBC3, DataFormatBC5, DataFormatBC7, DataFormatETC2RGBA8:
+		return 16
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,44 @@
+package ext
+
+import "github.com/mokiat/lacking/render"
+
+// FramebufferAttachment selects the part of a texture that a framebuffer
+// renders into.
+type FramebufferAttachment struct {
+
+	// Texture is the attached texture. If nil, there is no attachment.
+	Texture render.Texture
+
+	// Level is the mipmap level that is rendered into.
+	Level int
+
+	// Layer is the layer that is rendered into. It is the cube side, the
+	// array layer or the 3D texture slice. It is ignored for 2D textures
+	// and when Layered is set.
+	Layer int
+
+	// Layered specifies whether all layers are attached, in which case a
+	// geometry shader selects the layer of each primitive through
+	// gl_Layer. This is how render.API.CreateFramebuffer attaches cube,
+	// array and 3D textures.
+	Layered bool
+}
+
+// FramebufferInfo contains the information needed to create a framebuffer
+// that renders into individual layers or mipmap levels of textures (e.g.
+// one shadow cascade of a depth 2D array).
+type FramebufferInfo struct {
+
+	// ColorAttachments are the color attachments, in draw buffer order.
+	ColorAttachments [4]FramebufferAttachment
+
+	// DepthAttachment is the depth attachment.
+	DepthAttachment FramebufferAttachment
+
+	// StencilAttachment is the stencil attachment.
+	StencilAttachment FramebufferAttachment
+
+	// DepthStencilAttachment is the combined depth and stencil attachment.
+	// If specified, DepthAttachment and StencilAttachment are ignored.
+	DepthStencilAttachment FramebufferAttachment
+}
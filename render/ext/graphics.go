@@ -0,0 +1,58 @@
+package ext
+
+import "github.com/mokiat/lacking/render"
+
+// GraphicsProgramInfo contains the information needed to create a
+// program that runs as part of a pipeline, with optional geometry and
+// tessellation stages.
+type GraphicsProgramInfo struct {
+
+	// VertexShader is the shader that is executed for each vertex.
+	VertexShader render.Shader
+
+	// TessControlShader is an optional shader that is executed for each
+	// vertex of a patch and that controls how much the patch is
+	// tessellated. It needs to be created through
+	// API.CreateTessControlShader.
+	TessControlShader render.Shader
+
+	// TessEvaluationShader is an optional shader that is executed for
+	// each vertex that is generated by tessellation. It needs to be
+	// created through API.CreateTessEvaluationShader and is required
+	// for patches to be tessellated.
+	TessEvaluationShader render.Shader
+
+	// GeometryShader is an optional shader that is executed for each
+	// primitive and that can emit any number of primitives (e.g. to
+	// multiple layers). It needs to be created through
+	// API.CreateGeometryShader.
+	GeometryShader render.Shader
+
+	// FragmentShader is the shader that is executed for each fragment.
+	FragmentShader render.Shader
+}
+
+// PipelineInfo extends render.PipelineInfo with OpenGL-specific
+// settings.
+type PipelineInfo struct {
+	render.PipelineInfo
+
+	// AlphaToCoverage specifies whether the alpha of the first color
+	// output of the fragment shader determines which samples of a pixel
+	// are covered. When rendering into multisample targets, this gives
+	// alpha-tested geometry (e.g. foliage) smooth edges without sorting.
+	AlphaToCoverage bool
+
+	// PatchVertices is the number of vertices of each patch when the
+	// Topology of the embedded render.PipelineInfo is TopologyPatches. It
+	// needs to be between one and Capabilities.MaxPatchVertices then and
+	// is ignored otherwise.
+	PatchVertices int
+}
+
+// TopologyPatches is a topology in which every group of
+// PipelineInfo.PatchVertices vertices forms a patch, to be processed by
+// tessellation shaders. Pipelines with it need to be created through
+// API.CreateExtendedPipeline. Its value lies beyond the topologies of the
+// render package, so that it does not clash with them.
+const TopologyPatches render.Topology = 1 << 16
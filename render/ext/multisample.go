@@ -0,0 +1,89 @@
+package ext
+
+import "github.com/mokiat/lacking/render"
+
+// ColorTexture2DMultisampleInfo contains the information needed to create
+// a multisample color texture.
+//
+// Multisample textures can only be rendered into, through framebuffers,
+// and read by shaders one sample at a time, through sampler2DMS. They are
+// usually resolved into single-sample textures through
+// Commands.BlitFramebuffer.
+type ColorTexture2DMultisampleInfo struct {
+
+	// Width is the width of the texture.
+	Width int
+
+	// Height is the height of the texture.
+	Height int
+
+	// Samples is the number of samples per pixel. It needs to be at most
+	// Capabilities.MaxColorTextureSamples.
+	Samples int
+
+	// GammaCorrection specifies whether the data is in sRGB color space.
+	GammaCorrection bool
+
+	// Format is the format of the texture. Block-compressed formats are
+	// not supported.
+	Format render.DataFormat
+}
+
+// DepthTexture2DMultisampleInfo contains the information needed to create
+// a multisample depth texture, to be used together with multisample color
+// textures.
+type DepthTexture2DMultisampleInfo struct {
+
+	// Width is the width of the texture.
+	Width int
+
+	// Height is the height of the texture.
+	Height int
+
+	// Samples is the number of samples per pixel. It needs to be at most
+	// Capabilities.MaxDepthTextureSamples.
+	Samples int
+
+	// Format is the precision of the depth values, which is one of
+	// DataFormatDepth16, DataFormatDepth24 and DataFormatDepth32F. If not
+	// specified, 32-bit unsigned normalized values are used.
+	Format render.DataFormat
+}
+
+// BlitFramebufferInfo describes a copy of a region of one framebuffer into
+// a region of another one.
+//
+// When the source is multisample and the destination is not, the samples
+// of each pixel are resolved (i.e. averaged for color) into a single
+// value. Resolving requires both regions to have the same size.
+type BlitFramebufferInfo struct {
+
+	// Source is the framebuffer that is read. Color is read from its
+	// first color attachment.
+	Source render.Framebuffer
+
+	// SourceArea is the region of the source that is read.
+	SourceArea render.Area
+
+	// Destination is the framebuffer that is written. Color is written to
+	// all of its color attachments.
+	Destination render.Framebuffer
+
+	// DestinationArea is the region of the destination that is written.
+	// If it has a zero size, SourceArea is used.
+	DestinationArea render.Area
+
+	// Color specifies whether color is copied.
+	Color bool
+
+	// Depth specifies whether depth is copied.
+	Depth bool
+
+	// Stencil specifies whether stencil is copied.
+	Stencil bool
+
+	// Filtering specifies how color is filtered when the regions have
+	// different sizes. Depth and stencil can only be copied with
+	// render.FilterModeNearest.
+	Filtering render.FilterMode
+}
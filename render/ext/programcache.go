@@ -0,0 +1,22 @@
+package ext
+
+// ProgramCache stores linked program binaries, so that programs do not
+// need to be compiled again on subsequent runs.
+//
+// Keys are derived from the shader source code and the OpenGL driver
+// (vendor, renderer and version), so a driver update invalidates all
+// earlier entries. The stored data is opaque to the cache.
+type ProgramCache interface {
+
+	// Load returns the data that is stored under the specified key and
+	// whether there was any.
+	Load(key string) ([]byte, bool)
+
+	// Store saves the data under the specified key, replacing any data
+	// that was stored earlier.
+	Store(key string, data []byte)
+
+	// Remove deletes the data that is stored under the specified key.
+	// It is called when the driver rejects a cached binary.
+	Remove(key string)
+}
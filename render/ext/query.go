@@ -0,0 +1,89 @@
+package ext
+
+// QueryKind specifies what a query measures.
+type QueryKind int
+
+const (
+	// QueryKindTimestamp records the GPU time, in nanoseconds, at which
+	// all preceding commands have completed. The time is measured from an
+	// arbitrary point, so only differences between timestamps are
+	// meaningful.
+	QueryKindTimestamp QueryKind = iota
+
+	// QueryKindTimeElapsed measures the GPU time, in nanoseconds, that
+	// is spent on the commands between BeginQuery and EndQuery.
+	QueryKindTimeElapsed
+
+	// QueryKindAnySamplesPassed measures whether any samples passed the
+	// depth and stencil tests between BeginQuery and EndQuery. The
+	// result is one if they did and zero otherwise. This is the usual
+	// kind for occlusion culling.
+	QueryKindAnySamplesPassed
+
+	// QueryKindSamplesPassed counts the samples that passed the depth and
+	// stencil tests between BeginQuery and EndQuery.
+	QueryKindSamplesPassed
+
+	// QueryKindVerticesSubmitted counts the vertices that were submitted
+	// by draws between BeginQuery and EndQuery.
+	//
+	// Pipeline statistics queries require
+	// Capabilities.PipelineStatistics.
+	QueryKindVerticesSubmitted
+
+	// QueryKindPrimitivesSubmitted counts the primitives that were
+	// submitted by draws between BeginQuery and EndQuery.
+	//
+	// Pipeline statistics queries require
+	// Capabilities.PipelineStatistics.
+	QueryKindPrimitivesSubmitted
+
+	// QueryKindFragmentShaderInvocations counts the invocations of
+	// fragment shaders between BeginQuery and EndQuery.
+	//
+	// Pipeline statistics queries require
+	// Capabilities.PipelineStatistics.
+	QueryKindFragmentShaderInvocations
+)
+
+// QueryInfo contains the information needed to create a query.
+type QueryInfo struct {
+
+	// Kind specifies what the query measures.
+	Kind QueryKind
+}
+
+// Query holds a value that is measured by the GPU.
+//
+// Timestamp queries are issued through WriteTimestamp, while all other
+// kinds measure the commands between BeginQuery and EndQuery. Only one
+// query of a given kind can be active at a time, where
+// QueryKindAnySamplesPassed and QueryKindSamplesPassed count as the same
+// kind.
+//
+// Results become available asynchronously, usually a few frames after
+// the query was issued. Polling avoids stalling the CPU until the GPU
+// catches up.
+type Query interface {
+
+	// Kind returns what the query measures.
+	Kind() QueryKind
+
+	// Available returns whether the result of the last issued
+	// measurement can be read without blocking. It returns false if the
+	// query has never been issued and while the commands of the last
+	// issued measurement have not been executed to its end (e.g. while
+	// they are recorded in a command queue that has not been submitted).
+	Available() bool
+
+	// Result returns the measured value. It blocks until the result is
+	// available.
+	Result() uint64
+
+	// Poll returns the measured value if it is available. Otherwise, it
+	// returns false without blocking.
+	Poll() (uint64, bool)
+
+	// Release deletes the query.
+	Release()
+}
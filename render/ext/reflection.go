@@ -0,0 +1,256 @@
+package ext
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ProgramReflection describes the active interface of a linked program,
+// as reported by the driver. Variables that the driver optimized away
+// are not included.
+type ProgramReflection struct {
+
+	// Uniforms holds the uniforms that are not part of a uniform block,
+	// including samplers and images.
+	Uniforms []UniformInfo
+
+	// UniformBlocks holds the uniform blocks.
+	UniformBlocks []UniformBlockInfo
+
+	// Attributes holds the vertex attributes. Built-in inputs (e.g.
+	// gl_VertexID) are not included.
+	Attributes []AttributeInfo
+}
+
+// Uniform returns the uniform with the specified name and whether it was
+// found.
+func (r ProgramReflection) Uniform(name string) (UniformInfo, bool) {
+	for _, uniform := range r.Uniforms {
+		if uniform.Name == name {
+			return uniform, true
+		}
+	}
+	return UniformInfo{}, false
+}
+
+// UniformBlock returns the uniform block with the specified name and
+// whether it was found.
+func (r ProgramReflection) UniformBlock(name string) (UniformBlockInfo, bool) {
+	for _, block := range r.UniformBlocks {
+		if block.Name == name {
+			return block, true
+		}
+	}
+	return UniformBlockInfo{}, false
+}
+
+// UniformInfo describes a uniform that is not part of a uniform block.
+type UniformInfo struct {
+
+	// Name is the name of the uniform. Arrays are named after their
+	// first element (e.g. "lights[0]").
+	Name string
+
+	// Type is the data type of the uniform.
+	Type VariableType
+
+	// ArraySize is the number of array elements, or one if the uniform
+	// is not an array.
+	ArraySize int
+
+	// Location is the location that is to be used with the uniform
+	// commands.
+	Location int
+
+	// Binding is the texture unit of a sampler uniform or the image unit
+	// of an image uniform, as set through the binding layout qualifier.
+	// It is -1 for other uniforms.
+	Binding int
+}
+
+// UniformBlockInfo describes a uniform block.
+type UniformBlockInfo struct {
+
+	// Name is the name of the block, which is the name that follows the
+	// uniform keyword in GLSL.
+	Name string
+
+	// Binding is the uniform buffer binding point of the block.
+	Binding int
+
+	// Size is the minimum size, in bytes, of a buffer that backs the
+	// block.
+	Size int
+
+	// Members holds the variables of the block, sorted by offset.
+	Members []BlockMemberInfo
+}
+
+// BlockMemberInfo describes a variable inside a uniform block.
+type BlockMemberInfo struct {
+
+	// Name is the name of the variable. Members of blocks with an
+	// instance name are still named without it, while members of nested
+	// structures include the structure path (e.g. "light.color").
+	Name string
+
+	// Type is the data type of the variable.
+	Type VariableType
+
+	// ArraySize is the number of array elements, or one if the variable
+	// is not an array.
+	ArraySize int
+
+	// Offset is the position, in bytes, of the variable from the start
+	// of the block.
+	Offset int
+
+	// ArrayStride is the distance, in bytes, between consecutive array
+	// elements, or zero if the variable is not an array.
+	ArrayStride int
+
+	// MatrixStride is the distance, in bytes, between consecutive
+	// columns of a matrix, or zero if the variable is not a matrix.
+	MatrixStride int
+}
+
+// AttributeInfo describes a vertex attribute.
+type AttributeInfo struct {
+
+	// Name is the name of the attribute.
+	Name string
+
+	// Type is the data type of the attribute.
+	Type VariableType
+
+	// ArraySize is the number of array elements, or one if the attribute
+	// is not an array.
+	ArraySize int
+
+	// Location is the location of the attribute, as used by
+	// render.VertexArrayAttributeInfo.
+	Location int
+}
+
+// VariableType is the data type of a shader variable. Its value is the
+// OpenGL type constant (e.g. GL_FLOAT_VEC4).
+type VariableType uint32
+
+// String returns the GLSL name of the type (e.g. "vec4").
+func (t VariableType) String() string {
+	if name, ok := variableTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("type(0x%04X)", uint32(t))
+}
+
+// IsSampler returns whether the type is a sampler type.
+func (t VariableType) IsSampler() bool {
+	name := t.String()
+	return strings.HasPrefix(name, "sampler") ||
+		strings.HasPrefix(name, "isampler") ||
+		strings.HasPrefix(name, "usampler")
+}
+
+// IsImage returns whether the type is an image type.
+func (t VariableType) IsImage() bool {
+	name := t.String()
+	return strings.HasPrefix(name, "image") ||
+		strings.HasPrefix(name, "iimage") ||
+		strings.HasPrefix(name, "uimage")
+}
+
+var variableTypeNames = map[VariableType]string{
+	0x1406: "float",
+	0x8B50: "vec2",
+	0x8B51: "vec3",
+	0x8B52: "vec4",
+	0x140A: "double",
+	0x8FFC: "dvec2",
+	0x8FFD: "dvec3",
+	0x8FFE: "dvec4",
+	0x1404: "int",
+	0x8B53: "ivec2",
+	0x8B54: "ivec3",
+	0x8B55: "ivec4",
+	0x1405: "uint",
+	0x8DC6: "uvec2",
+	0x8DC7: "uvec3",
+	0x8DC8: "uvec4",
+	0x8B56: "bool",
+	0x8B57: "bvec2",
+	0x8B58: "bvec3",
+	0x8B59: "bvec4",
+	0x8B5A: "mat2",
+	0x8B5B: "mat3",
+	0x8B5C: "mat4",
+	0x8B65: "mat2x3",
+	0x8B66: "mat2x4",
+	0x8B67: "mat3x2",
+	0x8B68: "mat3x4",
+	0x8B69: "mat4x2",
+	0x8B6A: "mat4x3",
+	0x8F46: "dmat2",
+	0x8F47: "dmat3",
+	0x8F48: "dmat4",
+	0x8B5D: "sampler1D",
+	0x8B5E: "sampler2D",
+	0x8B5F: "sampler3D",
+	0x8B60: "samplerCube",
+	0x8B61: "sampler1DShadow",
+	0x8B62: "sampler2DShadow",
+	0x8DC0: "sampler1DArray",
+	0x8DC1: "sampler2DArray",
+	0x8DC3: "sampler1DArrayShadow",
+	0x8DC4: "sampler2DArrayShadow",
+	0x8DC5: "samplerCubeShadow",
+	0x9108: "sampler2DMS",
+	0x910B: "sampler2DMSArray",
+	0x900C: "samplerCubeArray",
+	0x900D: "samplerCubeArrayShadow",
+	0x8B63: "sampler2DRect",
+	0x8B64: "sampler2DRectShadow",
+	0x8DC2: "samplerBuffer",
+	0x8DC9: "isampler1D",
+	0x8DCA: "isampler2D",
+	0x8DCB: "isampler3D",
+	0x8DCC: "isamplerCube",
+	0x8DCE: "isampler1DArray",
+	0x8DCF: "isampler2DArray",
+	0x9109: "isampler2DMS",
+	0x910C: "isampler2DMSArray",
+	0x900E: "isamplerCubeArray",
+	0x8DD0: "isamplerBuffer",
+	0x8DD1: "usampler1D",
+	0x8DD2: "usampler2D",
+	0x8DD3: "usampler3D",
+	0x8DD4: "usamplerCube",
+	0x8DD6: "usampler1DArray",
+	0x8DD7: "usampler2DArray",
+	0x910A: "usampler2DMS",
+	0x910D: "usampler2DMSArray",
+	0x900F: "usamplerCubeArray",
+	0x8DD8: "usamplerBuffer",
+	0x904C: "image1D",
+	0x904D: "image2D",
+	0x904E: "image3D",
+	0x9050: "imageCube",
+	0x9052: "image1DArray",
+	0x9053: "image2DArray",
+	0x9051: "imageBuffer",
+	0x9057: "iimage1D",
+	0x9058: "iimage2D",
+	0x9059: "iimage3D",
+	0x905B: "iimageCube",
+	0x905D: "iimage1DArray",
+	0x905E: "iimage2DArray",
+	0x905C: "iimageBuffer",
+	0x9062: "uimage1D",
+	0x9063: "uimage2D",
+	0x9064: "uimage3D",
+	0x9066: "uimageCube",
+	0x9068: "uimage1DArray",
+	0x9069: "uimage2DArray",
+	0x9067: "uimageBuffer",
+	0x92DB: "atomic_uint",
+}
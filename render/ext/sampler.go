@@ -0,0 +1,60 @@
+package ext
+
+import "github.com/mokiat/lacking/render"
+
+// SamplerInfo contains the information needed to create a sampler.
+type SamplerInfo struct {
+
+	// Wrapping specifies how coordinates outside of the texture are
+	// handled, along all axes. It is ignored if BorderColor is specified.
+	Wrapping render.WrapMode
+
+	// Filtering specifies how the texture is filtered.
+	Filtering render.FilterMode
+
+	// Mipmapping specifies whether the mipmap levels of the texture are
+	// used when it is minified.
+	Mipmapping bool
+
+	// MaxAnisotropy is the anisotropic filtering level that is used with
+	// render.FilterModeAnisotropic. If zero, the largest supported level
+	// is used (see Capabilities.MaxAnisotropy).
+	MaxAnisotropy float32
+
+	// LODBias is added to the level of detail that is computed for each
+	// sample, before it is clamped to MinLOD and MaxLOD.
+	LODBias float32
+
+	// MinLOD, if specified, is the lowest level of detail (i.e. the most
+	// detailed level) that is sampled.
+	MinLOD *float32
+
+	// MaxLOD, if specified, is the highest level of detail (i.e. the least
+	// detailed level) that is sampled.
+	MaxLOD *float32
+
+	// BorderColor, if specified, is the value that is returned for
+	// coordinates outside of the texture, instead of them being wrapped.
+	BorderColor *[4]float32
+
+	// Comparison, if specified, makes depth textures be sampled by
+	// comparing the reference value of the lookup against the stored
+	// depth, with the result being one if the comparison passes and zero
+	// otherwise. Shaders then need to use shadow sampler types.
+	Comparison *render.Comparison
+}
+
+// Sampler specifies how textures are sampled, independently of the
+// textures themselves.
+//
+// While a sampler is bound to a texture unit (see Commands.SamplerUnit),
+// its parameters are used instead of the ones that the texture bound to
+// the same unit was created with. This allows a single texture to be
+// sampled in multiple ways (e.g. a shadow map both with and without
+// comparison). Samplers remain bound until they are unbound, the render
+// pass ends or the renderer is invalidated.
+type Sampler interface {
+
+	// Release deletes the sampler.
+	Release()
+}
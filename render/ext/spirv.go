@@ -0,0 +1,39 @@
+package ext
+
+// SPIRVShaderInfo describes a shader that is created from a precompiled
+// SPIR-V binary instead of GLSL source code.
+//
+// SPIR-V binaries can also be passed as the SourceCode of a
+// render.ShaderInfo, converted to a string. They are recognized by their
+// magic number and are specialized with the main entry point and no
+// specialization constants.
+type SPIRVShaderInfo struct {
+
+	// Stage is the stage that the shader is meant for.
+	Stage ShaderStage
+
+	// Binary is the SPIR-V module.
+	Binary []byte
+
+	// EntryPoint is the name of the function that the shader starts
+	// from. Defaults to "main".
+	EntryPoint string
+
+	// Constants are the values of the specialization constants of the
+	// module. Constants that are not specified keep their default values.
+	Constants []SpecializationConstant
+}
+
+// SpecializationConstant is the value of a single specialization constant
+// of a SPIR-V module.
+type SpecializationConstant struct {
+
+	// ID is the SpecId of the constant, as declared in GLSL through
+	// `layout(constant_id = 3) const ...`.
+	ID uint32
+
+	// Value is the raw 32-bit value of the constant. Floating point
+	// values need to be converted through math.Float32bits and booleans
+	// are represented by zero and one.
+	Value uint32
+}
@@ -0,0 +1,210 @@
+package ext
+
+import "github.com/mokiat/lacking/render"
+
+// Texture extends render.Texture with operations that change or read
+// back its content after it has been created. Textures created through
+// API implement it.
+//
+// The methods take effect immediately. The Commands with the same purpose
+// can be used to order them with respect to other commands.
+type Texture interface {
+	render.Texture
+
+	// Update replaces a region of one level of the texture with the
+	// specified data.
+	Update(info TextureUpdateInfo)
+
+	// GenerateMipmaps regenerates all levels of the texture from its
+	// first level. It has no effect on textures that were created
+	// without mipmapping.
+	GenerateMipmaps()
+
+	// CopyToBuffer copies a region of one level of the texture into a
+	// pixel transfer buffer. The buffer can be fetched once the copy
+	// has completed, which can be tracked through a fence.
+	CopyToBuffer(info TextureCopyToBufferInfo)
+}
+
+// TextureUpdateInfo describes a region of a texture that is replaced.
+type TextureUpdateInfo struct {
+
+	// Level is the mipmap level that is updated.
+	Level int
+
+	// Layer is the first layer that is updated. It is the cube side, in
+	// the order right, left, bottom, top, front and back, the array layer
+	// or the 3D texture slice. It is ignored for 2D textures.
+	Layer int
+
+	// Layers is the number of consecutive layers that are updated. Zero
+	// is treated as one. It is ignored for 2D textures.
+	Layers int
+
+	// X is the left edge of the region.
+	X int
+
+	// Y is the bottom edge of the region.
+	Y int
+
+	// Width is the width of the region. If zero, the region extends to
+	// the right edge of the level.
+	Width int
+
+	// Height is the height of the region. If zero, the region extends to
+	// the top edge of the level.
+	Height int
+
+	// Format is the format of Data. It does not need to match the
+	// format of the texture, in which case the data is converted.
+	//
+	// Block-compressed data needs to match the format of a compressed
+	// texture and the region needs to consist of whole blocks, except at
+	// the edges of the level. Such data is decompressed when the texture
+	// is not stored compressed (see Capabilities.CompressedFormats).
+	Format render.DataFormat
+
+	// Data holds the rows of the region, starting from the bottom one,
+	// with no padding between them. Multiple layers follow one another.
+	// It needs to hold the whole region, otherwise the update panics.
+	Data []byte
+}
+
+// TextureCopyToBufferInfo describes a region of a texture that is copied
+// into a pixel transfer buffer.
+type TextureCopyToBufferInfo struct {
+
+	// Buffer is the pixel transfer buffer that receives the data. It
+	// needs to be created through render.API.CreatePixelTransferBuffer.
+	Buffer render.Buffer
+
+	// Offset is the position, in bytes, in the buffer at which the data
+	// is written.
+	Offset int
+
+	// Level is the mipmap level that is copied.
+	Level int
+
+	// Layer is the first layer that is copied. It is the cube side, in
+	// the order right, left, bottom, top, front and back, the array layer
+	// or the 3D texture slice. It is ignored for 2D textures.
+	Layer int
+
+	// Layers is the number of consecutive layers that are copied. Zero
+	// is treated as one. It is ignored for 2D textures.
+	Layers int
+
+	// X is the left edge of the region.
+	X int
+
+	// Y is the bottom edge of the region.
+	Y int
+
+	// Width is the width of the region. If zero, the region extends to
+	// the right edge of the level.
+	Width int
+
+	// Height is the height of the region. If zero, the region extends to
+	// the top edge of the level.
+	Height int
+
+	// Format is the format in which the data is written to the buffer.
+	Format render.DataFormat
+}
+
+// ColorTexture2DArrayInfo contains the information needed to create a
+// 2D array texture, which is a sequence of 2D layers of the same size.
+// Shaders sample it through sampler2DArray.
+type ColorTexture2DArrayInfo struct {
+
+	// Width is the width of each layer.
+	Width int
+
+	// Height is the height of each layer.
+	Height int
+
+	// Layers is the number of layers.
+	Layers int
+
+	// Wrapping specifies how coordinates outside of a layer are handled.
+	Wrapping render.WrapMode
+
+	// Filtering specifies how the texture is filtered.
+	Filtering render.FilterMode
+
+	// Mipmapping specifies whether the texture has mipmap levels.
+	Mipmapping bool
+
+	// GammaCorrection specifies whether the data is in sRGB color space.
+	GammaCorrection bool
+
+	// Format is the format of the texture and of Data.
+	Format render.DataFormat
+
+	// Data holds the optional initial content of all layers, one after
+	// the other.
+	Data []byte
+}
+
+// ColorTexture3DInfo contains the information needed to create a 3D
+// texture (e.g. a color grading lookup table). Shaders sample it through
+// sampler3D.
+type ColorTexture3DInfo struct {
+
+	// Width is the width of the texture.
+	Width int
+
+	// Height is the height of the texture.
+	Height int
+
+	// Depth is the number of slices of the texture.
+	Depth int
+
+	// Wrapping specifies how coordinates outside of the texture are
+	// handled, along all three axes.
+	Wrapping render.WrapMode
+
+	// Filtering specifies how the texture is filtered.
+	Filtering render.FilterMode
+
+	// Mipmapping specifies whether the texture has mipmap levels.
+	Mipmapping bool
+
+	// GammaCorrection specifies whether the data is in sRGB color space.
+	GammaCorrection bool
+
+	// Format is the format of the texture and of Data.
+	Format render.DataFormat
+
+	// Data holds the optional initial content of all slices, one after
+	// the other.
+	Data []byte
+}
+
+// DepthTexture2DArrayInfo contains the information needed to create a
+// 2D array depth texture (e.g. for cascaded shadow maps). Shaders sample
+// it through sampler2DArray or, if comparable, sampler2DArrayShadow.
+type DepthTexture2DArrayInfo struct {
+
+	// Width is the width of each layer.
+	Width int
+
+	// Height is the height of each layer.
+	Height int
+
+	// Layers is the number of layers.
+	Layers int
+
+	// ClippedValue, if specified, is the depth value that is returned for
+	// coordinates outside of a layer.
+	ClippedValue *float32
+
+	// Comparable specifies whether the texture is sampled with depth
+	// comparison.
+	Comparable bool
+
+	// Format is the precision of the depth values, which is one of
+	// DataFormatDepth16, DataFormatDepth24 and DataFormatDepth32F. If not
+	// specified, 32-bit unsigned normalized values are used.
+	Format render.DataFormat
+}
@@ -0,0 +1,91 @@
+package golden
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+)
+
+// Result holds the outcome of an image comparison.
+type Result struct {
+
+	// SizeMismatch indicates that the two images have different
+	// dimensions, in which case no per-pixel comparison is performed.
+	SizeMismatch bool
+
+	// Mismatches is the number of pixels that differ by more than
+	// the tolerance in at least one channel.
+	Mismatches int
+
+	// MaxDelta is the largest per-channel difference that was found.
+	MaxDelta uint8
+
+	// Diff is an image that highlights mismatched pixels in red over
+	// a dimmed version of the expected image. It is nil when the
+	// images have different sizes.
+	Diff *image.RGBA
+}
+
+// Match returns whether the compared images are considered equal.
+func (r Result) Match() bool {
+	return !r.SizeMismatch && r.Mismatches == 0
+}
+
+// String returns a human-readable summary of the result.
+func (r Result) String() string {
+	if r.SizeMismatch {
+		return "image sizes differ"
+	}
+	return fmt.Sprintf("%d mismatched pixels (max delta %d)", r.Mismatches, r.MaxDelta)
+}
+
+// Compare performs a per-pixel comparison of the actual and expected
+// images. Two pixels match if none of their RGBA channels differ by more
+// than the specified tolerance.
+func Compare(actual, expected image.Image, tolerance uint8) Result {
+	bounds := actual.Bounds()
+	expectedBounds := expected.Bounds()
+	if bounds.Dx() != expectedBounds.Dx() || bounds.Dy() != expectedBounds.Dy() {
+		return Result{
+			SizeMismatch: true,
+		}
+	}
+
+	var result Result
+	diff := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			actualColor := color.RGBAModel.Convert(actual.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.RGBA)
+			expectedColor := color.RGBAModel.Convert(expected.At(expectedBounds.Min.X+x, expectedBounds.Min.Y+y)).(color.RGBA)
+
+			delta := max(
+				channelDelta(actualColor.R, expectedColor.R),
+				channelDelta(actualColor.G, expectedColor.G),
+				channelDelta(actualColor.B, expectedColor.B),
+				channelDelta(actualColor.A, expectedColor.A),
+			)
+			result.MaxDelta = max(result.MaxDelta, delta)
+
+			if delta > tolerance {
+				result.Mismatches++
+				diff.SetRGBA(x, y, color.RGBA{R: 0xFF, G: 0x00, B: 0x00, A: 0xFF})
+			} else {
+				diff.SetRGBA(x, y, color.RGBA{
+					R: expectedColor.R / 4,
+					G: expectedColor.G / 4,
+					B: expectedColor.B / 4,
+					A: 0xFF,
+				})
+			}
+		}
+	}
+	result.Diff = diff
+	return result
+}
+
+func channelDelta(a, b uint8) uint8 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
@@ -0,0 +1,220 @@
+// Package golden provides a harness for golden-image regression testing
+// of the render backend.
+//
+// Scenes are rendered through a headless context into an offscreen
+// framebuffer and the resulting pixels are compared against checked-in
+// PNG images. Rendering is performed by Mesa's software rasterizer, so
+// no GPU or display is required.
+package golden
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+
+	glapp "github.com/mokiat/lacking-gl/app"
+	glrender "github.com/mokiat/lacking-gl/render"
+	"github.com/mokiat/lacking/app"
+	"github.com/mokiat/lacking/render"
+)
+
+// UpdateEnvVar is the name of the environment variable that, when set
+// to a non-empty value, causes Assert to overwrite the golden images
+// with the actual results instead of comparing against them.
+const UpdateEnvVar = "LACKING_GL_UPDATE_GOLDEN"
+
+// Target describes the offscreen framebuffer that a Scene should
+// render into.
+type Target struct {
+	Framebuffer render.Framebuffer
+	Width       int
+	Height      int
+}
+
+// Scene renders content into the specified target. It is responsible
+// for beginning and ending its own render passes.
+type Scene func(api render.API, target Target)
+
+// Render executes the specified scene in a new headless context and
+// returns the content of the color attachment as a top-down image.
+//
+// Unless configured otherwise through the environment, the software
+// rasterizer is used, so that results are reproducible across machines.
+// This is done by setting LIBGL_ALWAYS_SOFTWARE in the environment of the
+// process when it is not already present, which also affects any other
+// contexts that the process creates afterwards.
+func Render(width, height int, scene Scene) (*image.RGBA, error) {
+	if _, ok := os.LookupEnv("LIBGL_ALWAYS_SOFTWARE"); !ok {
+		os.Setenv("LIBGL_ALWAYS_SOFTWARE", "1")
+	}
+	controller := &sceneController{
+		width:  width,
+		height: height,
+		scene:  scene,
+	}
+	cfg := glapp.NewConfig("golden", width, height)
+	if err := glapp.RunHeadless(cfg, controller); err != nil {
+		return nil, fmt.Errorf("failed to run headless context: %w", err)
+	}
+	if controller.result == nil {
+		return nil, errors.New("scene was not rendered")
+	}
+	return controller.result, nil
+}
+
+// Options control how rendered images are compared against goldens.
+type Options struct {
+
+	// Directory is the folder that holds the golden PNG images.
+	// Defaults to "testdata/golden".
+	Directory string
+
+	// OutputDirectory is the folder where actual and diff images are
+	// written when a comparison fails. Defaults to a "golden" folder
+	// inside the system temp directory.
+	OutputDirectory string
+
+	// Tolerance is the maximum per-channel difference that is still
+	// considered a match.
+	Tolerance uint8
+}
+
+// T is the subset of testing.TB that is used by Assert.
+type T interface {
+	Helper()
+	Logf(format string, args ...any)
+	Errorf(format string, args ...any)
+	Fatalf(format string, args ...any)
+}
+
+// Assert compares the actual image against the golden image with the
+// specified name and reports any differences to t. When the comparison
+// fails, the actual image and a diff image are written to the output
+// directory.
+//
+// If the UpdateEnvVar environment variable is set, the golden image is
+// written instead. A missing golden image is a failure otherwise, so that
+// deleted or misnamed goldens are not silently recreated.
+func Assert(t T, name string, actual image.Image, opts Options) {
+	t.Helper()
+	if opts.Directory == "" {
+		opts.Directory = filepath.Join("testdata", "golden")
+	}
+	if opts.OutputDirectory == "" {
+		opts.OutputDirectory = filepath.Join(os.TempDir(), "golden")
+	}
+
+	goldenPath := filepath.Join(opts.Directory, name+".png")
+	if os.Getenv(UpdateEnvVar) != "" {
+		if err := writeImage(goldenPath, actual); err != nil {
+			t.Fatalf("failed to update golden %q: %v", goldenPath, err)
+		}
+		t.Logf("updated golden %q", goldenPath)
+		return
+	}
+
+	expected, err := readImage(goldenPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("golden %q does not exist (set %s to create it)", goldenPath, UpdateEnvVar)
+			return
+		}
+		t.Fatalf("failed to read golden %q: %v", goldenPath, err)
+		return
+	}
+
+	result := Compare(actual, expected, opts.Tolerance)
+	if result.Match() {
+		return
+	}
+
+	actualPath := filepath.Join(opts.OutputDirectory, name+".actual.png")
+	if err := writeImage(actualPath, actual); err != nil {
+		t.Errorf("failed to write actual image %q: %v", actualPath, err)
+	}
+	diffPath := filepath.Join(opts.OutputDirectory, name+".diff.png")
+	if result.Diff != nil {
+		if err := writeImage(diffPath, result.Diff); err != nil {
+			t.Errorf("failed to write diff image %q: %v", diffPath, err)
+		}
+	}
+	t.Errorf("image %q does not match golden: %s (actual: %q, diff: %q)",
+		name, result, actualPath, diffPath,
+	)
+}
+
+type sceneController struct {
+	app.NopController
+	width  int
+	height int
+	scene  Scene
+	result *image.RGBA
+}
+
+func (c *sceneController) OnRender(window app.Window) {
+	defer window.Close()
+
+	api := window.RenderAPI()
+	colorTexture := api.CreateColorTexture2D(render.ColorTexture2DInfo{
+		Width:     c.width,
+		Height:    c.height,
+		Wrapping:  render.WrapModeClamp,
+		Filtering: render.FilterModeNearest,
+		Format:    render.DataFormatRGBA8,
+	})
+	defer colorTexture.Release()
+
+	depthStencilTexture := api.CreateDepthStencilTexture2D(render.DepthStencilTexture2DInfo{
+		Width:  c.width,
+		Height: c.height,
+	})
+	defer depthStencilTexture.Release()
+
+	framebuffer := api.CreateFramebuffer(render.FramebufferInfo{
+		ColorAttachments: [4]render.Texture{
+			colorTexture,
+		},
+		DepthStencilAttachment: depthStencilTexture,
+	})
+	defer framebuffer.Release()
+
+	c.scene(api, Target{
+		Framebuffer: framebuffer,
+		Width:       c.width,
+		Height:      c.height,
+	})
+	c.result = glrender.ReadPixels(api, framebuffer, c.width, c.height)
+}
+
+func readImage(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	img, err := png.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode png: %w", err)
+	}
+	return img, nil
+}
+
+func writeImage(path string, img image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer file.Close()
+
+	if err := png.Encode(file, img); err != nil {
+		return fmt.Errorf("failed to encode png: %w", err)
+	}
+	return nil
+}
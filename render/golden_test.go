@@ -0,0 +1,133 @@
+package render_test
+
+import (
+	"encoding/binary"
+	"image"
+	"math"
+	"testing"
+
+	"github.com/mokiat/lacking-gl/render/golden"
+	"github.com/mokiat/lacking/render"
+)
+
+func TestGoldenClear(t *testing.T) {
+	actual := renderGolden(t, 16, 16, func(api render.API, target golden.Target) {
+		api.BeginRenderPass(render.RenderPassInfo{
+			Framebuffer: target.Framebuffer,
+			Viewport: render.Area{
+				Width:  target.Width,
+				Height: target.Height,
+			},
+			Colors: [4]render.ColorAttachmentInfo{
+				{
+					LoadOp:     render.LoadOperationClear,
+					StoreOp:    render.StoreOperationStore,
+					ClearValue: [4]float32{0.2, 0.4, 0.6, 1.0},
+				},
+			},
+		})
+		api.EndRenderPass()
+	})
+	golden.Assert(t, "clear", actual, golden.Options{
+		Tolerance: 1,
+	})
+}
+
+func TestGoldenTriangle(t *testing.T) {
+	actual := renderGolden(t, 32, 32, func(api render.API, target golden.Target) {
+		vertexShader := api.CreateVertexShader(render.ShaderInfo{
+			SourceCode: `#version 450
+layout(location = 0) in vec2 position;
+void main() {
+	gl_Position = vec4(position, 0.0, 1.0);
+}
+`,
+		})
+		defer vertexShader.Release()
+
+		fragmentShader := api.CreateFragmentShader(render.ShaderInfo{
+			SourceCode: `#version 450
+layout(location = 0) out vec4 color;
+void main() {
+	color = vec4(1.0, 0.5, 0.0, 1.0);
+}
+`,
+		})
+		defer fragmentShader.Release()
+
+		program := api.CreateProgram(render.ProgramInfo{
+			VertexShader:   vertexShader,
+			FragmentShader: fragmentShader,
+		})
+		defer program.Release()
+
+		positions := []float32{
+			-0.75, -0.75,
+			0.75, -0.75,
+			0.0, 0.75,
+		}
+		data := make([]byte, len(positions)*4)
+		for i, position := range positions {
+			binary.LittleEndian.PutUint32(data[i*4:], math.Float32bits(position))
+		}
+		vertexBuffer := api.CreateVertexBuffer(render.BufferInfo{
+			Data: data,
+		})
+		defer vertexBuffer.Release()
+
+		vertexArray := api.CreateVertexArray(render.VertexArrayInfo{
+			Bindings: []render.VertexArrayBindingInfo{
+				{
+					VertexBuffer: vertexBuffer,
+					Stride:       8,
+				},
+			},
+			Attributes: []render.VertexArrayAttributeInfo{
+				{
+					Binding:  0,
+					Location: 0,
+					Format:   render.VertexAttributeFormatRG32F,
+				},
+			},
+		})
+		defer vertexArray.Release()
+
+		pipeline := api.CreatePipeline(render.PipelineInfo{
+			Program:     program,
+			VertexArray: vertexArray,
+			Topology:    render.TopologyTriangles,
+			ColorWrite:  [4]bool{true, true, true, true},
+		})
+		defer pipeline.Release()
+
+		api.BeginRenderPass(render.RenderPassInfo{
+			Framebuffer: target.Framebuffer,
+			Viewport: render.Area{
+				Width:  target.Width,
+				Height: target.Height,
+			},
+			Colors: [4]render.ColorAttachmentInfo{
+				{
+					LoadOp:     render.LoadOperationClear,
+					StoreOp:    render.StoreOperationStore,
+					ClearValue: [4]float32{0.0, 0.0, 0.0, 1.0},
+				},
+			},
+		})
+		api.BindPipeline(pipeline)
+		api.Draw(0, 3, 1)
+		api.EndRenderPass()
+	})
+	golden.Assert(t, "triangle", actual, golden.Options{
+		Tolerance: 1,
+	})
+}
+
+func renderGolden(t *testing.T, width, height int, scene golden.Scene) *image.RGBA {
+	t.Helper()
+	actual, err := golden.Render(width, height, scene)
+	if err != nil {
+		t.Skipf("headless rendering is not available: %v", err)
+	}
+	return actual
+}
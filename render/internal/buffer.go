@@ -21,6 +21,14 @@ func NewUniformBuffer(info render.BufferInfo) render.Buffer {
 	return newBuffer(info)
 }
 
+func NewStorageBuffer(info render.BufferInfo) render.Buffer {
+	return newBuffer(info)
+}
+
+func NewIndirectBuffer(info render.BufferInfo) render.Buffer {
+	return newBuffer(info)
+}
+
 func newBuffer(info render.BufferInfo) *Buffer {
 	var id uint32
 	gl.CreateBuffers(1, &id)
@@ -41,6 +49,11 @@ type Buffer struct {
 	id uint32
 }
 
+// ID returns the OpenGL name of this buffer.
+func (b *Buffer) ID() uint32 {
+	return b.id
+}
+
 func (b *Buffer) Update(info render.BufferUpdateInfo) {
 	gl.NamedBufferSubData(b.id, info.Offset, len(info.Data), gl.Ptr(&info.Data[0]))
 }
@@ -0,0 +1,137 @@
+package internal
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/go-gl/gl/v4.6-core/gl"
+	"github.com/mokiat/lacking-gl/render/ext"
+	"github.com/mokiat/lacking/render"
+)
+
+// DetectCapabilities queries the capabilities of the current OpenGL
+// context.
+func DetectCapabilities() ext.Capabilities {
+	result := ext.Capabilities{
+		Version: ext.Version{
+			Major: getInteger(gl.MAJOR_VERSION),
+			Minor: getInteger(gl.MINOR_VERSION),
+		},
+		VersionString:                gl.GoStr(gl.GetString(gl.VERSION)),
+		ShadingLanguageVersion:       gl.GoStr(gl.GetString(gl.SHADING_LANGUAGE_VERSION)),
+		Vendor:                       gl.GoStr(gl.GetString(gl.VENDOR)),
+		Renderer:                     gl.GoStr(gl.GetString(gl.RENDERER)),
+		MaxTextureSize:               getInteger(gl.MAX_TEXTURE_SIZE),
+		MaxCubeTextureSize:           getInteger(gl.MAX_CUBE_MAP_TEXTURE_SIZE),
+		MaxTextureUnits:              getInteger(gl.MAX_COMBINED_TEXTURE_IMAGE_UNITS),
+		MaxUniformBlockSize:          getInteger(gl.MAX_UNIFORM_BLOCK_SIZE),
+		MaxUniformBufferBindings:     getInteger(gl.MAX_UNIFORM_BUFFER_BINDINGS),
+		UniformBufferOffsetAlignment: UniformBufferOffsetAlignment(),
+		MaxSamples:                   getInteger(gl.MAX_SAMPLES),
+		MaxColorTextureSamples:       getInteger(gl.MAX_COLOR_TEXTURE_SAMPLES),
+		MaxDepthTextureSamples:       getInteger(gl.MAX_DEPTH_TEXTURE_SAMPLES),
+		MaxPatchVertices:             getInteger(gl.MAX_PATCH_VERTICES),
+		ProgramBinaryFormats:         getInteger(gl.NUM_PROGRAM_BINARY_FORMATS),
+		Extensions:                   getExtensions(),
+	}
+	result.Software = isSoftwareRenderer(result.Renderer)
+
+	// Anisotropic filtering is part of core since 4.6 and uses the same
+	// constant as the extensions that provided it earlier.
+	if result.Version.AtLeast(4, 6) ||
+		result.HasExtension("GL_ARB_texture_filter_anisotropic") ||
+		result.HasExtension("GL_EXT_texture_filter_anisotropic") {
+		gl.GetFloatv(gl.MAX_TEXTURE_MAX_ANISOTROPY, &result.MaxAnisotropy)
+	}
+
+	// Prior to 4.6, the count variants of the indirect draws are only
+	// available through an extension, with differently named functions.
+	result.IndirectCount = result.Version.AtLeast(4, 6) ||
+		result.HasExtension("GL_ARB_indirect_parameters")
+	result.PipelineStatistics = result.Version.AtLeast(4, 6) ||
+		result.HasExtension("GL_ARB_pipeline_statistics_query")
+	result.SPIRV = result.Version.AtLeast(4, 6) ||
+		result.HasExtension("GL_ARB_gl_spirv")
+	result.ParallelShaderCompile = result.HasExtension("GL_KHR_parallel_shader_compile") ||
+		result.HasExtension("GL_ARB_parallel_shader_compile")
+
+	result.CompressedFormats, result.CompressedSRGBFormats = detectCompressedFormats(result)
+
+	result.Quality = determineQuality(result)
+	return result
+}
+
+// UniformBufferOffsetAlignment returns the alignment, in bytes, that the
+// offset of a uniform buffer range binding needs to have.
+func UniformBufferOffsetAlignment() int {
+	return getInteger(gl.UNIFORM_BUFFER_OFFSET_ALIGNMENT)
+}
+
+// detectCompressedFormats returns the block-compressed formats that are
+// supported without and with gamma correction.
+func detectCompressedFormats(capabilities ext.Capabilities) ([]render.DataFormat, []render.DataFormat) {
+	var formats, srgbFormats []render.DataFormat
+
+	// S3TC is not part of core, due to patents that have since expired,
+	// and its sRGB variants come from yet another extension.
+	if capabilities.HasExtension("GL_EXT_texture_compression_s3tc") {
+		formats = append(formats, ext.DataFormatBC1, ext.DataFormatBC3)
+		if capabilities.HasExtension("GL_EXT_texture_sRGB") {
+			srgbFormats = append(srgbFormats, ext.DataFormatBC1, ext.DataFormatBC3)
+		}
+	}
+
+	// RGTC has been part of core since 3.0. It has no sRGB variants.
+	formats = append(formats, ext.DataFormatBC4, ext.DataFormatBC5)
+
+	if capabilities.Version.AtLeast(4, 2) || capabilities.HasExtension("GL_ARB_texture_compression_bptc") {
+		formats = append(formats, ext.DataFormatBC7)
+		srgbFormats = append(srgbFormats, ext.DataFormatBC7)
+	}
+
+	if capabilities.Version.AtLeast(4, 3) || capabilities.HasExtension("GL_ARB_ES3_compatibility") {
+		formats = append(formats, ext.DataFormatETC2RGB8, ext.DataFormatETC2RGBA8)
+		srgbFormats = append(srgbFormats, ext.DataFormatETC2RGB8, ext.DataFormatETC2RGBA8)
+	}
+
+	return formats, srgbFormats
+}
+
+func determineQuality(capabilities ext.Capabilities) render.Quality {
+	switch {
+	case capabilities.Software:
+		return render.QualityLow
+	case capabilities.MaxTextureSize < 8192 || capabilities.MaxSamples < 4:
+		return render.QualityLow
+	case capabilities.MaxTextureSize < 16384 || capabilities.MaxSamples < 8 || capabilities.MaxAnisotropy < 16.0:
+		return render.QualityMedium
+	default:
+		return render.QualityHigh
+	}
+}
+
+func isSoftwareRenderer(renderer string) bool {
+	renderer = strings.ToLower(renderer)
+	for _, name := range []string{"llvmpipe", "softpipe", "swiftshader", "software"} {
+		if strings.Contains(renderer, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func getExtensions() []string {
+	count := getInteger(gl.NUM_EXTENSIONS)
+	result := make([]string, count)
+	for i := range result {
+		result[i] = gl.GoStr(gl.GetStringi(gl.EXTENSIONS, uint32(i)))
+	}
+	slices.Sort(result)
+	return result
+}
+
+func getInteger(name uint32) int {
+	var value int32
+	gl.GetIntegerv(name, &value)
+	return int(value)
+}
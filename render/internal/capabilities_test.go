@@ -0,0 +1,129 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/mokiat/lacking-gl/render/ext"
+	"github.com/mokiat/lacking/render"
+)
+
+func TestDetermineQuality(t *testing.T) {
+	testCases := []struct {
+		name         string
+		capabilities ext.Capabilities
+		expected     render.Quality
+	}{
+		{
+			name: "software renderer",
+			capabilities: ext.Capabilities{
+				Software:       true,
+				MaxTextureSize: 16384,
+				MaxSamples:     8,
+				MaxAnisotropy:  16.0,
+			},
+			expected: render.QualityLow,
+		},
+		{
+			name: "small textures",
+			capabilities: ext.Capabilities{
+				MaxTextureSize: 4096,
+				MaxSamples:     8,
+				MaxAnisotropy:  16.0,
+			},
+			expected: render.QualityLow,
+		},
+		{
+			name: "few samples",
+			capabilities: ext.Capabilities{
+				MaxTextureSize: 16384,
+				MaxSamples:     2,
+				MaxAnisotropy:  16.0,
+			},
+			expected: render.QualityLow,
+		},
+		{
+			name: "medium textures",
+			capabilities: ext.Capabilities{
+				MaxTextureSize: 8192,
+				MaxSamples:     8,
+				MaxAnisotropy:  16.0,
+			},
+			expected: render.QualityMedium,
+		},
+		{
+			name: "medium samples",
+			capabilities: ext.Capabilities{
+				MaxTextureSize: 16384,
+				MaxSamples:     4,
+				MaxAnisotropy:  16.0,
+			},
+			expected: render.QualityMedium,
+		},
+		{
+			name: "limited anisotropy",
+			capabilities: ext.Capabilities{
+				MaxTextureSize: 16384,
+				MaxSamples:     8,
+				MaxAnisotropy:  8.0,
+			},
+			expected: render.QualityMedium,
+		},
+		{
+			name: "no anisotropy",
+			capabilities: ext.Capabilities{
+				MaxTextureSize: 16384,
+				MaxSamples:     8,
+			},
+			expected: render.QualityMedium,
+		},
+		{
+			name: "high",
+			capabilities: ext.Capabilities{
+				MaxTextureSize: 16384,
+				MaxSamples:     8,
+				MaxAnisotropy:  16.0,
+			},
+			expected: render.QualityHigh,
+		},
+		{
+			name: "above high",
+			capabilities: ext.Capabilities{
+				MaxTextureSize: 32768,
+				MaxSamples:     32,
+				MaxAnisotropy:  16.0,
+			},
+			expected: render.QualityHigh,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := determineQuality(tc.capabilities)
+			if actual != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestIsSoftwareRenderer(t *testing.T) {
+	testCases := []struct {
+		renderer string
+		expected bool
+	}{
+		{renderer: "llvmpipe (LLVM 15.0.7, 256 bits)", expected: true},
+		{renderer: "softpipe", expected: true},
+		{renderer: "Google SwiftShader", expected: true},
+		{renderer: "GDI Generic Software Renderer", expected: true},
+		{renderer: "NVIDIA GeForce RTX 3080/PCIe/SSE2", expected: false},
+		{renderer: "AMD Radeon RX 6800 XT (radeonsi, navi21, LLVM 15.0.7)", expected: false},
+		{renderer: "Mesa Intel(R) UHD Graphics 620 (KBL GT2)", expected: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.renderer, func(t *testing.T) {
+			actual := isSoftwareRenderer(tc.renderer)
+			if actual != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -1,10 +1,9 @@
 package internal
 
 import (
-	"fmt"
 	"unsafe"
 
-	"github.com/go-gl/gl/v4.6-core/gl"
+	"github.com/mokiat/lacking-gl/render/ext"
 	"github.com/mokiat/lacking/render"
 )
 
@@ -31,7 +30,7 @@ func (q *CommandQueue) BindPipeline(pipeline render.Pipeline) {
 	})
 	intPipeline := pipeline.(*Pipeline)
 	PushCommand(q, CommandBindPipeline{
-		ProgramID:        intPipeline.ProgramID,
+		ProgramID:        intPipeline.Program.id,
 		Topology:         intPipeline.Topology,
 		CullTest:         intPipeline.CullTest,
 		FrontFace:        intPipeline.FrontFace,
@@ -50,6 +49,7 @@ func (q *CommandQueue) BindPipeline(pipeline render.Pipeline) {
 		BlendColor:       intPipeline.BlendColor,
 		BlendEquation:    intPipeline.BlendEquation,
 		BlendFunc:        intPipeline.BlendFunc,
+		AlphaToCoverage:  intPipeline.AlphaToCoverage,
 		VertexArray:      intPipeline.VertexArray,
 	})
 }
@@ -162,28 +162,15 @@ func (q *CommandQueue) CopyContentToBuffer(info render.CopyContentToBufferInfo)
 	PushCommand(q, CommandHeader{
 		Kind: CommandKindCopyContentToBuffer,
 	})
-	var format, xtype uint32
-	switch info.Format {
-	case render.DataFormatRGBA8:
-		format = gl.RGBA
-		xtype = gl.UNSIGNED_BYTE
-	case render.DataFormatRGBA16F:
-		format = gl.RGBA
-		xtype = gl.HALF_FLOAT
-	case render.DataFormatRGBA32F:
-		format = gl.RGBA
-		xtype = gl.FLOAT
-	default:
-		panic(fmt.Errorf("unsupported data format %v", info.Format))
-	}
+	format := lookupDataFormat(info.Format)
 	PushCommand(q, CommandCopyContentToBuffer{
 		BufferID:     info.Buffer.(*Buffer).id,
 		X:            int32(info.X),
 		Y:            int32(info.Y),
 		Width:        int32(info.Width),
 		Height:       int32(info.Height),
-		Format:       format,
-		XType:        xtype,
+		Format:       format.format,
+		XType:        format.componentType,
 		BufferOffset: uint32(info.Offset),
 	})
 }
@@ -200,6 +187,161 @@ func (q *CommandQueue) UpdateBufferData(buffer render.Buffer, info render.Buffer
 	PushData(q, info.Data)
 }
 
+func (q *CommandQueue) BindComputeProgram(program render.Program) {
+	PushCommand(q, CommandHeader{
+		Kind: CommandKindBindProgram,
+	})
+	PushCommand(q, CommandBindProgram{
+		ProgramID: program.(*Program).id,
+	})
+}
+
+func (q *CommandQueue) StorageBufferUnit(index int, buffer render.Buffer) {
+	PushCommand(q, CommandHeader{
+		Kind: CommandKindStorageBufferUnit,
+	})
+	PushCommand(q, CommandStorageBufferUnit{
+		Index:    uint32(index),
+		BufferID: buffer.(*Buffer).id,
+	})
+}
+
+func (q *CommandQueue) StorageBufferUnitRange(index int, buffer render.Buffer, offset, size int) {
+	PushCommand(q, CommandHeader{
+		Kind: CommandKindStorageBufferUnitRange,
+	})
+	PushCommand(q, CommandStorageBufferUnitRange{
+		Index:    uint32(index),
+		BufferID: buffer.(*Buffer).id,
+		Offset:   uint32(offset),
+		Size:     uint32(size),
+	})
+}
+
+func (q *CommandQueue) ImageUnit(index int, texture render.Texture, info ext.ImageUnitInfo) {
+	PushCommand(q, CommandHeader{
+		Kind: CommandKindImageUnit,
+	})
+	PushCommand(q, newCommandImageUnit(index, texture, info))
+}
+
+func (q *CommandQueue) Dispatch(groupsX, groupsY, groupsZ int) {
+	PushCommand(q, CommandHeader{
+		Kind: CommandKindDispatch,
+	})
+	PushCommand(q, CommandDispatch{
+		GroupsX: uint32(groupsX),
+		GroupsY: uint32(groupsY),
+		GroupsZ: uint32(groupsZ),
+	})
+}
+
+func (q *CommandQueue) DispatchIndirect(buffer render.Buffer, offset int) {
+	PushCommand(q, CommandHeader{
+		Kind: CommandKindDispatchIndirect,
+	})
+	PushCommand(q, CommandDispatchIndirect{
+		BufferID: buffer.(*Buffer).id,
+		Offset:   uint32(offset),
+	})
+}
+
+func (q *CommandQueue) MemoryBarrier(barrier ext.Barrier) {
+	PushCommand(q, CommandHeader{
+		Kind: CommandKindMemoryBarrier,
+	})
+	PushCommand(q, CommandMemoryBarrier{
+		Barriers: glBarrierBits(barrier),
+	})
+}
+
+func (q *CommandQueue) DrawIndirect(buffer render.Buffer, offset int) {
+	PushCommand(q, CommandHeader{
+		Kind: CommandKindDrawIndirect,
+	})
+	PushCommand(q, CommandDrawIndirect{
+		BufferID: buffer.(*Buffer).id,
+		Offset:   uint32(offset),
+	})
+}
+
+func (q *CommandQueue) DrawIndexedIndirect(buffer render.Buffer, offset int) {
+	PushCommand(q, CommandHeader{
+		Kind: CommandKindDrawIndexedIndirect,
+	})
+	PushCommand(q, CommandDrawIndexedIndirect{
+		BufferID: buffer.(*Buffer).id,
+		Offset:   uint32(offset),
+	})
+}
+
+func (q *CommandQueue) MultiDrawIndexedIndirect(info ext.MultiDrawIndexedIndirectInfo) {
+	PushCommand(q, CommandHeader{
+		Kind: CommandKindMultiDrawIndexedIndirect,
+	})
+	PushCommand(q, newCommandMultiDrawIndexedIndirect(info))
+}
+
+func (q *CommandQueue) WriteTimestamp(query ext.Query) {
+	PushCommand(q, CommandHeader{
+		Kind: CommandKindWriteTimestamp,
+	})
+	PushCommand(q, newCommandWriteTimestamp(query))
+}
+
+func (q *CommandQueue) BeginQuery(query ext.Query) {
+	PushCommand(q, CommandHeader{
+		Kind: CommandKindBeginQuery,
+	})
+	PushCommand(q, newCommandBeginQuery(query))
+}
+
+func (q *CommandQueue) EndQuery(query ext.Query) {
+	PushCommand(q, CommandHeader{
+		Kind: CommandKindEndQuery,
+	})
+	PushCommand(q, newCommandEndQuery(query))
+}
+
+func (q *CommandQueue) UpdateTextureData(texture render.Texture, info ext.TextureUpdateInfo) {
+	PushCommand(q, CommandHeader{
+		Kind: CommandKindUpdateTextureData,
+	})
+	command, data := newCommandUpdateTextureData(texture, info)
+	PushCommand(q, command)
+	PushData(q, data)
+}
+
+func (q *CommandQueue) GenerateTextureMipmaps(texture render.Texture) {
+	PushCommand(q, CommandHeader{
+		Kind: CommandKindGenerateTextureMipmaps,
+	})
+	PushCommand(q, CommandGenerateTextureMipmaps{
+		TextureID: texture.(*Texture).id,
+	})
+}
+
+func (q *CommandQueue) CopyTextureToBuffer(texture render.Texture, info ext.TextureCopyToBufferInfo) {
+	PushCommand(q, CommandHeader{
+		Kind: CommandKindCopyTextureToBuffer,
+	})
+	PushCommand(q, newCommandCopyTextureToBuffer(texture, info))
+}
+
+func (q *CommandQueue) SamplerUnit(index int, sampler ext.Sampler) {
+	PushCommand(q, CommandHeader{
+		Kind: CommandKindSamplerUnit,
+	})
+	PushCommand(q, newCommandSamplerUnit(index, sampler))
+}
+
+func (q *CommandQueue) BlitFramebuffer(info ext.BlitFramebufferInfo) {
+	PushCommand(q, CommandHeader{
+		Kind: CommandKindBlitFramebuffer,
+	})
+	PushCommand(q, newCommandBlitFramebuffer(info))
+}
+
 func (q *CommandQueue) Release() {
 	q.data = nil
 }
@@ -280,6 +422,24 @@ const (
 	CommandKindDrawIndexed
 	CommandKindCopyContentToBuffer
 	CommandKindUpdateBufferData
+	CommandKindBindProgram
+	CommandKindStorageBufferUnit
+	CommandKindStorageBufferUnitRange
+	CommandKindImageUnit
+	CommandKindDispatch
+	CommandKindDispatchIndirect
+	CommandKindMemoryBarrier
+	CommandKindDrawIndirect
+	CommandKindDrawIndexedIndirect
+	CommandKindMultiDrawIndexedIndirect
+	CommandKindWriteTimestamp
+	CommandKindBeginQuery
+	CommandKindEndQuery
+	CommandKindUpdateTextureData
+	CommandKindGenerateTextureMipmaps
+	CommandKindCopyTextureToBuffer
+	CommandKindSamplerUnit
+	CommandKindBlitFramebuffer
 )
 
 type CommandHeader struct {
@@ -306,11 +466,13 @@ type CommandBindPipeline struct {
 	BlendEquation    CommandBlendEquation
 	BlendFunc        CommandBlendFunc
 	BlendColor       CommandBlendColor
+	AlphaToCoverage  bool // not dynamic
 	VertexArray      CommandBindVertexArray
 }
 
 type CommandTopology struct {
-	Topology uint32
+	Topology      uint32
+	PatchVertices int32
 }
 
 type CommandCullTest struct {
@@ -452,3 +614,133 @@ type CommandUpdateBufferData struct {
 	Offset   uint32
 	Count    uint32
 }
+
+type CommandBindProgram struct {
+	ProgramID uint32
+}
+
+type CommandStorageBufferUnit struct {
+	Index    uint32
+	BufferID uint32
+}
+
+type CommandStorageBufferUnitRange struct {
+	Index    uint32
+	BufferID uint32
+	Offset   uint32
+	Size     uint32
+}
+
+type CommandImageUnit struct {
+	Index     uint32
+	TextureID uint32
+	Level     int32
+	Layered   bool
+	Layer     int32
+	Access    uint32
+	Format    uint32
+}
+
+type CommandDispatch struct {
+	GroupsX uint32
+	GroupsY uint32
+	GroupsZ uint32
+}
+
+type CommandDispatchIndirect struct {
+	BufferID uint32
+	Offset   uint32
+}
+
+type CommandMemoryBarrier struct {
+	Barriers uint32
+}
+
+type CommandDrawIndirect struct {
+	BufferID uint32
+	Offset   uint32
+}
+
+type CommandDrawIndexedIndirect struct {
+	BufferID uint32
+	Offset   uint32
+}
+
+type CommandMultiDrawIndexedIndirect struct {
+	BufferID      uint32
+	Offset        uint32
+	DrawCount     int32
+	Stride        int32
+	CountBufferID uint32
+	CountOffset   uint32
+}
+
+type CommandWriteTimestamp struct {
+	QueryID uint32
+}
+
+type CommandBeginQuery struct {
+	QueryID uint32
+	Target  uint32
+}
+
+type CommandEndQuery struct {
+	QueryID uint32
+	Target  uint32
+}
+
+type CommandUpdateTextureData struct {
+	TextureID  uint32
+	Layered    bool
+	Compressed bool
+	Level      int32
+	Layer      int32
+	Layers     int32
+	X          int32
+	Y          int32
+	Width      int32
+	Height     int32
+	Format     uint32
+	XType      uint32
+	Count      uint32
+}
+
+type CommandGenerateTextureMipmaps struct {
+	TextureID uint32
+}
+
+type CommandCopyTextureToBuffer struct {
+	TextureID    uint32
+	BufferID     uint32
+	Level        int32
+	Layer        int32
+	Layers       int32
+	X            int32
+	Y            int32
+	Width        int32
+	Height       int32
+	Format       uint32
+	XType        uint32
+	BufferOffset uint32
+	BufferSize   uint32
+}
+
+type CommandSamplerUnit struct {
+	Index     uint32
+	SamplerID uint32
+}
+
+type CommandBlitFramebuffer struct {
+	SourceID      uint32
+	DestinationID uint32
+	SourceX0      int32
+	SourceY0      int32
+	SourceX1      int32
+	SourceY1      int32
+	DestinationX0 int32
+	DestinationY0 int32
+	DestinationX1 int32
+	DestinationY1 int32
+	Mask          uint32
+	Filter        uint32
+}
@@ -0,0 +1,75 @@
+package internal
+
+import (
+	"fmt"
+
+	"github.com/go-gl/gl/v4.6-core/gl"
+	"github.com/mokiat/lacking-gl/render/ext"
+	"github.com/mokiat/lacking/render"
+)
+
+func newCommandImageUnit(index int, texture render.Texture, info ext.ImageUnitInfo) CommandImageUnit {
+	return CommandImageUnit{
+		Index:     uint32(index),
+		TextureID: texture.(*Texture).id,
+		Level:     int32(info.Level),
+		Layered:   info.Layered,
+		Layer:     int32(info.Layer),
+		Access:    glImageAccess(info.Access),
+		Format:    glInternalFormat(info.Format, false),
+	}
+}
+
+func glImageAccess(access ext.ImageAccess) uint32 {
+	switch access {
+	case ext.ImageAccessReadOnly:
+		return gl.READ_ONLY
+	case ext.ImageAccessWriteOnly:
+		return gl.WRITE_ONLY
+	case ext.ImageAccessReadWrite:
+		return gl.READ_WRITE
+	default:
+		panic(fmt.Errorf("unknown image access: %d", access))
+	}
+}
+
+func glBarrierBits(barrier ext.Barrier) uint32 {
+	if barrier == ext.BarrierAll {
+		return gl.ALL_BARRIER_BITS
+	}
+	var result uint32
+	if barrier&ext.BarrierVertexAttribute != 0 {
+		result |= gl.VERTEX_ATTRIB_ARRAY_BARRIER_BIT
+	}
+	if barrier&ext.BarrierIndex != 0 {
+		result |= gl.ELEMENT_ARRAY_BARRIER_BIT
+	}
+	if barrier&ext.BarrierUniform != 0 {
+		result |= gl.UNIFORM_BARRIER_BIT
+	}
+	if barrier&ext.BarrierTextureFetch != 0 {
+		result |= gl.TEXTURE_FETCH_BARRIER_BIT
+	}
+	if barrier&ext.BarrierImageAccess != 0 {
+		result |= gl.SHADER_IMAGE_ACCESS_BARRIER_BIT
+	}
+	if barrier&ext.BarrierCommand != 0 {
+		result |= gl.COMMAND_BARRIER_BIT
+	}
+	if barrier&ext.BarrierPixelBuffer != 0 {
+		result |= gl.PIXEL_BUFFER_BARRIER_BIT
+	}
+	if barrier&ext.BarrierTextureUpdate != 0 {
+		result |= gl.TEXTURE_UPDATE_BARRIER_BIT
+	}
+	if barrier&ext.BarrierBufferUpdate != 0 {
+		result |= gl.BUFFER_UPDATE_BARRIER_BIT
+	}
+	if barrier&ext.BarrierFramebuffer != 0 {
+		result |= gl.FRAMEBUFFER_BARRIER_BIT
+	}
+	if barrier&ext.BarrierStorageBuffer != 0 {
+		result |= gl.SHADER_STORAGE_BARRIER_BIT
+	}
+	return result
+}
@@ -0,0 +1,171 @@
+package internal
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mokiat/lacking-gl/render/ext"
+)
+
+var (
+	// lineDirectivePattern matches #line directives.
+	lineDirectivePattern = regexp.MustCompile(`^#\s*line\s+(\d+)(?:\s+(\d+))?$`)
+
+	// sourceNamePattern matches the comments that name source strings.
+	sourceNamePattern = regexp.MustCompile(`^//\s*source-string\s+(\d+):\s*(\S.*)$`)
+
+	// mesaPattern matches Mesa messages (e.g. "0:12(5): error: ...").
+	mesaPattern = regexp.MustCompile(`^(\d+):(\d+)\((\d+)\): (error|warning): (.*)$`)
+
+	// nvidiaPattern matches NVIDIA messages
+	// (e.g. "0(12) : error C1008: ...").
+	nvidiaPattern = regexp.MustCompile(`^(\d+)\((\d+)\) : (error|warning)(?: \w+)?: (.*)$`)
+
+	// khronosPattern matches the messages of AMD, Intel and the reference
+	// compiler (e.g. "ERROR: 0:12: ...").
+	khronosPattern = regexp.MustCompile(`^(ERROR|WARNING): (\d+):(\d+): (.*)$`)
+
+	// genericPattern matches messages without a location
+	// (e.g. "error: linking with uncompiled shader").
+	genericPattern = regexp.MustCompile(`^(?i)(error|warning): (.*)$`)
+)
+
+// sourceLocation is a line within a source string.
+type sourceLocation struct {
+	sourceString int
+	line         int
+}
+
+// sourceMap maps the lines of the source code that is passed to the
+// driver to the locations that are declared through #line directives.
+//
+// Drivers are inconsistent in how they report source string numbers
+// (e.g. Mesa always reports zero), so the directives are resolved here
+// instead and removed from the code before it is compiled.
+type sourceMap struct {
+	locations []sourceLocation
+	names     map[int]string
+}
+
+// newSourceMap returns the specified source code, with all #line
+// directives replaced by empty lines, together with a mapping from the
+// resulting lines to the locations that the directives declared.
+func newSourceMap(code string) (string, *sourceMap) {
+	result := &sourceMap{}
+	lines := strings.Split(code, "\n")
+	current := sourceLocation{
+		line: 1,
+	}
+	var hasDirectives bool
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if match := lineDirectivePattern.FindStringSubmatch(trimmed); match != nil {
+			hasDirectives = true
+			lines[i] = ""
+			result.locations = append(result.locations, current)
+			current.line = atoi(match[1])
+			if match[2] != "" {
+				current.sourceString = atoi(match[2])
+			}
+			continue
+		}
+		if match := sourceNamePattern.FindStringSubmatch(trimmed); match != nil {
+			if result.names == nil {
+				result.names = make(map[int]string)
+			}
+			result.names[atoi(match[1])] = strings.TrimSpace(match[2])
+		}
+		result.locations = append(result.locations, current)
+		current.line++
+	}
+	if !hasDirectives {
+		result.locations = nil
+		return code, result
+	}
+	return strings.Join(lines, "\n"), result
+}
+
+// resolve updates the location of the diagnostic to the one that was
+// declared by the #line directives.
+func (m *sourceMap) resolve(diagnostic *ext.Diagnostic) {
+	if m == nil {
+		return
+	}
+	if index := diagnostic.Line - 1; index >= 0 && index < len(m.locations) {
+		location := m.locations[index]
+		diagnostic.SourceString = location.sourceString
+		diagnostic.Line = location.line
+	}
+	diagnostic.File = m.names[diagnostic.SourceString]
+}
+
+// parseInfoLog converts a shader or program info log into diagnostics.
+// Lines that do not match a known format are kept as messages without
+// a location.
+func parseInfoLog(log string, stage ext.ShaderStage, sources *sourceMap) []ext.Diagnostic {
+	var result []ext.Diagnostic
+	for _, line := range strings.Split(log, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		diagnostic := parseInfoLogLine(line)
+		diagnostic.Stage = stage
+		if diagnostic.Line > 0 {
+			sources.resolve(&diagnostic)
+		}
+		result = append(result, diagnostic)
+	}
+	return result
+}
+
+func parseInfoLogLine(line string) ext.Diagnostic {
+	if match := mesaPattern.FindStringSubmatch(line); match != nil {
+		return ext.Diagnostic{
+			Severity:     parseSeverity(match[4]),
+			SourceString: atoi(match[1]),
+			Line:         atoi(match[2]),
+			Column:       atoi(match[3]),
+			Message:      match[5],
+		}
+	}
+	if match := nvidiaPattern.FindStringSubmatch(line); match != nil {
+		return ext.Diagnostic{
+			Severity:     parseSeverity(match[3]),
+			SourceString: atoi(match[1]),
+			Line:         atoi(match[2]),
+			Message:      match[4],
+		}
+	}
+	if match := khronosPattern.FindStringSubmatch(line); match != nil {
+		return ext.Diagnostic{
+			Severity:     parseSeverity(match[1]),
+			SourceString: atoi(match[2]),
+			Line:         atoi(match[3]),
+			Message:      match[4],
+		}
+	}
+	if match := genericPattern.FindStringSubmatch(line); match != nil {
+		return ext.Diagnostic{
+			Severity: parseSeverity(match[1]),
+			Message:  match[2],
+		}
+	}
+	return ext.Diagnostic{
+		Severity: ext.DiagnosticSeverityError,
+		Message:  line,
+	}
+}
+
+func parseSeverity(value string) ext.DiagnosticSeverity {
+	if strings.EqualFold(value, "warning") {
+		return ext.DiagnosticSeverityWarning
+	}
+	return ext.DiagnosticSeverityError
+}
+
+func atoi(value string) int {
+	result, _ := strconv.Atoi(value)
+	return result
+}
@@ -0,0 +1,232 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mokiat/lacking-gl/render/ext"
+)
+
+func TestParseInfoLogLine(t *testing.T) {
+	testCases := []struct {
+		name     string
+		line     string
+		expected ext.Diagnostic
+	}{
+		{
+			name: "mesa error",
+			line: "0:12(5): error: `foo' undeclared",
+			expected: ext.Diagnostic{
+				Severity: ext.DiagnosticSeverityError,
+				Line:     12,
+				Column:   5,
+				Message:  "`foo' undeclared",
+			},
+		},
+		{
+			name: "mesa warning",
+			line: "2:7(18): warning: `color' used uninitialized",
+			expected: ext.Diagnostic{
+				Severity:     ext.DiagnosticSeverityWarning,
+				SourceString: 2,
+				Line:         7,
+				Column:       18,
+				Message:      "`color' used uninitialized",
+			},
+		},
+		{
+			name: "nvidia error with code",
+			line: `0(12) : error C1008: undefined variable "foo"`,
+			expected: ext.Diagnostic{
+				Severity: ext.DiagnosticSeverityError,
+				Line:     12,
+				Message:  `undefined variable "foo"`,
+			},
+		},
+		{
+			name: "nvidia warning with code",
+			line: `3(40) : warning C7050: "color" might be used before being initialized`,
+			expected: ext.Diagnostic{
+				Severity:     ext.DiagnosticSeverityWarning,
+				SourceString: 3,
+				Line:         40,
+				Message:      `"color" might be used before being initialized`,
+			},
+		},
+		{
+			name: "nvidia error without code",
+			line: "1(3) : error: syntax error",
+			expected: ext.Diagnostic{
+				Severity:     ext.DiagnosticSeverityError,
+				SourceString: 1,
+				Line:         3,
+				Message:      "syntax error",
+			},
+		},
+		{
+			name: "khronos error",
+			line: "ERROR: 0:12: 'foo' : undeclared identifier",
+			expected: ext.Diagnostic{
+				Severity: ext.DiagnosticSeverityError,
+				Line:     12,
+				Message:  "'foo' : undeclared identifier",
+			},
+		},
+		{
+			name: "khronos warning",
+			line: "WARNING: 4:2: extension not supported",
+			expected: ext.Diagnostic{
+				Severity:     ext.DiagnosticSeverityWarning,
+				SourceString: 4,
+				Line:         2,
+				Message:      "extension not supported",
+			},
+		},
+		{
+			name: "generic error",
+			line: "error: linking with uncompiled/unspecialized shader",
+			expected: ext.Diagnostic{
+				Severity: ext.DiagnosticSeverityError,
+				Message:  "linking with uncompiled/unspecialized shader",
+			},
+		},
+		{
+			name: "generic warning with capitals",
+			line: "Warning: unused varying",
+			expected: ext.Diagnostic{
+				Severity: ext.DiagnosticSeverityWarning,
+				Message:  "unused varying",
+			},
+		},
+		{
+			name: "unknown format",
+			line: "Vertex info",
+			expected: ext.Diagnostic{
+				Severity: ext.DiagnosticSeverityError,
+				Message:  "Vertex info",
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			actual := parseInfoLogLine(testCase.line)
+			if !reflect.DeepEqual(actual, testCase.expected) {
+				t.Errorf("expected %#v, got %#v", testCase.expected, actual)
+			}
+		})
+	}
+}
+
+func TestNewSourceMap(t *testing.T) {
+	t.Run("without directives", func(t *testing.T) {
+		code := "#version 460\nvoid main() {}\n"
+		actualCode, sources := newSourceMap(code)
+		if actualCode != code {
+			t.Errorf("expected code to be unchanged, got %q", actualCode)
+		}
+		diagnostic := ext.Diagnostic{SourceString: 0, Line: 2}
+		sources.resolve(&diagnostic)
+		if diagnostic.Line != 2 || diagnostic.SourceString != 0 || diagnostic.File != "" {
+			t.Errorf("expected location to be unchanged, got %#v", diagnostic)
+		}
+	})
+
+	t.Run("with directives", func(t *testing.T) {
+		code := "#version 460\n" + // 1
+			"#line 1 1\n" + // 2
+			"uniform float a;\n" + // 3 -> 1:1
+			"uniform float b;\n" + // 4 -> 1:2
+			"  #line 10 2\n" + // 5
+			"void main() {\n" + // 6 -> 2:10
+			"#line 20\n" + // 7
+			"}\n" + // 8 -> 2:20
+			"// source-string 1: common.glsl\n" + // 9 -> 2:21
+			"// source-string 2: main.frag.glsl" // 10 -> 2:22
+
+		expectedCode := "#version 460\n" +
+			"\n" +
+			"uniform float a;\n" +
+			"uniform float b;\n" +
+			"\n" +
+			"void main() {\n" +
+			"\n" +
+			"}\n" +
+			"// source-string 1: common.glsl\n" +
+			"// source-string 2: main.frag.glsl"
+
+		actualCode, sources := newSourceMap(code)
+		if actualCode != expectedCode {
+			t.Errorf("expected code %q, got %q", expectedCode, actualCode)
+		}
+
+		testCases := []struct {
+			line         int
+			sourceString int
+			file         string
+			mappedLine   int
+		}{
+			{line: 1, sourceString: 0, file: "", mappedLine: 1},
+			{line: 3, sourceString: 1, file: "common.glsl", mappedLine: 1},
+			{line: 4, sourceString: 1, file: "common.glsl", mappedLine: 2},
+			{line: 6, sourceString: 2, file: "main.frag.glsl", mappedLine: 10},
+			{line: 8, sourceString: 2, file: "main.frag.glsl", mappedLine: 20},
+			{line: 10, sourceString: 2, file: "main.frag.glsl", mappedLine: 22},
+		}
+		for _, testCase := range testCases {
+			diagnostic := ext.Diagnostic{Line: testCase.line}
+			sources.resolve(&diagnostic)
+			if diagnostic.SourceString != testCase.sourceString || diagnostic.Line != testCase.mappedLine || diagnostic.File != testCase.file {
+				t.Errorf("line %d: expected %s:%d (source-string %d), got %s:%d (source-string %d)",
+					testCase.line,
+					testCase.file, testCase.mappedLine, testCase.sourceString,
+					diagnostic.File, diagnostic.Line, diagnostic.SourceString,
+				)
+			}
+		}
+	})
+}
+
+func TestParseInfoLog(t *testing.T) {
+	code := "#version 460\n" +
+		"#line 1 1\n" +
+		"void main() {\n" +
+		"\tfoo = 1.0;\n" +
+		"}\n" +
+		"// source-string 1: main.frag.glsl"
+	_, sources := newSourceMap(code)
+
+	log := "0:4(2): error: `foo' undeclared\n" +
+		"\n" +
+		"  0:4(2): warning: statement has no effect  \n" +
+		"error: compilation failed\n"
+
+	expected := []ext.Diagnostic{
+		{
+			Stage:        ext.ShaderStageFragment,
+			Severity:     ext.DiagnosticSeverityError,
+			SourceString: 1,
+			File:         "main.frag.glsl",
+			Line:         2,
+			Column:       2,
+			Message:      "`foo' undeclared",
+		},
+		{
+			Stage:        ext.ShaderStageFragment,
+			Severity:     ext.DiagnosticSeverityWarning,
+			SourceString: 1,
+			File:         "main.frag.glsl",
+			Line:         2,
+			Column:       2,
+			Message:      "statement has no effect",
+		},
+		{
+			Stage:    ext.ShaderStageFragment,
+			Severity: ext.DiagnosticSeverityError,
+			Message:  "compilation failed",
+		},
+	}
+	actual := parseInfoLog(log, ext.ShaderStageFragment, sources)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %#v, got %#v", expected, actual)
+	}
+}
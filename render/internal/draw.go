@@ -0,0 +1,18 @@
+package internal
+
+import "github.com/mokiat/lacking-gl/render/ext"
+
+func newCommandMultiDrawIndexedIndirect(info ext.MultiDrawIndexedIndirectInfo) CommandMultiDrawIndexedIndirect {
+	var countBufferID uint32
+	if info.CountBuffer != nil {
+		countBufferID = info.CountBuffer.(*Buffer).id
+	}
+	return CommandMultiDrawIndexedIndirect{
+		BufferID:      info.Buffer.(*Buffer).id,
+		Offset:        uint32(info.Offset),
+		DrawCount:     int32(info.DrawCount),
+		Stride:        int32(info.Stride),
+		CountBufferID: countBufferID,
+		CountOffset:   uint32(info.CountOffset),
+	}
+}
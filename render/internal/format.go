@@ -0,0 +1,143 @@
+package internal
+
+import (
+	"fmt"
+
+	"github.com/go-gl/gl/v4.6-core/gl"
+	"github.com/mokiat/lacking-gl/render/ext"
+	"github.com/mokiat/lacking/render"
+)
+
+// dataFormat describes how a render.DataFormat is stored in textures and
+// how its pixels are transferred.
+type dataFormat struct {
+	internalFormat uint32
+	format         uint32
+	componentType  uint32
+}
+
+var dataFormats = map[render.DataFormat]dataFormat{
+	render.DataFormatRGBA8:   {gl.RGBA8, gl.RGBA, gl.UNSIGNED_BYTE},
+	render.DataFormatRGBA16F: {gl.RGBA16F, gl.RGBA, gl.HALF_FLOAT},
+	render.DataFormatRGBA32F: {gl.RGBA32F, gl.RGBA, gl.FLOAT},
+
+	ext.DataFormatR8:         {gl.R8, gl.RED, gl.UNSIGNED_BYTE},
+	ext.DataFormatRG8:        {gl.RG8, gl.RG, gl.UNSIGNED_BYTE},
+	ext.DataFormatR16F:       {gl.R16F, gl.RED, gl.HALF_FLOAT},
+	ext.DataFormatRG16F:      {gl.RG16F, gl.RG, gl.HALF_FLOAT},
+	ext.DataFormatR32F:       {gl.R32F, gl.RED, gl.FLOAT},
+	ext.DataFormatR11G11B10F: {gl.R11F_G11F_B10F, gl.RGB, gl.UNSIGNED_INT_10F_11F_11F_REV},
+	ext.DataFormatRGB10A2:    {gl.RGB10_A2, gl.RGBA, gl.UNSIGNED_INT_2_10_10_10_REV},
+	ext.DataFormatR32UI:      {gl.R32UI, gl.RED_INTEGER, gl.UNSIGNED_INT},
+
+	ext.DataFormatDepth16:  {gl.DEPTH_COMPONENT16, gl.DEPTH_COMPONENT, gl.UNSIGNED_SHORT},
+	ext.DataFormatDepth24:  {gl.DEPTH_COMPONENT24, gl.DEPTH_COMPONENT, gl.UNSIGNED_INT},
+	ext.DataFormatDepth32F: {gl.DEPTH_COMPONENT32F, gl.DEPTH_COMPONENT, gl.FLOAT},
+}
+
+// The sRGB variants of the S3TC formats come from GL_EXT_texture_sRGB and
+// are not part of the core profile bindings.
+const (
+	glCompressedSRGBAlphaS3TCDXT1 = 0x8C4D
+	glCompressedSRGBAlphaS3TCDXT5 = 0x8C4F
+)
+
+// compressedFormat holds the internal formats of a block-compressed
+// render.DataFormat, without and with gamma correction. Formats that have
+// no sRGB variant use the linear one for both.
+type compressedFormat struct {
+	internalFormat     uint32
+	srgbInternalFormat uint32
+}
+
+var compressedFormats = map[render.DataFormat]compressedFormat{
+	ext.DataFormatBC1:       {gl.COMPRESSED_RGBA_S3TC_DXT1_EXT, glCompressedSRGBAlphaS3TCDXT1},
+	ext.DataFormatBC3:       {gl.COMPRESSED_RGBA_S3TC_DXT5_EXT, glCompressedSRGBAlphaS3TCDXT5},
+	ext.DataFormatBC4:       {gl.COMPRESSED_RED_RGTC1, gl.COMPRESSED_RED_RGTC1},
+	ext.DataFormatBC5:       {gl.COMPRESSED_RG_RGTC2, gl.COMPRESSED_RG_RGTC2},
+	ext.DataFormatBC7:       {gl.COMPRESSED_RGBA_BPTC_UNORM, gl.COMPRESSED_SRGB_ALPHA_BPTC_UNORM},
+	ext.DataFormatETC2RGB8:  {gl.COMPRESSED_RGB8_ETC2, gl.COMPRESSED_SRGB8_ETC2},
+	ext.DataFormatETC2RGBA8: {gl.COMPRESSED_RGBA8_ETC2_EAC, gl.COMPRESSED_SRGB8_ALPHA8_ETC2_EAC},
+}
+
+// glCompressedInternalFormat returns the internal format of the specified
+// block-compressed format.
+func glCompressedInternalFormat(format render.DataFormat, gammaCorrection bool) uint32 {
+	info, ok := compressedFormats[format]
+	if !ok {
+		panic(fmt.Errorf("unsupported compressed format %v", format))
+	}
+	if gammaCorrection {
+		return info.srgbInternalFormat
+	}
+	return info.internalFormat
+}
+
+// lookupDataFormat returns the description of the specified format. It
+// panics if the format is not supported, which includes block-compressed
+// formats, since they are handled separately.
+func lookupDataFormat(format render.DataFormat) dataFormat {
+	result, ok := dataFormats[format]
+	if !ok {
+		if ext.BlockSize(format) > 0 {
+			panic(fmt.Errorf("block-compressed data format %v can only be used with 2D color textures", format))
+		}
+		panic(fmt.Errorf("unsupported data format %v", format))
+	}
+	return result
+}
+
+// textureDataFormat is like lookupDataFormat, except that an unspecified
+// format is treated as render.DataFormatRGBA8.
+func textureDataFormat(format render.DataFormat) dataFormat {
+	if format == render.DataFormatUnsupported {
+		format = render.DataFormatRGBA8
+	}
+	return lookupDataFormat(format)
+}
+
+// isIntegerFormat returns whether the specified format holds integer
+// values that are not normalized. Such textures cannot be filtered.
+func isIntegerFormat(format render.DataFormat) bool {
+	return dataFormats[format].format == gl.RED_INTEGER
+}
+
+// isDepthFormat returns whether the specified format holds depth values.
+func isDepthFormat(format render.DataFormat) bool {
+	return dataFormats[format].format == gl.DEPTH_COMPONENT
+}
+
+func glInternalFormat(format render.DataFormat, gammaCorrection bool) uint32 {
+	if gammaCorrection && format == render.DataFormatRGBA8 {
+		return gl.SRGB8_ALPHA8
+	}
+	return textureDataFormat(format).internalFormat
+}
+
+func glDataFormat(format render.DataFormat) uint32 {
+	return textureDataFormat(format).format
+}
+
+func glDataComponentType(format render.DataFormat) uint32 {
+	return textureDataFormat(format).componentType
+}
+
+func glDataPixelSize(format render.DataFormat) int {
+	size := ext.PixelSize(format)
+	if size == 0 {
+		panic(fmt.Errorf("unsupported data format %v", format))
+	}
+	return size
+}
+
+// contentFormat returns the format that matches the specified pixel
+// transfer format and component type, or render.DataFormatUnsupported if
+// there is none.
+func contentFormat(format, componentType uint32) render.DataFormat {
+	for dataFormat, info := range dataFormats {
+		if info.format == format && info.componentType == componentType && info.format != gl.DEPTH_COMPONENT {
+			return dataFormat
+		}
+	}
+	return render.DataFormatUnsupported
+}
@@ -2,20 +2,31 @@ package internal
 
 import (
 	"github.com/go-gl/gl/v4.6-core/gl"
+	"github.com/mokiat/lacking-gl/render/ext"
 	"github.com/mokiat/lacking/log"
 	"github.com/mokiat/lacking/render"
 )
 
 func NewFramebuffer(info render.FramebufferInfo) *Framebuffer {
+	var extInfo ext.FramebufferInfo
+	for i, attachment := range info.ColorAttachments {
+		extInfo.ColorAttachments[i] = layeredAttachment(attachment)
+	}
+	extInfo.DepthAttachment = layeredAttachment(info.DepthAttachment)
+	extInfo.StencilAttachment = layeredAttachment(info.StencilAttachment)
+	extInfo.DepthStencilAttachment = layeredAttachment(info.DepthStencilAttachment)
+	return NewFramebufferWithAttachments(extInfo)
+}
+
+func NewFramebufferWithAttachments(info ext.FramebufferInfo) *Framebuffer {
 	var id uint32
 	gl.CreateFramebuffers(1, &id)
 
 	var activeDrawBuffers [4]bool
 	var drawBuffers []uint32
 	for i, attachment := range info.ColorAttachments {
-		if colorAttachment, ok := attachment.(*Texture); ok {
-			attachmentID := gl.COLOR_ATTACHMENT0 + uint32(i)
-			gl.NamedFramebufferTexture(id, attachmentID, colorAttachment.id, 0)
+		attachmentID := gl.COLOR_ATTACHMENT0 + uint32(i)
+		if attachTexture(id, attachmentID, attachment) {
 			drawBuffers = append(drawBuffers, attachmentID)
 			activeDrawBuffers[i] = true
 		}
@@ -24,15 +35,9 @@ func NewFramebuffer(info render.FramebufferInfo) *Framebuffer {
 		drawBuffers = append(drawBuffers, gl.NONE)
 	}
 
-	if depthStencilAttachment, ok := info.DepthStencilAttachment.(*Texture); ok {
-		gl.NamedFramebufferTexture(id, gl.DEPTH_STENCIL_ATTACHMENT, depthStencilAttachment.id, 0)
-	} else {
-		if depthAttachment, ok := info.DepthAttachment.(*Texture); ok {
-			gl.NamedFramebufferTexture(id, gl.DEPTH_ATTACHMENT, depthAttachment.id, 0)
-		}
-		if stencilAttachment, ok := info.StencilAttachment.(*Texture); ok {
-			gl.NamedFramebufferTexture(id, gl.STENCIL_ATTACHMENT, stencilAttachment.id, 0)
-		}
+	if !attachTexture(id, gl.DEPTH_STENCIL_ATTACHMENT, info.DepthStencilAttachment) {
+		attachTexture(id, gl.DEPTH_ATTACHMENT, info.DepthAttachment)
+		attachTexture(id, gl.STENCIL_ATTACHMENT, info.StencilAttachment)
 	}
 
 	gl.NamedFramebufferDrawBuffers(id, int32(len(drawBuffers)), &drawBuffers[0])
@@ -48,6 +53,29 @@ func NewFramebuffer(info render.FramebufferInfo) *Framebuffer {
 	}
 }
 
+// NewOffscreenFramebuffer creates a framebuffer that can be used in place
+// of the default one when there is no window surface. It has a single
+// RGBA8 color attachment and a combined depth-stencil attachment.
+func NewOffscreenFramebuffer(width, height int) *Framebuffer {
+	colorTexture := NewColorTexture2D(render.ColorTexture2DInfo{
+		Width:     width,
+		Height:    height,
+		Wrapping:  render.WrapModeClamp,
+		Filtering: render.FilterModeNearest,
+		Format:    render.DataFormatRGBA8,
+	})
+	depthStencilTexture := NewDepthStencilTexture2D(render.DepthStencilTexture2DInfo{
+		Width:  width,
+		Height: height,
+	})
+	return NewFramebuffer(render.FramebufferInfo{
+		ColorAttachments: [4]render.Texture{
+			colorTexture,
+		},
+		DepthStencilAttachment: depthStencilTexture,
+	})
+}
+
 var DefaultFramebuffer = &Framebuffer{
 	id:                0,
 	activeDrawBuffers: [4]bool{true, false, false, false},
@@ -59,12 +87,42 @@ type Framebuffer struct {
 	activeDrawBuffers [4]bool
 }
 
+// ID returns the OpenGL name of this framebuffer.
+func (f *Framebuffer) ID() uint32 {
+	return f.id
+}
+
 func (f *Framebuffer) Release() {
 	gl.DeleteFramebuffers(1, &f.id)
 	f.id = 0
 	f.activeDrawBuffers = [4]bool{}
 }
 
+// layeredAttachment returns an attachment of all layers of the specified
+// texture, which is how the textures of a render.FramebufferInfo are
+// attached.
+func layeredAttachment(texture render.Texture) ext.FramebufferAttachment {
+	return ext.FramebufferAttachment{
+		Texture: texture,
+		Layered: true,
+	}
+}
+
+// attachTexture attaches the specified texture to the framebuffer and
+// returns whether there was a texture to attach.
+func attachTexture(id, attachmentID uint32, attachment ext.FramebufferAttachment) bool {
+	texture, ok := attachment.Texture.(*Texture)
+	if !ok {
+		return false
+	}
+	if attachment.Layered || !texture.layered {
+		gl.NamedFramebufferTexture(id, attachmentID, texture.id, int32(attachment.Level))
+	} else {
+		gl.NamedFramebufferTextureLayer(id, attachmentID, texture.id, int32(attachment.Level), int32(attachment.Layer))
+	}
+	return true
+}
+
 func DetermineContentFormat(framebuffer render.Framebuffer) render.DataFormat {
 	fb := framebuffer.(*Framebuffer)
 	gl.BindFramebuffer(gl.FRAMEBUFFER, fb.id)
@@ -77,23 +135,47 @@ func DetermineContentFormat(framebuffer render.Framebuffer) render.DataFormat {
 		gl.IMPLEMENTATION_COLOR_READ_FORMAT,
 		&glFormat,
 	)
-	if glFormat != gl.RGBA {
-		return render.DataFormatUnsupported
-	}
 	var glType int32
 	gl.GetNamedFramebufferParameteriv(
 		fb.id,
 		gl.IMPLEMENTATION_COLOR_READ_TYPE,
 		&glType,
 	)
-	switch glType {
-	case gl.UNSIGNED_BYTE:
-		return render.DataFormatRGBA8
-	case gl.HALF_FLOAT:
-		return render.DataFormatRGBA16F
-	case gl.FLOAT:
-		return render.DataFormatRGBA32F
-	default:
-		return render.DataFormatUnsupported
+	return contentFormat(uint32(glFormat), uint32(glType))
+}
+
+func newCommandBlitFramebuffer(info ext.BlitFramebufferInfo) CommandBlitFramebuffer {
+	source := info.SourceArea
+	destination := info.DestinationArea
+	if destination.Width == 0 || destination.Height == 0 {
+		destination = source
+	}
+	var mask uint32
+	if info.Color {
+		mask |= gl.COLOR_BUFFER_BIT
+	}
+	if info.Depth {
+		mask |= gl.DEPTH_BUFFER_BIT
+	}
+	if info.Stencil {
+		mask |= gl.STENCIL_BUFFER_BIT
+	}
+	filter := uint32(gl.NEAREST)
+	if info.Filtering == render.FilterModeLinear || info.Filtering == render.FilterModeAnisotropic {
+		filter = gl.LINEAR
+	}
+	return CommandBlitFramebuffer{
+		SourceID:      info.Source.(*Framebuffer).id,
+		DestinationID: info.Destination.(*Framebuffer).id,
+		SourceX0:      int32(source.X),
+		SourceY0:      int32(source.Y),
+		SourceX1:      int32(source.X + source.Width),
+		SourceY1:      int32(source.Y + source.Height),
+		DestinationX0: int32(destination.X),
+		DestinationY0: int32(destination.Y),
+		DestinationX1: int32(destination.X + destination.Width),
+		DestinationY1: int32(destination.Y + destination.Height),
+		Mask:          mask,
+		Filter:        filter,
 	}
 }
@@ -4,15 +4,23 @@ import (
 	"fmt"
 
 	"github.com/go-gl/gl/v4.6-core/gl"
+	"github.com/mokiat/lacking-gl/render/ext"
 	"github.com/mokiat/lacking/render"
 )
 
-func NewPipeline(info render.PipelineInfo) *Pipeline {
+func NewPipeline(info render.PipelineInfo, capabilities ext.Capabilities) *Pipeline {
+	return NewExtendedPipeline(ext.PipelineInfo{
+		PipelineInfo: info,
+	}, capabilities)
+}
+
+func NewExtendedPipeline(extInfo ext.PipelineInfo, capabilities ext.Capabilities) *Pipeline {
+	info := extInfo.PipelineInfo
 	intProgram := info.Program.(*Program)
 	intVertexArray := info.VertexArray.(*VertexArray)
 
 	pipeline := &Pipeline{
-		ProgramID: intProgram.id,
+		Program: intProgram,
 		VertexArray: CommandBindVertexArray{
 			VertexArrayID: intVertexArray.id,
 			IndexFormat:   intVertexArray.indexFormat,
@@ -34,6 +42,12 @@ func NewPipeline(info render.PipelineInfo) *Pipeline {
 		pipeline.Topology.Topology = gl.TRIANGLE_FAN
 	case render.TopologyTriangles:
 		pipeline.Topology.Topology = gl.TRIANGLES
+	case ext.TopologyPatches:
+		if extInfo.PatchVertices < 1 || extInfo.PatchVertices > capabilities.MaxPatchVertices {
+			panic(fmt.Errorf("patch vertices %d outside of supported range [1, %d]", extInfo.PatchVertices, capabilities.MaxPatchVertices))
+		}
+		pipeline.Topology.Topology = gl.PATCHES
+		pipeline.Topology.PatchVertices = int32(extInfo.PatchVertices)
 	}
 
 	switch info.Culling {
@@ -102,6 +116,8 @@ func NewPipeline(info render.PipelineInfo) *Pipeline {
 	pipeline.BlendFunc.SourceFactorAlpha = glEnumFromBlendFactor(info.BlendSourceAlphaFactor)
 	pipeline.BlendFunc.DestinationFactorAlpha = glEnumFromBlendFactor(info.BlendDestinationAlphaFactor)
 
+	pipeline.AlphaToCoverage = extInfo.AlphaToCoverage
+
 	return pipeline
 }
 
@@ -207,7 +223,9 @@ func glEnumFromBlendFactor(factor render.BlendFactor) uint32 {
 
 type Pipeline struct {
 	render.PipelineObject
-	ProgramID        uint32
+	// Program is referenced instead of its ID, since programs can be
+	// rebuilt in place (see Program.Rebuild).
+	Program          *Program
 	Topology         CommandTopology
 	CullTest         CommandCullTest
 	FrontFace        CommandFrontFace
@@ -226,6 +244,7 @@ type Pipeline struct {
 	BlendColor       CommandBlendColor
 	BlendEquation    CommandBlendEquation
 	BlendFunc        CommandBlendFunc
+	AlphaToCoverage  bool
 	VertexArray      CommandBindVertexArray
 }
 
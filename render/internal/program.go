@@ -6,25 +6,40 @@ import (
 	"strings"
 
 	"github.com/go-gl/gl/v4.6-core/gl"
-	"github.com/mokiat/lacking/log"
+	"github.com/mokiat/lacking-gl/render/ext"
 	"github.com/mokiat/lacking/render"
 )
 
-func NewProgram(info render.ProgramInfo) *Program {
+// NewProgram creates a program from the shaders of the specified info.
+// When async is true, the program is linked in the background and its
+// results are collected once Status reports it as ready or Wait is
+// called. This requires parallel shader compilation support.
+func NewProgram(info render.ProgramInfo, cache *ProgramCache, async bool) *Program {
+	return NewGraphicsProgram(ext.GraphicsProgramInfo{
+		VertexShader:   info.VertexShader,
+		FragmentShader: info.FragmentShader,
+	}, cache, async)
+}
+
+// NewGraphicsProgram creates a program from the shaders of the specified
+// info, including any geometry and tessellation ones. See NewProgram.
+func NewGraphicsProgram(info ext.GraphicsProgramInfo, cache *ProgramCache, async bool) *Program {
 	program := &Program{
 		id: gl.CreateProgram(),
 	}
-	if vertexShader, ok := info.VertexShader.(*Shader); ok {
-		gl.AttachShader(program.id, vertexShader.id)
-		defer gl.DetachShader(program.id, vertexShader.id)
-	}
-	if fragmentShader, ok := info.FragmentShader.(*Shader); ok {
-		gl.AttachShader(program.id, fragmentShader.id)
-		defer gl.DetachShader(program.id, fragmentShader.id)
-	}
-	if err := program.link(); err != nil {
-		log.Error("Program link error: %v", err)
+	var shaders []*Shader
+	for _, shader := range []render.Shader{
+		info.VertexShader,
+		info.TessControlShader,
+		info.TessEvaluationShader,
+		info.GeometryShader,
+		info.FragmentShader,
+	} {
+		if intShader, ok := shader.(*Shader); ok {
+			shaders = append(shaders, intShader)
+		}
 	}
+	program.build(shaders, cache, async)
 	// NOTE: Texture bindings are to be done in GLSL through
 	// `layout(binding = 2) uniform ...`.
 	// NOTE: Buffer bindings are to be done in GLSL through
@@ -32,29 +47,326 @@ func NewProgram(info render.ProgramInfo) *Program {
 	return program
 }
 
+func NewComputeProgram(info ext.ComputeProgramInfo, cache *ProgramCache, async bool) *Program {
+	program := &Program{
+		id: gl.CreateProgram(),
+	}
+	var shaders []*Shader
+	if computeShader, ok := info.ComputeShader.(*Shader); ok {
+		shaders = append(shaders, computeShader)
+	}
+	program.build(shaders, cache, async)
+	return program
+}
+
 type Program struct {
 	render.ProgramObject
-	id uint32
+	id          uint32
+	diagnostics []ext.Diagnostic
+	err         error
+
+	sources []ShaderSource
+
+	linking    bool
+	shaders    []*Shader
+	shaderIDs  []uint32
+	cache      *ProgramCache
+	cacheKey   string
+	onComplete func()
+
+	// uniforms holds the locations that have been handed out, by name.
+	// They remain valid when the program is rebuilt.
+	uniforms map[string]int32
+
+	// locations maps the handed out locations to the ones of the current
+	// version of the program. It is nil while they are the same, which
+	// is the case until a rebuild moves any of them.
+	locations map[int32]int32
+
+	// renderer is the renderer that translates the locations once a
+	// rebuild has moved them.
+	renderer *Renderer
+}
+
+// ID returns the OpenGL name of this program.
+func (p *Program) ID() uint32 {
+	return p.id
 }
 
 func (p *Program) UniformLocation(name string) render.UniformLocation {
+	p.Wait()
+	if location, ok := p.uniforms[name]; ok {
+		return location
+	}
+	return p.trackUniform(name, glUniformLocation(p.id, name))
+}
+
+// trackUniform records that the location of the uniform with the specified
+// name, which has the specified location in the current version of the
+// program, is handed out and returns the location to hand out. The two
+// are the same unless the program has been rebuilt and the location is
+// already taken by a different uniform.
+func (p *Program) trackUniform(name string, location int32) int32 {
+	if location < 0 {
+		return location
+	}
+	if p.uniforms == nil {
+		p.uniforms = make(map[string]int32)
+	}
+	result := location
+	if p.locations != nil {
+		if _, taken := p.locations[result]; taken {
+			// Locations beyond the ones that drivers support are used for
+			// uniforms whose location is taken by a different uniform of
+			// an earlier version.
+			result = 1 << 16
+			for _, taken := p.locations[result]; taken; _, taken = p.locations[result] {
+				result++
+			}
+		}
+		p.locations[result] = location
+	}
+	p.uniforms[name] = result
+	return result
+}
+
+// uniformLocation returns the location to hand out for the uniform with
+// the specified name and location in the current version of the program.
+func (p *Program) uniformLocation(name string, location int32) int32 {
+	if result, ok := p.uniforms[name]; ok {
+		return result
+	}
+	return p.trackUniform(name, location)
+}
+
+func glUniformLocation(program uint32, name string) int32 {
 	nullTerminatedName := name + "\x00"
-	result := gl.GetUniformLocation(p.id, gl.Str(nullTerminatedName))
+	result := gl.GetUniformLocation(program, gl.Str(nullTerminatedName))
 	runtime.KeepAlive(nullTerminatedName)
 	return result
 }
 
+// Diagnostics returns the messages that were reported by the driver
+// during linking, including warnings. It waits for the program to be
+// linked.
+func (p *Program) Diagnostics() []ext.Diagnostic {
+	p.Wait()
+	return p.diagnostics
+}
+
+// Err returns an *ext.ShaderError if the program failed to link. It
+// waits for the program to be linked.
+func (p *Program) Err() error {
+	p.Wait()
+	return p.err
+}
+
+// Linking returns whether the program is being linked in the background
+// and its results have not been collected yet.
+func (p *Program) Linking() bool {
+	return p.linking
+}
+
+// SetCompleteCallback specifies a function that is called once the
+// results of a background link have been collected.
+func (p *Program) SetCompleteCallback(callback func()) {
+	p.onComplete = callback
+}
+
+// Status returns render.FenceStatusSuccess if the program is ready to be
+// used, in which case its results are collected, and
+// render.FenceStatusNotReady if it is still being compiled and linked.
+// A ready program may still have failed to link (see Err).
+func (p *Program) Status() render.FenceStatus {
+	if !p.linking {
+		return render.FenceStatusSuccess
+	}
+	var status int32
+	gl.GetProgramiv(p.id, gl.COMPLETION_STATUS_KHR, &status)
+	if status == gl.FALSE {
+		return render.FenceStatusNotReady
+	}
+	p.finish()
+	return render.FenceStatusSuccess
+}
+
+// Wait blocks until the program has been linked and collects the results.
+func (p *Program) Wait() {
+	if p.linking {
+		p.finish()
+	}
+}
+
+// Sources returns the source code of the shaders that the program was
+// built from. The returned slice must not be modified.
+func (p *Program) Sources() []ShaderSource {
+	return p.sources
+}
+
+// Rebuild compiles and links a new version of the program from the
+// specified sources. If that succeeds, the new version replaces the
+// current one in place, so that pipelines that use the program pick it
+// up. Otherwise, the current version is kept. The diagnostics of the new
+// version are returned either way.
+//
+// Uniform locations that have been handed out remain valid, since the
+// specified renderer translates them to the ones of the new version.
+func (p *Program) Rebuild(sources []ShaderSource, cache *ProgramCache, renderer *Renderer) ([]ext.Diagnostic, error) {
+	p.Wait()
+
+	shaders := make([]*Shader, len(sources))
+	for i, source := range sources {
+		if source.spirv != nil {
+			shaders[i] = NewSPIRVShader(source.spirv.info, source.spirv.capabilities)
+		} else {
+			shaders[i] = NewShader(source.Stage, render.ShaderInfo{
+				SourceCode: source.Code,
+			})
+		}
+		defer shaders[i].Release()
+	}
+	replacement := &Program{
+		id: gl.CreateProgram(),
+	}
+	replacement.build(shaders, cache, false)
+
+	var diagnostics []ext.Diagnostic
+	var errs []error
+	for _, shader := range shaders {
+		// Shaders are not compiled when the program is loaded from cache.
+		if shader.Compiled() {
+			diagnostics = append(diagnostics, shader.Diagnostics()...)
+			errs = append(errs, shader.Err())
+		}
+	}
+	diagnostics = append(diagnostics, replacement.diagnostics...)
+	errs = append(errs, replacement.err)
+	if err := errors.Join(errs...); err != nil {
+		replacement.Release()
+		return diagnostics, err
+	}
+
+	p.renderer = renderer
+	p.updateLocations(replacement.id)
+	gl.DeleteProgram(p.id)
+	p.id = replacement.id
+	p.sources = replacement.sources
+	p.diagnostics = replacement.diagnostics
+	p.err = nil
+	p.registerLocations()
+	return diagnostics, nil
+}
+
+// updateLocations looks up the handed out uniforms in the specified new
+// version of the program.
+func (p *Program) updateLocations(id uint32) {
+	locations := make(map[int32]int32, len(p.uniforms))
+	moved := false
+	for name, location := range p.uniforms {
+		newLocation := glUniformLocation(id, name)
+		locations[location] = newLocation
+		moved = moved || newLocation != location
+	}
+	if !moved {
+		locations = nil
+	}
+	if p.renderer != nil {
+		delete(p.renderer.remappedPrograms, p.id)
+	}
+	p.locations = locations
+}
+
+func (p *Program) registerLocations() {
+	if p.renderer != nil && p.locations != nil {
+		p.renderer.remappedPrograms[p.id] = p.locations
+	}
+}
+
 func (p *Program) Release() {
+	// NOTE: Deleting the program detaches any shaders that are still
+	// attached to it.
+	p.linking = false
+	p.shaders = nil
+	p.onComplete = nil
+	if p.renderer != nil {
+		delete(p.renderer.remappedPrograms, p.id)
+	}
 	gl.DeleteProgram(p.id)
 	p.id = 0
 }
 
-func (p *Program) link() error {
+// build restores the program from the cache or, if that is not possible,
+// compiles and links the specified shaders. The cache may be nil.
+//
+// When async is true, the compilation and linking are only started and
+// the results are collected later on through finish.
+func (p *Program) build(shaders []*Shader, cache *ProgramCache, async bool) {
+	p.sources = make([]ShaderSource, len(shaders))
+	for i, shader := range shaders {
+		p.sources[i] = ShaderSource{
+			Stage: shader.stage,
+			Code:  shader.sourceCode,
+			spirv: shader.spirv,
+		}
+	}
+	if cache != nil {
+		p.cache = cache
+		p.cacheKey = cache.key(shaders)
+		if cache.load(p.id, p.cacheKey) {
+			return
+		}
+		gl.ProgramParameteri(p.id, gl.PROGRAM_BINARY_RETRIEVABLE_HINT, gl.TRUE)
+	}
+	p.shaders = shaders
+	p.shaderIDs = make([]uint32, len(shaders))
+	for i, shader := range shaders {
+		shader.CompileAsync()
+		p.shaderIDs[i] = shader.id
+		gl.AttachShader(p.id, shader.id)
+	}
 	gl.LinkProgram(p.id)
+	p.linking = true
+	if !async {
+		p.finish()
+	}
+}
+
+// finish collects the results of the shader compilations and the program
+// link, which blocks until they are available.
+func (p *Program) finish() {
+	p.linking = false
+	for _, shader := range p.shaders {
+		shader.Compile()
+	}
+	p.link()
+	for i, shader := range p.shaders {
+		gl.DetachShader(p.id, p.shaderIDs[i])
+		if shader.released {
+			shader.id = 0
+		}
+	}
+	p.shaders = nil
+	p.shaderIDs = nil
+	if p.cache != nil && p.err == nil {
+		p.cache.store(p.id, p.cacheKey)
+	}
+	p.cache = nil
+	if callback := p.onComplete; callback != nil {
+		p.onComplete = nil
+		callback()
+	}
+}
+
+func (p *Program) link() {
+	infoLog := p.getInfoLog()
+	p.diagnostics = parseInfoLog(infoLog, ext.ShaderStageUnknown, nil)
 	if !p.isLinkSuccessful() {
-		return errors.New(p.getInfoLog())
+		p.err = &ext.ShaderError{
+			Link:        true,
+			Diagnostics: p.diagnostics,
+			Log:         infoLog,
+		}
 	}
-	return nil
 }
 
 func (p *Program) isLinkSuccessful() bool {
@@ -70,5 +382,18 @@ func (p *Program) getInfoLog() string {
 	log := strings.Repeat("\x00", int(logLength+1))
 	gl.GetProgramInfoLog(p.id, logLength, nil, gl.Str(log))
 	runtime.KeepAlive(log)
-	return log
+	return strings.TrimRight(log, "\x00")
+}
+
+// ShaderSource is the source code of a single stage of a program.
+type ShaderSource struct {
+	Stage ext.ShaderStage
+	Code  string
+	spirv *spirvShader
+}
+
+// IsSPIRV returns whether the shader was created from a SPIR-V binary,
+// in which case Code holds the binary and cannot be replaced.
+func (s ShaderSource) IsSPIRV() bool {
+	return s.spirv != nil
 }
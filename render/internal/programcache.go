@@ -0,0 +1,92 @@
+package internal
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/go-gl/gl/v4.6-core/gl"
+	"github.com/mokiat/lacking-gl/render/ext"
+)
+
+// programCacheVersion is part of every key, so that entries written by an
+// incompatible earlier implementation are never used.
+const programCacheVersion = "lacking-gl program cache v1"
+
+// binaryFormatSize is the size of the binary format that precedes the
+// program binary in the cached data.
+const binaryFormatSize = 4
+
+func NewProgramCache(cache ext.ProgramCache, capabilities ext.Capabilities) *ProgramCache {
+	return &ProgramCache{
+		cache: cache,
+		driver: fmt.Sprintf("%s\x00%s\x00%s",
+			capabilities.Vendor,
+			capabilities.Renderer,
+			capabilities.VersionString,
+		),
+	}
+}
+
+// ProgramCache stores and restores program binaries through an
+// ext.ProgramCache.
+type ProgramCache struct {
+	cache  ext.ProgramCache
+	driver string
+}
+
+// key returns the cache key of a program that consists of the specified
+// shaders.
+func (c *ProgramCache) key(shaders []*Shader) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\x00%s\x00", programCacheVersion, c.driver)
+	for _, shader := range shaders {
+		fmt.Fprintf(hash, "%d\x00%d\x00%s\x00", shader.stage, len(shader.sourceCode), shader.sourceCode)
+		if shader.spirv != nil {
+			fmt.Fprintf(hash, "%s\x00%v\x00", shader.spirv.info.EntryPoint, shader.spirv.info.Constants)
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// load restores the program binary that is stored under the specified key
+// into the program. It returns false if there is no such binary or if the
+// driver rejected it, in which case the program needs to be linked.
+func (c *ProgramCache) load(programID uint32, key string) bool {
+	data, ok := c.cache.Load(key)
+	if !ok {
+		return false
+	}
+	if len(data) <= binaryFormatSize {
+		c.cache.Remove(key)
+		return false
+	}
+	format := binary.LittleEndian.Uint32(data)
+	program := data[binaryFormatSize:]
+	gl.ProgramBinary(programID, format, gl.Ptr(&program[0]), int32(len(program)))
+
+	var status int32
+	gl.GetProgramiv(programID, gl.LINK_STATUS, &status)
+	if status == gl.FALSE {
+		// The binary is likely from an incompatible driver build that
+		// reports the same version.
+		c.cache.Remove(key)
+		return false
+	}
+	return true
+}
+
+// store saves the binary of the linked program under the specified key.
+func (c *ProgramCache) store(programID uint32, key string) {
+	var length int32
+	gl.GetProgramiv(programID, gl.PROGRAM_BINARY_LENGTH, &length)
+	if length <= 0 {
+		return
+	}
+	data := make([]byte, binaryFormatSize+int(length))
+	var format uint32
+	gl.GetProgramBinary(programID, length, &length, &format, gl.Ptr(&data[binaryFormatSize]))
+	binary.LittleEndian.PutUint32(data, format)
+	c.cache.Store(key, data[:binaryFormatSize+int(length)])
+}
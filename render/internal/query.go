@@ -0,0 +1,141 @@
+package internal
+
+import (
+	"fmt"
+
+	"github.com/go-gl/gl/v4.6-core/gl"
+	"github.com/mokiat/lacking-gl/render/ext"
+)
+
+func NewQuery(info ext.QueryInfo) *Query {
+	target := glQueryTarget(info.Kind)
+	// Some drivers (e.g. Mesa) produce no results for pipeline statistics
+	// queries created through CreateQueries, so the object is instead
+	// created by the first BeginQuery or QueryCounter call.
+	var id uint32
+	gl.GenQueries(1, &id)
+	return &Query{
+		id:     id,
+		kind:   info.Kind,
+		target: target,
+	}
+}
+
+type Query struct {
+	id     uint32
+	kind   ext.QueryKind
+	target uint32
+
+	// state tracks the progress of the last measurement, since OpenGL does
+	// not allow the availability of a query to be checked before a
+	// measurement has been made or while it is in progress.
+	state queryState
+
+	// renderer is the renderer that updates the state once the commands
+	// of the measurement are executed.
+	renderer *Renderer
+}
+
+type queryState uint8
+
+const (
+	// queryStateIdle indicates that no measurement has been requested.
+	queryStateIdle queryState = iota
+
+	// queryStateRecorded indicates that a measurement has been requested
+	// but that its commands have not been executed yet.
+	queryStateRecorded
+
+	// queryStatePending indicates that the measurement has begun but has
+	// not ended yet.
+	queryStatePending
+
+	// queryStateEnded indicates that the measurement has ended, so its
+	// result becomes available eventually.
+	queryStateEnded
+)
+
+// ID returns the OpenGL name of this query.
+func (q *Query) ID() uint32 {
+	return q.id
+}
+
+func (q *Query) Kind() ext.QueryKind {
+	return q.kind
+}
+
+func (q *Query) Available() bool {
+	if q.state != queryStateEnded {
+		return false
+	}
+	var available int32
+	gl.GetQueryObjectiv(q.id, gl.QUERY_RESULT_AVAILABLE, &available)
+	return available != gl.FALSE
+}
+
+func (q *Query) Result() uint64 {
+	var result uint64
+	gl.GetQueryObjectui64v(q.id, gl.QUERY_RESULT, &result)
+	return result
+}
+
+func (q *Query) Poll() (uint64, bool) {
+	if !q.Available() {
+		return 0, false
+	}
+	return q.Result(), true
+}
+
+func (q *Query) Release() {
+	if q.renderer != nil {
+		delete(q.renderer.queries, q.id)
+	}
+	gl.DeleteQueries(1, &q.id)
+	q.id = 0
+}
+
+func newCommandWriteTimestamp(query ext.Query) CommandWriteTimestamp {
+	intQuery := query.(*Query)
+	intQuery.state = queryStateRecorded
+	return CommandWriteTimestamp{
+		QueryID: intQuery.id,
+	}
+}
+
+func newCommandBeginQuery(query ext.Query) CommandBeginQuery {
+	intQuery := query.(*Query)
+	intQuery.state = queryStateRecorded
+	return CommandBeginQuery{
+		QueryID: intQuery.id,
+		Target:  intQuery.target,
+	}
+}
+
+func newCommandEndQuery(query ext.Query) CommandEndQuery {
+	intQuery := query.(*Query)
+	return CommandEndQuery{
+		QueryID: intQuery.id,
+		Target:  intQuery.target,
+	}
+}
+
+func glQueryTarget(kind ext.QueryKind) uint32 {
+	switch kind {
+	case ext.QueryKindTimestamp:
+		return gl.TIMESTAMP
+	case ext.QueryKindTimeElapsed:
+		return gl.TIME_ELAPSED
+	case ext.QueryKindAnySamplesPassed:
+		return gl.ANY_SAMPLES_PASSED
+	case ext.QueryKindSamplesPassed:
+		return gl.SAMPLES_PASSED
+	case ext.QueryKindVerticesSubmitted:
+		return gl.VERTICES_SUBMITTED
+	case ext.QueryKindPrimitivesSubmitted:
+		return gl.PRIMITIVES_SUBMITTED
+	case ext.QueryKindFragmentShaderInvocations:
+		return gl.FRAGMENT_SHADER_INVOCATIONS
+	default:
+		panic(fmt.Errorf("unknown query kind: %d", kind))
+	}
+}
@@ -0,0 +1,110 @@
+package internal
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/go-gl/gl/v4.6-core/gl"
+	"github.com/mokiat/lacking-gl/render/ext"
+)
+
+// Reflection queries the active interface of the program through the
+// program interface queries. It waits for the program to be linked.
+func (p *Program) Reflection() ext.ProgramReflection {
+	p.Wait()
+
+	var result ext.ProgramReflection
+
+	blockCount := p.resourceCount(gl.UNIFORM_BLOCK)
+	result.UniformBlocks = make([]ext.UniformBlockInfo, blockCount)
+	for i := range result.UniformBlocks {
+		values := p.resourceProperties(gl.UNIFORM_BLOCK, i, gl.BUFFER_BINDING, gl.BUFFER_DATA_SIZE)
+		result.UniformBlocks[i] = ext.UniformBlockInfo{
+			Name:    p.resourceName(gl.UNIFORM_BLOCK, i),
+			Binding: int(values[0]),
+			Size:    int(values[1]),
+		}
+	}
+
+	for i := 0; i < p.resourceCount(gl.UNIFORM); i++ {
+		values := p.resourceProperties(gl.UNIFORM, i,
+			gl.TYPE, gl.ARRAY_SIZE, gl.LOCATION, gl.BLOCK_INDEX,
+			gl.OFFSET, gl.ARRAY_STRIDE, gl.MATRIX_STRIDE,
+		)
+		name := p.resourceName(gl.UNIFORM, i)
+		variableType := ext.VariableType(values[0])
+		if blockIndex := int(values[3]); blockIndex >= 0 && blockIndex < blockCount {
+			block := &result.UniformBlocks[blockIndex]
+			block.Members = append(block.Members, ext.BlockMemberInfo{
+				Name:         name,
+				Type:         variableType,
+				ArraySize:    int(values[1]),
+				Offset:       int(values[4]),
+				ArrayStride:  int(values[5]),
+				MatrixStride: int(values[6]),
+			})
+			continue
+		}
+		if values[2] < 0 {
+			// Atomic counters and storage block members have no location.
+			continue
+		}
+		uniform := ext.UniformInfo{
+			Name:      name,
+			Type:      variableType,
+			ArraySize: int(values[1]),
+			Location:  int(p.uniformLocation(name, values[2])),
+			Binding:   -1,
+		}
+		if variableType.IsSampler() || variableType.IsImage() {
+			var binding int32
+			gl.GetUniformiv(p.id, values[2], &binding)
+			uniform.Binding = int(binding)
+		}
+		result.Uniforms = append(result.Uniforms, uniform)
+	}
+
+	for i := range result.UniformBlocks {
+		slices.SortFunc(result.UniformBlocks[i].Members, func(a, b ext.BlockMemberInfo) int {
+			return a.Offset - b.Offset
+		})
+	}
+
+	for i := 0; i < p.resourceCount(gl.PROGRAM_INPUT); i++ {
+		name := p.resourceName(gl.PROGRAM_INPUT, i)
+		if strings.HasPrefix(name, "gl_") {
+			continue
+		}
+		values := p.resourceProperties(gl.PROGRAM_INPUT, i, gl.TYPE, gl.ARRAY_SIZE, gl.LOCATION)
+		result.Attributes = append(result.Attributes, ext.AttributeInfo{
+			Name:      name,
+			Type:      ext.VariableType(values[0]),
+			ArraySize: int(values[1]),
+			Location:  int(values[2]),
+		})
+	}
+
+	return result
+}
+
+func (p *Program) resourceCount(programInterface uint32) int {
+	var count int32
+	gl.GetProgramInterfaceiv(p.id, programInterface, gl.ACTIVE_RESOURCES, &count)
+	return int(count)
+}
+
+func (p *Program) resourceProperties(programInterface uint32, index int, props ...uint32) []int32 {
+	values := make([]int32, len(props))
+	gl.GetProgramResourceiv(p.id, programInterface, uint32(index), int32(len(props)), &props[0], int32(len(values)), nil, &values[0])
+	return values
+}
+
+func (p *Program) resourceName(programInterface uint32, index int) string {
+	length := p.resourceProperties(programInterface, index, gl.NAME_LENGTH)[0]
+	if length <= 0 {
+		return ""
+	}
+	name := make([]uint8, length)
+	gl.GetProgramResourceName(p.id, programInterface, uint32(index), length, nil, &name[0])
+	return gl.GoStr(&name[0])
+}
@@ -0,0 +1,169 @@
+package internal
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// NewIDRemapper creates a new IDRemapper with empty mappings.
+func NewIDRemapper() *IDRemapper {
+	return &IDRemapper{
+		Programs:         make(map[uint32]uint32),
+		Buffers:          make(map[uint32]uint32),
+		Textures:         make(map[uint32]uint32),
+		VertexArrays:     make(map[uint32]uint32),
+		UniformLocations: make(map[uint32]map[int32]int32),
+	}
+}
+
+// IDRemapper translates the OpenGL object names and uniform locations
+// that are referenced by queued commands. Names that are not present
+// in a mapping are left unchanged.
+//
+// Only the commands of render.CommandQueue are remapped. The
+// OpenGL-specific commands of ext.CommandQueue are skipped, since the
+// Recorder does not expose them and captures cannot contain them.
+type IDRemapper struct {
+	Programs     map[uint32]uint32
+	Buffers      map[uint32]uint32
+	Textures     map[uint32]uint32
+	VertexArrays map[uint32]uint32
+
+	// UniformLocations maps uniform locations per program, where
+	// programs are identified by their original name.
+	UniformLocations map[uint32]map[int32]int32
+
+	program uint32
+}
+
+// CommandData returns the raw bytes of the commands that are pending
+// in the specified queue.
+func CommandData(queue *CommandQueue) []byte {
+	return queue.data[queue.readOffset:queue.writeOffset]
+}
+
+// Remap rewrites, in place, all OpenGL object names and uniform locations
+// that are referenced by the pending commands in the specified queue.
+//
+// The last bound program is tracked across calls, since uniform commands
+// apply to the program of a pipeline that may have been bound by an
+// earlier queue.
+func (m *IDRemapper) Remap(queue *CommandQueue) {
+	offset := queue.readOffset
+	for offset < queue.writeOffset {
+		header := peekCommand[CommandHeader](queue, &offset)
+		switch header.Kind {
+		case CommandKindBindPipeline:
+			command := peekCommand[CommandBindPipeline](queue, &offset)
+			m.program = command.ProgramID
+			command.ProgramID = remapID(m.Programs, command.ProgramID)
+			command.VertexArray.VertexArrayID = remapID(m.VertexArrays, command.VertexArray.VertexArrayID)
+		case CommandKindTopology:
+			peekCommand[CommandTopology](queue, &offset)
+		case CommandKindCullTest:
+			peekCommand[CommandCullTest](queue, &offset)
+		case CommandKindFrontFace:
+			peekCommand[CommandFrontFace](queue, &offset)
+		case CommandKindDepthTest:
+			peekCommand[CommandDepthTest](queue, &offset)
+		case CommandKindDepthWrite:
+			peekCommand[CommandDepthWrite](queue, &offset)
+		case CommandKindDepthComparison:
+			peekCommand[CommandDepthComparison](queue, &offset)
+		case CommandKindUniform1f:
+			command := peekCommand[CommandUniform1f](queue, &offset)
+			command.Location = m.remapLocation(command.Location)
+		case CommandKindUniform1i:
+			command := peekCommand[CommandUniform1i](queue, &offset)
+			command.Location = m.remapLocation(command.Location)
+		case CommandKindUniform3f:
+			command := peekCommand[CommandUniform3f](queue, &offset)
+			command.Location = m.remapLocation(command.Location)
+		case CommandKindUniform4f:
+			command := peekCommand[CommandUniform4f](queue, &offset)
+			command.Location = m.remapLocation(command.Location)
+		case CommandKindUniformMatrix4f:
+			command := peekCommand[CommandUniformMatrix4f](queue, &offset)
+			command.Location = m.remapLocation(command.Location)
+		case CommandKindUniformBufferUnit:
+			command := peekCommand[CommandUniformBufferUnit](queue, &offset)
+			command.BufferID = remapID(m.Buffers, command.BufferID)
+		case CommandKindUniformBufferUnitRange:
+			command := peekCommand[CommandUniformBufferUnitRange](queue, &offset)
+			command.BufferID = remapID(m.Buffers, command.BufferID)
+		case CommandKindTextureUnit:
+			command := peekCommand[CommandTextureUnit](queue, &offset)
+			command.TextureID = remapID(m.Textures, command.TextureID)
+		case CommandKindDraw:
+			peekCommand[CommandDraw](queue, &offset)
+		case CommandKindDrawIndexed:
+			peekCommand[CommandDrawIndexed](queue, &offset)
+		case CommandKindCopyContentToBuffer:
+			command := peekCommand[CommandCopyContentToBuffer](queue, &offset)
+			command.BufferID = remapID(m.Buffers, command.BufferID)
+		case CommandKindUpdateBufferData:
+			command := peekCommand[CommandUpdateBufferData](queue, &offset)
+			command.BufferID = remapID(m.Buffers, command.BufferID)
+			offset += uintptr(command.Count)
+		case CommandKindBindProgram:
+			peekCommand[CommandBindProgram](queue, &offset)
+		case CommandKindStorageBufferUnit:
+			peekCommand[CommandStorageBufferUnit](queue, &offset)
+		case CommandKindStorageBufferUnitRange:
+			peekCommand[CommandStorageBufferUnitRange](queue, &offset)
+		case CommandKindImageUnit:
+			peekCommand[CommandImageUnit](queue, &offset)
+		case CommandKindDispatch:
+			peekCommand[CommandDispatch](queue, &offset)
+		case CommandKindDispatchIndirect:
+			peekCommand[CommandDispatchIndirect](queue, &offset)
+		case CommandKindMemoryBarrier:
+			peekCommand[CommandMemoryBarrier](queue, &offset)
+		case CommandKindDrawIndirect:
+			peekCommand[CommandDrawIndirect](queue, &offset)
+		case CommandKindDrawIndexedIndirect:
+			peekCommand[CommandDrawIndexedIndirect](queue, &offset)
+		case CommandKindMultiDrawIndexedIndirect:
+			peekCommand[CommandMultiDrawIndexedIndirect](queue, &offset)
+		case CommandKindWriteTimestamp:
+			peekCommand[CommandWriteTimestamp](queue, &offset)
+		case CommandKindBeginQuery:
+			peekCommand[CommandBeginQuery](queue, &offset)
+		case CommandKindEndQuery:
+			peekCommand[CommandEndQuery](queue, &offset)
+		case CommandKindUpdateTextureData:
+			command := peekCommand[CommandUpdateTextureData](queue, &offset)
+			offset += uintptr(command.Count)
+		case CommandKindGenerateTextureMipmaps:
+			peekCommand[CommandGenerateTextureMipmaps](queue, &offset)
+		case CommandKindCopyTextureToBuffer:
+			peekCommand[CommandCopyTextureToBuffer](queue, &offset)
+		case CommandKindSamplerUnit:
+			peekCommand[CommandSamplerUnit](queue, &offset)
+		case CommandKindBlitFramebuffer:
+			peekCommand[CommandBlitFramebuffer](queue, &offset)
+		default:
+			panic(fmt.Errorf("unknown command kind: %v", header.Kind))
+		}
+	}
+}
+
+func (m *IDRemapper) remapLocation(location int32) int32 {
+	if newLocation, ok := m.UniformLocations[m.program][location]; ok {
+		return newLocation
+	}
+	return location
+}
+
+func peekCommand[T any](queue *CommandQueue, offset *uintptr) *T {
+	target := (*T)(unsafe.Add(unsafe.Pointer(&queue.data[0]), *offset))
+	*offset += unsafe.Sizeof(*target)
+	return target
+}
+
+func remapID(mapping map[uint32]uint32, id uint32) uint32 {
+	if newID, ok := mapping[id]; ok {
+		return newID
+	}
+	return id
+}
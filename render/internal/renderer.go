@@ -2,16 +2,22 @@ package internal
 
 import (
 	"fmt"
+	"slices"
 
 	"github.com/go-gl/gl/v4.6-core/gl"
+	"github.com/mokiat/lacking-gl/render/ext"
 	"github.com/mokiat/lacking/render"
 )
 
-func NewRenderer() *Renderer {
+func NewRenderer(capabilities ext.Capabilities) *Renderer {
 	result := &Renderer{
-		framebuffer:   DefaultFramebuffer,
-		isDirty:       true,
-		isInvalidated: true,
+		framebuffer:      DefaultFramebuffer,
+		indirectCountARB: !capabilities.Version.AtLeast(4, 6),
+		linkingPrograms:  make(map[uint32]*Program),
+		remappedPrograms: make(map[uint32]map[int32]int32),
+		queries:          make(map[uint32]*Query),
+		isDirty:          true,
+		isInvalidated:    true,
 		desiredState: &State{
 			CullTest:                    false,
 			CullFace:                    gl.BACK,
@@ -42,10 +48,17 @@ func NewRenderer() *Renderer {
 			BlendDestinationFactorRGB:   gl.ZERO,
 			BlendSourceFactorAlpha:      gl.ONE,
 			BlendDestinationFactorAlpha: gl.ZERO,
+			AlphaToCoverage:             false,
 		},
 		actualState: &State{},
 	}
 	result.Invalidate()
+
+	// Pixel rows are transferred without padding, which matters for
+	// formats with fewer than four bytes per pixel.
+	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 1)
+	gl.PixelStorei(gl.PACK_ALIGNMENT, 1)
+
 	return result
 }
 
@@ -53,9 +66,32 @@ type Renderer struct {
 	framebuffer           *Framebuffer
 	invalidateAttachments []uint32
 	program               uint32
+	locations             map[int32]int32
 	topology              uint32
+	patchVertices         int32
 	indexType             uint32
 
+	// samplerUnits holds the texture units that have a sampler bound, so
+	// that they can be unbound once the render pass ends.
+	samplerUnits []uint32
+
+	// indirectCountARB indicates that indirect count draws need to use
+	// the functions of the ARB_indirect_parameters extension.
+	indirectCountARB bool
+
+	// linkingPrograms holds the programs that are being linked in the
+	// background, by program name, so that their results can be collected
+	// once a command that binds them is executed.
+	linkingPrograms map[uint32]*Program
+
+	// remappedPrograms holds the location translations of the programs
+	// that have been rebuilt with moved uniforms, by program name.
+	remappedPrograms map[uint32]map[int32]int32
+
+	// queries holds the queries whose state is updated once the commands
+	// of their measurements are executed, by query name.
+	queries map[uint32]*Query
+
 	isDirty       bool
 	isInvalidated bool
 	desiredState  *State
@@ -201,11 +237,15 @@ func (r *Renderer) EndRenderPass() {
 	gl.Disable(gl.CLIP_DISTANCE2)
 	gl.Disable(gl.CLIP_DISTANCE3)
 	gl.Disable(gl.PRIMITIVE_RESTART_FIXED_INDEX)
+	r.unbindSamplers()
 	r.framebuffer = DefaultFramebuffer
 }
 
 func (r *Renderer) Invalidate() {
+	r.unbindSamplers()
 	r.program = 0
+	r.locations = nil
+	r.patchVertices = 0
 	r.isDirty = true
 	r.isInvalidated = true
 }
@@ -213,7 +253,7 @@ func (r *Renderer) Invalidate() {
 func (r *Renderer) BindPipeline(pipeline render.Pipeline) {
 	intPipeline := pipeline.(*Pipeline)
 	r.executeCommandBindPipeline(CommandBindPipeline{
-		ProgramID:        intPipeline.ProgramID,
+		ProgramID:        intPipeline.Program.id,
 		Topology:         intPipeline.Topology,
 		CullTest:         intPipeline.CullTest,
 		FrontFace:        intPipeline.FrontFace,
@@ -232,6 +272,7 @@ func (r *Renderer) BindPipeline(pipeline render.Pipeline) {
 		BlendColor:       intPipeline.BlendColor,
 		BlendEquation:    intPipeline.BlendEquation,
 		BlendFunc:        intPipeline.BlendFunc,
+		AlphaToCoverage:  intPipeline.AlphaToCoverage,
 		VertexArray:      intPipeline.VertexArray,
 	})
 }
@@ -327,6 +368,105 @@ func (r *Renderer) CopyContentToTexture(info render.CopyContentToTextureInfo) {
 	}
 }
 
+func (r *Renderer) BindComputeProgram(program render.Program) {
+	r.executeCommandBindProgram(CommandBindProgram{
+		ProgramID: program.(*Program).id,
+	})
+}
+
+func (r *Renderer) StorageBufferUnit(index int, buffer render.Buffer) {
+	r.executeCommandStorageBufferUnit(CommandStorageBufferUnit{
+		Index:    uint32(index),
+		BufferID: buffer.(*Buffer).id,
+	})
+}
+
+func (r *Renderer) StorageBufferUnitRange(index int, buffer render.Buffer, offset, size int) {
+	r.executeCommandStorageBufferUnitRange(CommandStorageBufferUnitRange{
+		Index:    uint32(index),
+		BufferID: buffer.(*Buffer).id,
+		Offset:   uint32(offset),
+		Size:     uint32(size),
+	})
+}
+
+func (r *Renderer) ImageUnit(index int, texture render.Texture, info ext.ImageUnitInfo) {
+	r.executeCommandImageUnit(newCommandImageUnit(index, texture, info))
+}
+
+func (r *Renderer) Dispatch(groupsX, groupsY, groupsZ int) {
+	r.executeCommandDispatch(CommandDispatch{
+		GroupsX: uint32(groupsX),
+		GroupsY: uint32(groupsY),
+		GroupsZ: uint32(groupsZ),
+	})
+}
+
+func (r *Renderer) DispatchIndirect(buffer render.Buffer, offset int) {
+	r.executeCommandDispatchIndirect(CommandDispatchIndirect{
+		BufferID: buffer.(*Buffer).id,
+		Offset:   uint32(offset),
+	})
+}
+
+func (r *Renderer) DrawIndirect(buffer render.Buffer, offset int) {
+	r.executeCommandDrawIndirect(CommandDrawIndirect{
+		BufferID: buffer.(*Buffer).id,
+		Offset:   uint32(offset),
+	})
+}
+
+func (r *Renderer) DrawIndexedIndirect(buffer render.Buffer, offset int) {
+	r.executeCommandDrawIndexedIndirect(CommandDrawIndexedIndirect{
+		BufferID: buffer.(*Buffer).id,
+		Offset:   uint32(offset),
+	})
+}
+
+func (r *Renderer) MultiDrawIndexedIndirect(info ext.MultiDrawIndexedIndirectInfo) {
+	r.executeCommandMultiDrawIndexedIndirect(newCommandMultiDrawIndexedIndirect(info))
+}
+
+func (r *Renderer) MemoryBarrier(barrier ext.Barrier) {
+	r.executeCommandMemoryBarrier(CommandMemoryBarrier{
+		Barriers: glBarrierBits(barrier),
+	})
+}
+
+func (r *Renderer) WriteTimestamp(query ext.Query) {
+	r.executeCommandWriteTimestamp(newCommandWriteTimestamp(query))
+}
+
+func (r *Renderer) BeginQuery(query ext.Query) {
+	r.executeCommandBeginQuery(newCommandBeginQuery(query))
+}
+
+func (r *Renderer) EndQuery(query ext.Query) {
+	r.executeCommandEndQuery(newCommandEndQuery(query))
+}
+
+func (r *Renderer) UpdateTextureData(texture render.Texture, info ext.TextureUpdateInfo) {
+	r.executeCommandUpdateTextureData(newCommandUpdateTextureData(texture, info))
+}
+
+func (r *Renderer) GenerateTextureMipmaps(texture render.Texture) {
+	r.executeCommandGenerateTextureMipmaps(CommandGenerateTextureMipmaps{
+		TextureID: texture.(*Texture).id,
+	})
+}
+
+func (r *Renderer) CopyTextureToBuffer(texture render.Texture, info ext.TextureCopyToBufferInfo) {
+	r.executeCommandCopyTextureToBuffer(newCommandCopyTextureToBuffer(texture, info))
+}
+
+func (r *Renderer) SamplerUnit(index int, sampler ext.Sampler) {
+	r.executeCommandSamplerUnit(newCommandSamplerUnit(index, sampler))
+}
+
+func (r *Renderer) BlitFramebuffer(info ext.BlitFramebufferInfo) {
+	r.executeCommandBlitFramebuffer(newCommandBlitFramebuffer(info))
+}
+
 func (r *Renderer) SubmitQueue(queue *CommandQueue) {
 	for MoreCommands(queue) {
 		header := PopCommand[CommandHeader](queue)
@@ -389,6 +529,61 @@ func (r *Renderer) SubmitQueue(queue *CommandQueue) {
 			command := PopCommand[CommandUpdateBufferData](queue)
 			data := PopData(queue, command.Count)
 			r.executeCommandUpdateBufferData(command, data)
+		case CommandKindBindProgram:
+			command := PopCommand[CommandBindProgram](queue)
+			r.executeCommandBindProgram(command)
+		case CommandKindStorageBufferUnit:
+			command := PopCommand[CommandStorageBufferUnit](queue)
+			r.executeCommandStorageBufferUnit(command)
+		case CommandKindStorageBufferUnitRange:
+			command := PopCommand[CommandStorageBufferUnitRange](queue)
+			r.executeCommandStorageBufferUnitRange(command)
+		case CommandKindImageUnit:
+			command := PopCommand[CommandImageUnit](queue)
+			r.executeCommandImageUnit(command)
+		case CommandKindDispatch:
+			command := PopCommand[CommandDispatch](queue)
+			r.executeCommandDispatch(command)
+		case CommandKindDispatchIndirect:
+			command := PopCommand[CommandDispatchIndirect](queue)
+			r.executeCommandDispatchIndirect(command)
+		case CommandKindMemoryBarrier:
+			command := PopCommand[CommandMemoryBarrier](queue)
+			r.executeCommandMemoryBarrier(command)
+		case CommandKindDrawIndirect:
+			command := PopCommand[CommandDrawIndirect](queue)
+			r.executeCommandDrawIndirect(command)
+		case CommandKindDrawIndexedIndirect:
+			command := PopCommand[CommandDrawIndexedIndirect](queue)
+			r.executeCommandDrawIndexedIndirect(command)
+		case CommandKindMultiDrawIndexedIndirect:
+			command := PopCommand[CommandMultiDrawIndexedIndirect](queue)
+			r.executeCommandMultiDrawIndexedIndirect(command)
+		case CommandKindWriteTimestamp:
+			command := PopCommand[CommandWriteTimestamp](queue)
+			r.executeCommandWriteTimestamp(command)
+		case CommandKindBeginQuery:
+			command := PopCommand[CommandBeginQuery](queue)
+			r.executeCommandBeginQuery(command)
+		case CommandKindEndQuery:
+			command := PopCommand[CommandEndQuery](queue)
+			r.executeCommandEndQuery(command)
+		case CommandKindUpdateTextureData:
+			command := PopCommand[CommandUpdateTextureData](queue)
+			data := PopData(queue, command.Count)
+			r.executeCommandUpdateTextureData(command, data)
+		case CommandKindGenerateTextureMipmaps:
+			command := PopCommand[CommandGenerateTextureMipmaps](queue)
+			r.executeCommandGenerateTextureMipmaps(command)
+		case CommandKindCopyTextureToBuffer:
+			command := PopCommand[CommandCopyTextureToBuffer](queue)
+			r.executeCommandCopyTextureToBuffer(command)
+		case CommandKindSamplerUnit:
+			command := PopCommand[CommandSamplerUnit](queue)
+			r.executeCommandSamplerUnit(command)
+		case CommandKindBlitFramebuffer:
+			command := PopCommand[CommandBlitFramebuffer](queue)
+			r.executeCommandBlitFramebuffer(command)
 		default:
 			panic(fmt.Errorf("unknown command kind: %v", header.Kind))
 		}
@@ -396,11 +591,54 @@ func (r *Renderer) SubmitQueue(queue *CommandQueue) {
 	queue.Reset()
 }
 
-func (r *Renderer) executeCommandBindPipeline(command CommandBindPipeline) {
-	if r.program != command.ProgramID {
-		r.program = command.ProgramID
-		gl.UseProgram(command.ProgramID)
+// TrackLinkingProgram makes the renderer collect the link results of the
+// specified program, if it is being linked in the background, once a
+// command that binds it is executed. Otherwise, the results of a program
+// that is only ever bound would not be collected.
+func (r *Renderer) TrackLinkingProgram(program *Program) {
+	if !program.Linking() {
+		return
+	}
+	// Programs whose results have been collected in the meantime, or that
+	// have been released, are dropped first.
+	for id, linkingProgram := range r.linkingPrograms {
+		if !linkingProgram.Linking() {
+			delete(r.linkingPrograms, id)
+		}
+	}
+	r.linkingPrograms[program.id] = program
+}
+
+// waitProgram collects the link results of the program with the specified
+// name, if it is being linked in the background.
+func (r *Renderer) waitProgram(id uint32) {
+	if program, ok := r.linkingPrograms[id]; ok {
+		delete(r.linkingPrograms, id)
+		program.Wait()
 	}
+}
+
+// useProgram makes the specified program current, unless it already is.
+func (r *Renderer) useProgram(id uint32) {
+	if r.program != id {
+		r.program = id
+		r.locations = r.remappedPrograms[id]
+		gl.UseProgram(id)
+	}
+}
+
+// uniformLocation translates a location that was handed out by the
+// current program to its location in the current version of the program.
+func (r *Renderer) uniformLocation(location int32) int32 {
+	if newLocation, ok := r.locations[location]; ok {
+		return newLocation
+	}
+	return location
+}
+
+func (r *Renderer) executeCommandBindPipeline(command CommandBindPipeline) {
+	r.waitProgram(command.ProgramID)
+	r.useProgram(command.ProgramID)
 	r.executeCommandTopology(command.Topology)
 	r.executeCommandCullTest(command.CullTest)
 	r.executeCommandFrontFace(command.FrontFace)
@@ -426,11 +664,16 @@ func (r *Renderer) executeCommandBindPipeline(command CommandBindPipeline) {
 		r.executeCommandBlendEquation(command.BlendEquation)
 		r.executeCommandBlendFunc(command.BlendFunc)
 	}
+	r.desiredState.AlphaToCoverage = command.AlphaToCoverage
 	r.executeCommandBindVertexArray(command.VertexArray)
 }
 
 func (r *Renderer) executeCommandTopology(command CommandTopology) {
 	r.topology = command.Topology
+	if command.Topology == gl.PATCHES && r.patchVertices != command.PatchVertices {
+		r.patchVertices = command.PatchVertices
+		gl.PatchParameteri(gl.PATCH_VERTICES, command.PatchVertices)
+	}
 }
 
 func (r *Renderer) executeCommandCullTest(command CommandCullTest) {
@@ -535,21 +778,21 @@ func (r *Renderer) executeCommandBindVertexArray(command CommandBindVertexArray)
 
 func (r *Renderer) executeCommandUniform1f(command CommandUniform1f) {
 	gl.Uniform1f(
-		command.Location,
+		r.uniformLocation(command.Location),
 		command.Value,
 	)
 }
 
 func (r *Renderer) executeCommandUniform1i(command CommandUniform1i) {
 	gl.Uniform1i(
-		command.Location,
+		r.uniformLocation(command.Location),
 		command.Value,
 	)
 }
 
 func (r *Renderer) executeCommandUniform3f(command CommandUniform3f) {
 	gl.Uniform3f(
-		command.Location,
+		r.uniformLocation(command.Location),
 		command.Values[0],
 		command.Values[1],
 		command.Values[2],
@@ -558,7 +801,7 @@ func (r *Renderer) executeCommandUniform3f(command CommandUniform3f) {
 
 func (r *Renderer) executeCommandUniform4f(command CommandUniform4f) {
 	gl.Uniform4f(
-		command.Location,
+		r.uniformLocation(command.Location),
 		command.Values[0],
 		command.Values[1],
 		command.Values[2],
@@ -569,7 +812,7 @@ func (r *Renderer) executeCommandUniform4f(command CommandUniform4f) {
 func (r *Renderer) executeCommandUniformMatrix4f(command CommandUniformMatrix4f) {
 	slice := command.Values[:]
 	gl.UniformMatrix4fv(
-		command.Location,
+		r.uniformLocation(command.Location),
 		1,
 		false,
 		&slice[0],
@@ -646,6 +889,218 @@ func (r *Renderer) executeCommandUpdateBufferData(command CommandUpdateBufferDat
 	gl.NamedBufferSubData(command.BufferID, int(command.Offset), len(data), gl.Ptr(&data[0]))
 }
 
+func (r *Renderer) executeCommandBindProgram(command CommandBindProgram) {
+	r.waitProgram(command.ProgramID)
+	r.useProgram(command.ProgramID)
+}
+
+func (r *Renderer) executeCommandStorageBufferUnit(command CommandStorageBufferUnit) {
+	gl.BindBufferBase(
+		gl.SHADER_STORAGE_BUFFER,
+		command.Index,
+		command.BufferID,
+	)
+}
+
+func (r *Renderer) executeCommandStorageBufferUnitRange(command CommandStorageBufferUnitRange) {
+	gl.BindBufferRange(
+		gl.SHADER_STORAGE_BUFFER,
+		command.Index,
+		command.BufferID,
+		int(command.Offset),
+		int(command.Size),
+	)
+}
+
+func (r *Renderer) executeCommandImageUnit(command CommandImageUnit) {
+	gl.BindImageTexture(
+		command.Index,
+		command.TextureID,
+		command.Level,
+		command.Layered,
+		command.Layer,
+		command.Access,
+		command.Format,
+	)
+}
+
+func (r *Renderer) executeCommandDispatch(command CommandDispatch) {
+	gl.DispatchCompute(
+		command.GroupsX,
+		command.GroupsY,
+		command.GroupsZ,
+	)
+}
+
+func (r *Renderer) executeCommandDispatchIndirect(command CommandDispatchIndirect) {
+	gl.BindBuffer(
+		gl.DISPATCH_INDIRECT_BUFFER,
+		command.BufferID,
+	)
+	gl.DispatchComputeIndirect(int(command.Offset))
+	gl.BindBuffer(
+		gl.DISPATCH_INDIRECT_BUFFER,
+		0,
+	)
+}
+
+func (r *Renderer) executeCommandMemoryBarrier(command CommandMemoryBarrier) {
+	gl.MemoryBarrier(command.Barriers)
+}
+
+func (r *Renderer) executeCommandDrawIndirect(command CommandDrawIndirect) {
+	r.validateState()
+	gl.BindBuffer(
+		gl.DRAW_INDIRECT_BUFFER,
+		command.BufferID,
+	)
+	gl.DrawArraysIndirect(
+		r.topology,
+		gl.PtrOffset(int(command.Offset)),
+	)
+	gl.BindBuffer(
+		gl.DRAW_INDIRECT_BUFFER,
+		0,
+	)
+}
+
+func (r *Renderer) executeCommandDrawIndexedIndirect(command CommandDrawIndexedIndirect) {
+	r.validateState()
+	gl.BindBuffer(
+		gl.DRAW_INDIRECT_BUFFER,
+		command.BufferID,
+	)
+	gl.DrawElementsIndirect(
+		r.topology,
+		r.indexType,
+		gl.PtrOffset(int(command.Offset)),
+	)
+	gl.BindBuffer(
+		gl.DRAW_INDIRECT_BUFFER,
+		0,
+	)
+}
+
+func (r *Renderer) executeCommandMultiDrawIndexedIndirect(command CommandMultiDrawIndexedIndirect) {
+	r.validateState()
+	gl.BindBuffer(
+		gl.DRAW_INDIRECT_BUFFER,
+		command.BufferID,
+	)
+	if command.CountBufferID == 0 {
+		gl.MultiDrawElementsIndirect(
+			r.topology,
+			r.indexType,
+			gl.PtrOffset(int(command.Offset)),
+			command.DrawCount,
+			command.Stride,
+		)
+	} else {
+		gl.BindBuffer(
+			gl.PARAMETER_BUFFER,
+			command.CountBufferID,
+		)
+		drawIndirectCount := gl.MultiDrawElementsIndirectCount
+		if r.indirectCountARB {
+			drawIndirectCount = gl.MultiDrawElementsIndirectCountARB
+		}
+		drawIndirectCount(
+			r.topology,
+			r.indexType,
+			gl.PtrOffset(int(command.Offset)),
+			int(command.CountOffset),
+			command.DrawCount,
+			command.Stride,
+		)
+		gl.BindBuffer(
+			gl.PARAMETER_BUFFER,
+			0,
+		)
+	}
+	gl.BindBuffer(
+		gl.DRAW_INDIRECT_BUFFER,
+		0,
+	)
+}
+
+// TrackQuery makes the renderer update the state of the specified query
+// once the commands of its measurements are executed.
+func (r *Renderer) TrackQuery(query *Query) {
+	query.renderer = r
+	r.queries[query.id] = query
+}
+
+// setQueryState updates the state of the query with the specified name.
+func (r *Renderer) setQueryState(id uint32, state queryState) {
+	if query, ok := r.queries[id]; ok {
+		query.state = state
+	}
+}
+
+func (r *Renderer) executeCommandWriteTimestamp(command CommandWriteTimestamp) {
+	gl.QueryCounter(command.QueryID, gl.TIMESTAMP)
+	r.setQueryState(command.QueryID, queryStateEnded)
+}
+
+func (r *Renderer) executeCommandBeginQuery(command CommandBeginQuery) {
+	gl.BeginQuery(command.Target, command.QueryID)
+	r.setQueryState(command.QueryID, queryStatePending)
+}
+
+func (r *Renderer) executeCommandEndQuery(command CommandEndQuery) {
+	gl.EndQuery(command.Target)
+	r.setQueryState(command.QueryID, queryStateEnded)
+}
+
+func (r *Renderer) executeCommandUpdateTextureData(command CommandUpdateTextureData, data []byte) {
+	updateTextureData(command, data)
+}
+
+func (r *Renderer) executeCommandGenerateTextureMipmaps(command CommandGenerateTextureMipmaps) {
+	gl.GenerateTextureMipmap(command.TextureID)
+}
+
+func (r *Renderer) executeCommandCopyTextureToBuffer(command CommandCopyTextureToBuffer) {
+	copyTextureToBuffer(command)
+}
+
+func (r *Renderer) executeCommandSamplerUnit(command CommandSamplerUnit) {
+	gl.BindSampler(command.Index, command.SamplerID)
+	if command.SamplerID == 0 {
+		r.samplerUnits = slices.DeleteFunc(r.samplerUnits, func(unit uint32) bool {
+			return unit == command.Index
+		})
+	} else if !slices.Contains(r.samplerUnits, command.Index) {
+		r.samplerUnits = append(r.samplerUnits, command.Index)
+	}
+}
+
+// unbindSamplers unbinds the samplers that are bound to texture units, so
+// that textures are sampled with their own parameters again.
+func (r *Renderer) unbindSamplers() {
+	for _, unit := range r.samplerUnits {
+		gl.BindSampler(unit, 0)
+	}
+	r.samplerUnits = r.samplerUnits[:0]
+}
+
+func (r *Renderer) executeCommandBlitFramebuffer(command CommandBlitFramebuffer) {
+	gl.BlitNamedFramebuffer(
+		command.SourceID,
+		command.DestinationID,
+		command.SourceX0,
+		command.SourceY0,
+		command.SourceX1,
+		command.SourceY1,
+		command.DestinationX0,
+		command.DestinationY0,
+		command.DestinationX1,
+		command.DestinationY1,
+		command.Mask,
+		command.Filter,
+	)
+}
+
 func (r *Renderer) validateState() {
 	if r.isDirty || r.isInvalidated {
 		forcedUpdate := r.isInvalidated
@@ -664,6 +1119,7 @@ func (r *Renderer) validateState() {
 		r.validateBlendEquation(forcedUpdate)
 		r.validateBlendFunc(forcedUpdate)
 		r.validateBlendColor(forcedUpdate)
+		r.validateAlphaToCoverage(forcedUpdate)
 	}
 	r.isDirty = false
 	r.isInvalidated = false
@@ -925,6 +1381,20 @@ func (r *Renderer) validateBlending(forcedUpdate bool) {
 	}
 }
 
+func (r *Renderer) validateAlphaToCoverage(forcedUpdate bool) {
+	needsUpdate := forcedUpdate ||
+		(r.actualState.AlphaToCoverage != r.desiredState.AlphaToCoverage)
+
+	if needsUpdate {
+		r.actualState.AlphaToCoverage = r.desiredState.AlphaToCoverage
+		if r.actualState.AlphaToCoverage {
+			gl.Enable(gl.SAMPLE_ALPHA_TO_COVERAGE)
+		} else {
+			gl.Disable(gl.SAMPLE_ALPHA_TO_COVERAGE)
+		}
+	}
+}
+
 func (r *Renderer) validateBlendColor(forcedUpdate bool) {
 	needsUpdate := forcedUpdate ||
 		(r.actualState.BlendColor != r.desiredState.BlendColor)
@@ -0,0 +1,76 @@
+package internal
+
+import (
+	"github.com/go-gl/gl/v4.6-core/gl"
+	"github.com/mokiat/lacking-gl/render/ext"
+	"github.com/mokiat/lacking/render"
+)
+
+func NewSampler(info ext.SamplerInfo) *Sampler {
+	var id uint32
+	gl.CreateSamplers(1, &id)
+
+	if info.BorderColor != nil {
+		gl.SamplerParameteri(id, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_BORDER)
+		gl.SamplerParameteri(id, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_BORDER)
+		gl.SamplerParameteri(id, gl.TEXTURE_WRAP_R, gl.CLAMP_TO_BORDER)
+		gl.SamplerParameterfv(id, gl.TEXTURE_BORDER_COLOR, &info.BorderColor[0])
+	} else {
+		gl.SamplerParameteri(id, gl.TEXTURE_WRAP_S, glWrap(info.Wrapping))
+		gl.SamplerParameteri(id, gl.TEXTURE_WRAP_T, glWrap(info.Wrapping))
+		gl.SamplerParameteri(id, gl.TEXTURE_WRAP_R, glWrap(info.Wrapping))
+	}
+
+	gl.SamplerParameteri(id, gl.TEXTURE_MIN_FILTER, glFilter(info.Filtering, info.Mipmapping))
+	gl.SamplerParameteri(id, gl.TEXTURE_MAG_FILTER, glFilter(info.Filtering, false)) // no mipmaps when magnification
+	if info.Filtering == render.FilterModeAnisotropic {
+		maxAnisotropy := info.MaxAnisotropy
+		if maxAnisotropy == 0.0 {
+			gl.GetFloatv(gl.MAX_TEXTURE_MAX_ANISOTROPY, &maxAnisotropy)
+		}
+		gl.SamplerParameterf(id, gl.TEXTURE_MAX_ANISOTROPY, maxAnisotropy)
+	}
+
+	gl.SamplerParameterf(id, gl.TEXTURE_LOD_BIAS, info.LODBias)
+	if info.MinLOD != nil {
+		gl.SamplerParameterf(id, gl.TEXTURE_MIN_LOD, *info.MinLOD)
+	}
+	if info.MaxLOD != nil {
+		gl.SamplerParameterf(id, gl.TEXTURE_MAX_LOD, *info.MaxLOD)
+	}
+
+	if info.Comparison != nil {
+		gl.SamplerParameteri(id, gl.TEXTURE_COMPARE_MODE, gl.COMPARE_REF_TO_TEXTURE)
+		gl.SamplerParameteri(id, gl.TEXTURE_COMPARE_FUNC, int32(glEnumFromComparison(*info.Comparison)))
+	} else {
+		gl.SamplerParameteri(id, gl.TEXTURE_COMPARE_MODE, gl.NONE)
+	}
+
+	return &Sampler{
+		id: id,
+	}
+}
+
+type Sampler struct {
+	id uint32
+}
+
+// ID returns the OpenGL name of this sampler.
+func (s *Sampler) ID() uint32 {
+	return s.id
+}
+
+func (s *Sampler) Release() {
+	gl.DeleteSamplers(1, &s.id)
+	s.id = 0
+}
+
+func newCommandSamplerUnit(index int, sampler ext.Sampler) CommandSamplerUnit {
+	command := CommandSamplerUnit{
+		Index: uint32(index),
+	}
+	if sampler != nil {
+		command.SamplerID = sampler.(*Sampler).id
+	}
+	return command
+}
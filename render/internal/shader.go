@@ -1,45 +1,179 @@
 package internal
 
 import (
-	"errors"
+	"fmt"
 	"runtime"
 	"strings"
 
 	"github.com/go-gl/gl/v4.6-core/gl"
-	"github.com/mokiat/lacking/log"
+	"github.com/mokiat/lacking-gl/render/ext"
 	"github.com/mokiat/lacking/render"
 )
 
+// NewShader creates a shader for the specified stage.
+func NewShader(stage ext.ShaderStage, info render.ShaderInfo) *Shader {
+	return newShader(glShaderType(stage), stage, info)
+}
+
 func NewVertexShader(info render.ShaderInfo) *Shader {
+	return newShader(gl.VERTEX_SHADER, ext.ShaderStageVertex, info)
+}
+
+func NewFragmentShader(info render.ShaderInfo) *Shader {
+	return newShader(gl.FRAGMENT_SHADER, ext.ShaderStageFragment, info)
+}
+
+func NewComputeShader(info render.ShaderInfo) *Shader {
+	return newShader(gl.COMPUTE_SHADER, ext.ShaderStageCompute, info)
+}
+
+// SetMaxShaderCompilerThreads specifies the number of background threads
+// that the driver may use to compile shaders and link programs. A value
+// of zero disables background compilation and the maximum uint32 value
+// lets the driver decide. It requires ext.Capabilities.ParallelShaderCompile.
+func SetMaxShaderCompilerThreads(capabilities ext.Capabilities, count uint32) {
+	// The KHR and ARB extensions provide the same function under different
+	// names and either one may be missing.
+	if capabilities.HasExtension("GL_KHR_parallel_shader_compile") {
+		gl.MaxShaderCompilerThreadsKHR(count)
+	} else {
+		gl.MaxShaderCompilerThreadsARB(count)
+	}
+}
+
+// NewSPIRVShader creates a shader from a SPIR-V binary. If the driver does
+// not support SPIR-V (see ext.Capabilities.SPIRV) or the binary is empty,
+// the shader is created as one that failed to compile.
+func NewSPIRVShader(info ext.SPIRVShaderInfo, capabilities ext.Capabilities) *Shader {
+	if info.EntryPoint == "" {
+		info.EntryPoint = "main"
+	}
 	shader := &Shader{
-		id: gl.CreateShader(gl.VERTEX_SHADER),
+		id:         gl.CreateShader(glShaderType(info.Stage)),
+		stage:      info.Stage,
+		sourceCode: string(info.Binary),
+		spirv: &spirvShader{
+			info:         info,
+			capabilities: capabilities,
+		},
 	}
-	shader.setSourceCode(info.SourceCode)
-	if err := shader.compile(); err != nil {
-		log.Error("Shader compilation error: %v", err)
+	switch {
+	case !capabilities.SPIRV:
+		shader.fail("SPIR-V shaders are not supported by the driver")
+	case len(info.Binary) == 0:
+		shader.fail("SPIR-V binary is empty")
+	default:
+		gl.ShaderBinary(1, &shader.id, gl.SHADER_BINARY_FORMAT_SPIR_V, gl.Ptr(&info.Binary[0]), int32(len(info.Binary)))
 	}
 	return shader
 }
 
-func NewFragmentShader(info render.ShaderInfo) *Shader {
+// IsSPIRV returns whether the specified source code is a SPIR-V binary,
+// by checking for the magic number at its start.
+func IsSPIRV(sourceCode string) bool {
+	return strings.HasPrefix(sourceCode, spirvMagic)
+}
+
+// spirvMagic is the magic number of SPIR-V modules, in little-endian
+// byte order.
+const spirvMagic = "\x03\x02\x23\x07"
+
+// spirvShader holds the information needed to specialize a SPIR-V shader.
+type spirvShader struct {
+	info         ext.SPIRVShaderInfo
+	capabilities ext.Capabilities
+}
+
+// newShader creates a shader with the specified source code. The shader
+// is compiled on first use, which allows programs that are restored from
+// a program cache to skip compilation entirely.
+func newShader(xtype uint32, stage ext.ShaderStage, info render.ShaderInfo) *Shader {
 	shader := &Shader{
-		id: gl.CreateShader(gl.FRAGMENT_SHADER),
-	}
-	shader.setSourceCode(info.SourceCode)
-	if err := shader.compile(); err != nil {
-		log.Error("Shader compilation error: %v", err)
+		id:         gl.CreateShader(xtype),
+		stage:      stage,
+		sourceCode: info.SourceCode,
 	}
+	code, sources := newSourceMap(info.SourceCode)
+	shader.setSourceCode(code)
+	shader.sources = sources
 	return shader
 }
 
 type Shader struct {
 	render.ShaderObject
-	id uint32
+	id          uint32
+	stage       ext.ShaderStage
+	sourceCode  string
+	sources     *sourceMap
+	spirv       *spirvShader
+	compiling   bool
+	compiled    bool
+	released    bool
+	diagnostics []ext.Diagnostic
+	err         error
+}
+
+// ID returns the OpenGL name of this shader.
+func (s *Shader) ID() uint32 {
+	return s.id
+}
+
+// Compiled returns whether the shader has been compiled.
+func (s *Shader) Compiled() bool {
+	return s.compiled
+}
+
+// Compiling returns whether the compilation of the shader has been
+// started through CompileAsync but its results have not been collected.
+func (s *Shader) Compiling() bool {
+	return s.compiling
+}
+
+// Compile compiles the shader, unless it has already been compiled. If
+// an asynchronous compilation is in progress, it waits for it instead.
+func (s *Shader) Compile() {
+	s.CompileAsync()
+	s.finishCompile()
+}
+
+// CompileAsync starts the compilation of the shader, unless it has
+// already been started. The results are collected on the next call to
+// Compile.
+func (s *Shader) CompileAsync() {
+	if !s.compiled && !s.compiling {
+		if s.spirv != nil {
+			s.specialize()
+		} else {
+			gl.CompileShader(s.id)
+		}
+		s.compiling = true
+	}
+}
+
+// Diagnostics returns the messages that were reported by the driver
+// during compilation, including warnings. The shader is compiled if it
+// has not been already.
+func (s *Shader) Diagnostics() []ext.Diagnostic {
+	s.Compile()
+	return s.diagnostics
+}
+
+// Err returns an *ext.ShaderError if the shader failed to compile. The
+// shader is compiled if it has not been already.
+func (s *Shader) Err() error {
+	s.Compile()
+	return s.err
 }
 
 func (s *Shader) Release() {
 	gl.DeleteShader(s.id)
-	s.id = 0
+	s.released = true
+	// NOTE: A shader that is attached to a program is only flagged for
+	// deletion, so the results of a compilation in progress can still be
+	// collected. The program clears the ID once it detaches the shader.
+	if !s.compiling {
+		s.id = 0
+	}
 }
 
 func (s *Shader) setSourceCode(code string) {
@@ -48,12 +182,62 @@ func (s *Shader) setSourceCode(code string) {
 	gl.ShaderSource(s.id, 1, sources, nil)
 }
 
-func (s *Shader) compile() error {
-	gl.CompileShader(s.id)
+// specialize is the SPIR-V equivalent of compilation.
+func (s *Shader) specialize() {
+	// The ARB_gl_spirv variant of the function needs to be used prior to
+	// OpenGL 4.6.
+	specialize := gl.SpecializeShader
+	if !s.spirv.capabilities.Version.AtLeast(4, 6) {
+		specialize = gl.SpecializeShaderARB
+	}
+	info := s.spirv.info
+	indices := make([]uint32, len(info.Constants))
+	values := make([]uint32, len(info.Constants))
+	for i, constant := range info.Constants {
+		indices[i] = constant.ID
+		values[i] = constant.Value
+	}
+	var indicesPtr, valuesPtr *uint32
+	if len(info.Constants) > 0 {
+		indicesPtr = &indices[0]
+		valuesPtr = &values[0]
+	}
+	entryPoint := info.EntryPoint + "\x00"
+	specialize(s.id, gl.Str(entryPoint), uint32(len(info.Constants)), indicesPtr, valuesPtr)
+	runtime.KeepAlive(entryPoint)
+}
+
+// fail marks the shader as one that failed to compile with the specified
+// message, without involving the driver.
+func (s *Shader) fail(message string) {
+	s.compiled = true
+	s.diagnostics = []ext.Diagnostic{
+		{
+			Stage:    s.stage,
+			Severity: ext.DiagnosticSeverityError,
+			Message:  message,
+		},
+	}
+	s.err = &ext.ShaderError{
+		Diagnostics: s.diagnostics,
+		Log:         message,
+	}
+}
+
+func (s *Shader) finishCompile() {
+	if !s.compiling {
+		return
+	}
+	s.compiling = false
+	s.compiled = true
+	infoLog := s.getInfoLog()
+	s.diagnostics = parseInfoLog(infoLog, s.stage, s.sources)
 	if !s.isCompileSuccessful() {
-		return errors.New(s.getInfoLog())
+		s.err = &ext.ShaderError{
+			Diagnostics: s.diagnostics,
+			Log:         infoLog,
+		}
 	}
-	return nil
 }
 
 func (s *Shader) isCompileSuccessful() bool {
@@ -69,5 +253,24 @@ func (s *Shader) getInfoLog() string {
 	log := strings.Repeat("\x00", int(logLength+1))
 	gl.GetShaderInfoLog(s.id, logLength, nil, gl.Str(log))
 	runtime.KeepAlive(log)
-	return log
+	return strings.TrimRight(log, "\x00")
+}
+
+func glShaderType(stage ext.ShaderStage) uint32 {
+	switch stage {
+	case ext.ShaderStageVertex:
+		return gl.VERTEX_SHADER
+	case ext.ShaderStageFragment:
+		return gl.FRAGMENT_SHADER
+	case ext.ShaderStageCompute:
+		return gl.COMPUTE_SHADER
+	case ext.ShaderStageGeometry:
+		return gl.GEOMETRY_SHADER
+	case ext.ShaderStageTessControl:
+		return gl.TESS_CONTROL_SHADER
+	case ext.ShaderStageTessEvaluation:
+		return gl.TESS_EVALUATION_SHADER
+	default:
+		panic(fmt.Errorf("unknown shader stage: %s", stage))
+	}
 }
@@ -31,4 +31,5 @@ type State struct {
 	BlendDestinationFactorRGB   uint32
 	BlendSourceFactorAlpha      uint32
 	BlendDestinationFactorAlpha uint32
+	AlphaToCoverage             bool
 }
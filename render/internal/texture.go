@@ -1,7 +1,12 @@
 package internal
 
 import (
+	"fmt"
+
 	"github.com/go-gl/gl/v4.6-core/gl"
+	"github.com/mokiat/lacking-gl/render/decompress"
+	"github.com/mokiat/lacking-gl/render/ext"
+	"github.com/mokiat/lacking/log"
 	"github.com/mokiat/lacking/render"
 )
 
@@ -10,13 +15,7 @@ func NewColorTexture2D(info render.ColorTexture2DInfo) *Texture {
 	gl.CreateTextures(gl.TEXTURE_2D, 1, &id)
 	gl.TextureParameteri(id, gl.TEXTURE_WRAP_S, glWrap(info.Wrapping))
 	gl.TextureParameteri(id, gl.TEXTURE_WRAP_T, glWrap(info.Wrapping))
-	gl.TextureParameteri(id, gl.TEXTURE_MIN_FILTER, glFilter(info.Filtering, info.Mipmapping))
-	gl.TextureParameteri(id, gl.TEXTURE_MAG_FILTER, glFilter(info.Filtering, false)) // no mipmaps when magnification
-	if info.Filtering == render.FilterModeAnisotropic {
-		var maxAnisotropy float32
-		gl.GetFloatv(gl.MAX_TEXTURE_MAX_ANISOTROPY, &maxAnisotropy)
-		gl.TextureParameterf(id, gl.TEXTURE_MAX_ANISOTROPY, maxAnisotropy)
-	}
+	setColorFiltering(id, info.Filtering, info.Mipmapping, info.Format)
 
 	levels := glMipmapLevels(info.Width, info.Height, info.Mipmapping)
 	internalFormat := glInternalFormat(info.Format, info.GammaCorrection)
@@ -27,36 +26,78 @@ func NewColorTexture2D(info render.ColorTexture2DInfo) *Texture {
 		componentType := glDataComponentType(info.Format)
 		gl.TextureSubImage2D(id, 0, 0, 0, int32(info.Width), int32(info.Height), dataFormat, componentType, gl.Ptr(info.Data))
 
-		if info.Mipmapping {
+		if info.Mipmapping && !isIntegerFormat(info.Format) {
 			gl.GenerateTextureMipmap(id)
 		}
 	}
 
 	return &Texture{
-		id: id,
+		id:     id,
+		width:  info.Width,
+		height: info.Height,
+		levels: levels,
 	}
 }
 
-func NewDepthTexture2D(info render.DepthTexture2DInfo) *Texture {
+// NewCompressedColorTexture2D creates a 2D texture from block-compressed
+// data. If the driver does not support the format, the data is decompressed
+// and the texture is stored as RGBA8 instead.
+func NewCompressedColorTexture2D(info render.ColorTexture2DInfo, capabilities ext.Capabilities) *Texture {
+	if !capabilities.SupportsCompressedFormat(info.Format, info.GammaCorrection) {
+		if info.Data != nil {
+			info.Data = decompressData(info.Format, info.Width, info.Height, 1, info.Data)
+		}
+		info.Format = render.DataFormatRGBA8
+		return NewColorTexture2D(info)
+	}
+
 	var id uint32
 	gl.CreateTextures(gl.TEXTURE_2D, 1, &id)
-	if info.ClippedValue != nil {
-		gl.TextureParameteri(id, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_BORDER)
-		gl.TextureParameteri(id, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_BORDER)
-		borderColor := []float32{*info.ClippedValue, *info.ClippedValue, *info.ClippedValue, *info.ClippedValue}
-		gl.TextureParameterfv(id, gl.TEXTURE_BORDER_COLOR, &borderColor[0])
-	} else {
-		gl.TextureParameteri(id, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
-		gl.TextureParameteri(id, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+	gl.TextureParameteri(id, gl.TEXTURE_WRAP_S, glWrap(info.Wrapping))
+	gl.TextureParameteri(id, gl.TEXTURE_WRAP_T, glWrap(info.Wrapping))
+	setColorFiltering(id, info.Filtering, info.Mipmapping, info.Format)
+
+	levels := glMipmapLevels(info.Width, info.Height, info.Mipmapping)
+	internalFormat := glCompressedInternalFormat(info.Format, info.GammaCorrection)
+	gl.TextureStorage2D(id, levels, internalFormat, int32(info.Width), int32(info.Height))
+
+	if info.Data != nil {
+		gl.CompressedTextureSubImage2D(id, 0, 0, 0, int32(info.Width), int32(info.Height), internalFormat, int32(len(info.Data)), gl.Ptr(info.Data))
 	}
-	gl.TextureParameteri(id, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
-	gl.TextureParameteri(id, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
-	if info.Comparable {
-		gl.TextureParameteri(id, gl.TEXTURE_COMPARE_MODE, gl.COMPARE_REF_TO_TEXTURE)
+
+	// Mipmaps cannot be generated for compressed formats. The remaining
+	// levels are to be provided through Update.
+
+	return &Texture{
+		id:               id,
+		width:            info.Width,
+		height:           info.Height,
+		levels:           levels,
+		compressedFormat: internalFormat,
+	}
+}
+
+func NewDepthTexture2D(info render.DepthTexture2DInfo) *Texture {
+	return newDepthTexture2D(info, gl.DEPTH_COMPONENT32)
+}
+
+func NewDepthTexture2DWithFormat(info render.DepthTexture2DInfo, format render.DataFormat) *Texture {
+	if !isDepthFormat(format) {
+		panic(fmt.Errorf("unsupported depth format %v", format))
 	}
-	gl.TextureStorage2D(id, 1, gl.DEPTH_COMPONENT32, int32(info.Width), int32(info.Height))
+	return newDepthTexture2D(info, glInternalFormat(format, false))
+}
+
+func newDepthTexture2D(info render.DepthTexture2DInfo, internalFormat uint32) *Texture {
+	var id uint32
+	gl.CreateTextures(gl.TEXTURE_2D, 1, &id)
+	setDepthSampling(id, info.ClippedValue, info.Comparable)
+	gl.TextureStorage2D(id, 1, internalFormat, int32(info.Width), int32(info.Height))
 	return &Texture{
-		id: id,
+		id:     id,
+		width:  info.Width,
+		height: info.Height,
+		levels: 1,
 	}
 }
 
@@ -69,7 +110,10 @@ func NewStencilTexture2D(info render.StencilTexture2DInfo) *Texture {
 	gl.TextureParameteri(id, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
 	gl.TextureStorage2D(id, 1, gl.STENCIL_INDEX8, int32(info.Width), int32(info.Height))
 	return &Texture{
-		id: id,
+		id:     id,
+		width:  info.Width,
+		height: info.Height,
+		levels: 1,
 	}
 }
 
@@ -82,7 +126,10 @@ func NewDepthStencilTexture2D(info render.DepthStencilTexture2DInfo) *Texture {
 	gl.TextureParameteri(id, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
 	gl.TextureStorage2D(id, 1, gl.DEPTH24_STENCIL8, int32(info.Width), int32(info.Height))
 	return &Texture{
-		id: id,
+		id:     id,
+		width:  info.Width,
+		height: info.Height,
+		levels: 1,
 	}
 }
 
@@ -91,13 +138,7 @@ func NewColorTextureCube(info render.ColorTextureCubeInfo) *Texture {
 	gl.CreateTextures(gl.TEXTURE_CUBE_MAP, 1, &id)
 	gl.TextureParameteri(id, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
 	gl.TextureParameteri(id, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
-	gl.TextureParameteri(id, gl.TEXTURE_MIN_FILTER, glFilter(info.Filtering, info.Mipmapping))
-	gl.TextureParameteri(id, gl.TEXTURE_MAG_FILTER, glFilter(info.Filtering, false)) // no mipmaps when magnification
-	if info.Filtering == render.FilterModeAnisotropic {
-		var maxAnisotropy float32
-		gl.GetFloatv(gl.MAX_TEXTURE_MAX_ANISOTROPY, &maxAnisotropy)
-		gl.TextureParameterf(id, gl.TEXTURE_MAX_ANISOTROPY, maxAnisotropy)
-	}
+	setColorFiltering(id, info.Filtering, info.Mipmapping, info.Format)
 
 	levels := glMipmapLevels(info.Dimension, info.Dimension, info.Mipmapping)
 	internalFormat := glInternalFormat(info.Format, info.GammaCorrection)
@@ -125,19 +166,162 @@ func NewColorTextureCube(info render.ColorTextureCubeInfo) *Texture {
 		gl.TextureSubImage3D(id, 0, 0, 0, 5, int32(info.Dimension), int32(info.Dimension), 1, dataFormat, componentType, gl.Ptr(info.BackSideData))
 	}
 
-	// TODO: Move as separate command
-	// if info.Mipmapping {
-	// 	gl.GenerateTextureMipmap(id)
-	// }
+	// Mipmaps are not generated here, since sides may be provided later
+	// through Update. GenerateMipmaps is to be used once all are set.
+
+	return &Texture{
+		id:      id,
+		width:   info.Dimension,
+		height:  info.Dimension,
+		levels:  levels,
+		layered: true,
+	}
+}
+
+func NewColorTexture2DArray(info ext.ColorTexture2DArrayInfo) *Texture {
+	var id uint32
+	gl.CreateTextures(gl.TEXTURE_2D_ARRAY, 1, &id)
+	gl.TextureParameteri(id, gl.TEXTURE_WRAP_S, glWrap(info.Wrapping))
+	gl.TextureParameteri(id, gl.TEXTURE_WRAP_T, glWrap(info.Wrapping))
+	setColorFiltering(id, info.Filtering, info.Mipmapping, info.Format)
+
+	levels := glMipmapLevels(info.Width, info.Height, info.Mipmapping)
+	internalFormat := glInternalFormat(info.Format, info.GammaCorrection)
+	gl.TextureStorage3D(id, levels, internalFormat, int32(info.Width), int32(info.Height), int32(info.Layers))
+
+	if info.Data != nil {
+		dataFormat := glDataFormat(info.Format)
+		componentType := glDataComponentType(info.Format)
+		gl.TextureSubImage3D(id, 0, 0, 0, 0, int32(info.Width), int32(info.Height), int32(info.Layers), dataFormat, componentType, gl.Ptr(info.Data))
+
+		if info.Mipmapping && !isIntegerFormat(info.Format) {
+			gl.GenerateTextureMipmap(id)
+		}
+	}
+
+	return &Texture{
+		id:      id,
+		width:   info.Width,
+		height:  info.Height,
+		levels:  levels,
+		layered: true,
+	}
+}
+
+func NewColorTexture3D(info ext.ColorTexture3DInfo) *Texture {
+	var id uint32
+	gl.CreateTextures(gl.TEXTURE_3D, 1, &id)
+	gl.TextureParameteri(id, gl.TEXTURE_WRAP_S, glWrap(info.Wrapping))
+	gl.TextureParameteri(id, gl.TEXTURE_WRAP_T, glWrap(info.Wrapping))
+	gl.TextureParameteri(id, gl.TEXTURE_WRAP_R, glWrap(info.Wrapping))
+	setColorFiltering(id, info.Filtering, info.Mipmapping, info.Format)
+
+	levels := glMipmapLevels(max(info.Width, info.Height), info.Depth, info.Mipmapping)
+	internalFormat := glInternalFormat(info.Format, info.GammaCorrection)
+	gl.TextureStorage3D(id, levels, internalFormat, int32(info.Width), int32(info.Height), int32(info.Depth))
+
+	if info.Data != nil {
+		dataFormat := glDataFormat(info.Format)
+		componentType := glDataComponentType(info.Format)
+		gl.TextureSubImage3D(id, 0, 0, 0, 0, int32(info.Width), int32(info.Height), int32(info.Depth), dataFormat, componentType, gl.Ptr(info.Data))
+
+		if info.Mipmapping && !isIntegerFormat(info.Format) {
+			gl.GenerateTextureMipmap(id)
+		}
+	}
+
+	return &Texture{
+		id:      id,
+		width:   info.Width,
+		height:  info.Height,
+		levels:  levels,
+		layered: true,
+	}
+}
+
+func NewDepthTexture2DArray(info ext.DepthTexture2DArrayInfo) *Texture {
+	internalFormat := uint32(gl.DEPTH_COMPONENT32)
+	if info.Format != render.DataFormatUnsupported {
+		if !isDepthFormat(info.Format) {
+			panic(fmt.Errorf("unsupported depth format %v", info.Format))
+		}
+		internalFormat = glInternalFormat(info.Format, false)
+	}
+
+	var id uint32
+	gl.CreateTextures(gl.TEXTURE_2D_ARRAY, 1, &id)
+	setDepthSampling(id, info.ClippedValue, info.Comparable)
+	gl.TextureStorage3D(id, 1, internalFormat, int32(info.Width), int32(info.Height), int32(info.Layers))
+	return &Texture{
+		id:      id,
+		width:   info.Width,
+		height:  info.Height,
+		levels:  1,
+		layered: true,
+	}
+}
+
+func NewColorTexture2DMultisample(info ext.ColorTexture2DMultisampleInfo) *Texture {
+	var id uint32
+	gl.CreateTextures(gl.TEXTURE_2D_MULTISAMPLE, 1, &id)
+	internalFormat := glInternalFormat(info.Format, info.GammaCorrection)
+	gl.TextureStorage2DMultisample(id, int32(info.Samples), internalFormat, int32(info.Width), int32(info.Height), true)
+	return &Texture{
+		id:     id,
+		width:  info.Width,
+		height: info.Height,
+		levels: 1,
+	}
+}
+
+func NewDepthTexture2DMultisample(info ext.DepthTexture2DMultisampleInfo) *Texture {
+	internalFormat := uint32(gl.DEPTH_COMPONENT32)
+	if info.Format != render.DataFormatUnsupported {
+		if !isDepthFormat(info.Format) {
+			panic(fmt.Errorf("unsupported depth format %v", info.Format))
+		}
+		internalFormat = glInternalFormat(info.Format, false)
+	}
 
+	var id uint32
+	gl.CreateTextures(gl.TEXTURE_2D_MULTISAMPLE, 1, &id)
+	gl.TextureStorage2DMultisample(id, int32(info.Samples), internalFormat, int32(info.Width), int32(info.Height), true)
 	return &Texture{
-		id: id,
+		id:     id,
+		width:  info.Width,
+		height: info.Height,
+		levels: 1,
 	}
 }
 
 type Texture struct {
 	render.TextureObject
-	id uint32
+	id      uint32
+	width   int
+	height  int
+	levels  int32
+	layered bool
+
+	// compressedFormat is the internal format of textures that hold
+	// block-compressed data and zero for all others.
+	compressedFormat uint32
+}
+
+// ID returns the OpenGL name of this texture.
+func (t *Texture) ID() uint32 {
+	return t.id
+}
+
+func (t *Texture) Update(info ext.TextureUpdateInfo) {
+	updateTextureData(newCommandUpdateTextureData(t, info))
+}
+
+func (t *Texture) GenerateMipmaps() {
+	gl.GenerateTextureMipmap(t.id)
+}
+
+func (t *Texture) CopyToBuffer(info ext.TextureCopyToBufferInfo) {
+	copyTextureToBuffer(newCommandCopyTextureToBuffer(t, info))
 }
 
 func (t *Texture) Release() {
@@ -145,6 +329,184 @@ func (t *Texture) Release() {
 	t.id = 0
 }
 
+// levelSize returns the size of the specified mipmap level.
+func (t *Texture) levelSize(level int) (int, int) {
+	return max(1, t.width>>level), max(1, t.height>>level)
+}
+
+// region returns the specified region of a level, where a zero width or
+// height extends the region to the edge of the level.
+func (t *Texture) region(level, x, y, width, height int) (int32, int32) {
+	levelWidth, levelHeight := t.levelSize(level)
+	if width == 0 {
+		width = levelWidth - x
+	}
+	if height == 0 {
+		height = levelHeight - y
+	}
+	return int32(width), int32(height)
+}
+
+// newCommandUpdateTextureData returns the command that performs the
+// specified update, along with the data that it uploads. Block-compressed
+// data is decompressed if the texture is not stored compressed.
+func newCommandUpdateTextureData(texture render.Texture, info ext.TextureUpdateInfo) (CommandUpdateTextureData, []byte) {
+	intTexture := texture.(*Texture)
+	width, height := intTexture.region(info.Level, info.X, info.Y, info.Width, info.Height)
+	command := CommandUpdateTextureData{
+		TextureID: intTexture.id,
+		Layered:   intTexture.layered,
+		Level:     int32(info.Level),
+		Layer:     int32(info.Layer),
+		Layers:    int32(max(1, info.Layers)),
+		X:         int32(info.X),
+		Y:         int32(info.Y),
+		Width:     width,
+		Height:    height,
+	}
+	layers := 1
+	if intTexture.layered {
+		layers = int(command.Layers)
+	}
+	if size := textureDataSize(info.Format, int(width), int(height), layers); len(info.Data) < size {
+		panic(fmt.Errorf("texture data is %d bytes long instead of %d", len(info.Data), size))
+	}
+	data := info.Data
+	switch {
+	case ext.BlockSize(info.Format) == 0:
+		command.Format = glDataFormat(info.Format)
+		command.XType = glDataComponentType(info.Format)
+	case intTexture.compressedFormat != 0:
+		command.Compressed = true
+		command.Format = intTexture.compressedFormat
+	default:
+		data = decompressData(info.Format, int(width), int(height), int(command.Layers), data)
+		command.Format = gl.RGBA
+		command.XType = gl.UNSIGNED_BYTE
+	}
+	command.Count = uint32(len(data))
+	return command, data
+}
+
+func newCommandCopyTextureToBuffer(texture render.Texture, info ext.TextureCopyToBufferInfo) CommandCopyTextureToBuffer {
+	intTexture := texture.(*Texture)
+	width, height := intTexture.region(info.Level, info.X, info.Y, info.Width, info.Height)
+	layer, layers := int32(0), int32(1)
+	if intTexture.layered {
+		layer, layers = int32(info.Layer), int32(max(1, info.Layers))
+	}
+	return CommandCopyTextureToBuffer{
+		TextureID:    intTexture.id,
+		BufferID:     info.Buffer.(*Buffer).id,
+		Level:        int32(info.Level),
+		Layer:        layer,
+		Layers:       layers,
+		X:            int32(info.X),
+		Y:            int32(info.Y),
+		Width:        width,
+		Height:       height,
+		Format:       glDataFormat(info.Format),
+		XType:        glDataComponentType(info.Format),
+		BufferOffset: uint32(info.Offset),
+		BufferSize:   uint32(int(width) * int(height) * int(layers) * glDataPixelSize(info.Format)),
+	}
+}
+
+func updateTextureData(command CommandUpdateTextureData, data []byte) {
+	if len(data) == 0 {
+		return // decompression failed, which has been logged
+	}
+	if command.Compressed {
+		gl.CompressedTextureSubImage2D(command.TextureID, command.Level, command.X, command.Y, command.Width, command.Height, command.Format, int32(len(data)), gl.Ptr(&data[0]))
+		return
+	}
+	if command.Layered {
+		gl.TextureSubImage3D(command.TextureID, command.Level, command.X, command.Y, command.Layer, command.Width, command.Height, command.Layers, command.Format, command.XType, gl.Ptr(&data[0]))
+	} else {
+		gl.TextureSubImage2D(command.TextureID, command.Level, command.X, command.Y, command.Width, command.Height, command.Format, command.XType, gl.Ptr(&data[0]))
+	}
+}
+
+// textureDataSize returns the size, in bytes, of the data of a texture
+// region with the specified format and number of layers.
+func textureDataSize(format render.DataFormat, width, height, layers int) int {
+	if blockSize := ext.BlockSize(format); blockSize > 0 {
+		return ((width + 3) / 4) * ((height + 3) / 4) * blockSize * layers
+	}
+	return width * height * glDataPixelSize(format) * layers
+}
+
+func copyTextureToBuffer(command CommandCopyTextureToBuffer) {
+	gl.BindBuffer(gl.PIXEL_PACK_BUFFER, command.BufferID)
+	gl.GetTextureSubImage(
+		command.TextureID,
+		command.Level,
+		command.X,
+		command.Y,
+		command.Layer,
+		command.Width,
+		command.Height,
+		command.Layers,
+		command.Format,
+		command.XType,
+		int32(command.BufferSize),
+		gl.PtrOffset(int(command.BufferOffset)),
+	)
+	gl.BindBuffer(gl.PIXEL_PACK_BUFFER, 0)
+}
+
+// decompressData decodes block-compressed data with the specified number
+// of layers to RGBA8. It returns nil if the data cannot be decoded.
+func decompressData(format render.DataFormat, width, height, layers int, data []byte) []byte {
+	layerSize := textureDataSize(format, width, height, 1)
+	result := make([]byte, 0, width*height*layers*4)
+	for layer := 0; layer < layers; layer++ {
+		if len(data) < (layer+1)*layerSize {
+			log.Error("Failed to decompress texture data: data is %d bytes long instead of %d", len(data), layers*layerSize)
+			return nil
+		}
+		pixels, err := decompress.RGBA8(format, width, height, data[layer*layerSize:(layer+1)*layerSize])
+		if err != nil {
+			log.Error("Failed to decompress texture data: %v", err)
+			return nil
+		}
+		result = append(result, pixels...)
+	}
+	return result
+}
+
+// setColorFiltering configures how a color texture is filtered.
+func setColorFiltering(id uint32, filtering render.FilterMode, mipmapping bool, format render.DataFormat) {
+	if isIntegerFormat(format) {
+		filtering = render.FilterModeNearest // integer textures cannot be filtered
+	}
+	gl.TextureParameteri(id, gl.TEXTURE_MIN_FILTER, glFilter(filtering, mipmapping))
+	gl.TextureParameteri(id, gl.TEXTURE_MAG_FILTER, glFilter(filtering, false)) // no mipmaps when magnification
+	if filtering == render.FilterModeAnisotropic {
+		var maxAnisotropy float32
+		gl.GetFloatv(gl.MAX_TEXTURE_MAX_ANISOTROPY, &maxAnisotropy)
+		gl.TextureParameterf(id, gl.TEXTURE_MAX_ANISOTROPY, maxAnisotropy)
+	}
+}
+
+// setDepthSampling configures how a depth texture is sampled.
+func setDepthSampling(id uint32, clippedValue *float32, comparable bool) {
+	if clippedValue != nil {
+		gl.TextureParameteri(id, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_BORDER)
+		gl.TextureParameteri(id, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_BORDER)
+		borderColor := []float32{*clippedValue, *clippedValue, *clippedValue, *clippedValue}
+		gl.TextureParameterfv(id, gl.TEXTURE_BORDER_COLOR, &borderColor[0])
+	} else {
+		gl.TextureParameteri(id, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+		gl.TextureParameteri(id, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+	}
+	gl.TextureParameteri(id, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+	gl.TextureParameteri(id, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+	if comparable {
+		gl.TextureParameteri(id, gl.TEXTURE_COMPARE_MODE, gl.COMPARE_REF_TO_TEXTURE)
+	}
+}
+
 func glWrap(wrap render.WrapMode) int32 {
 	switch wrap {
 	case render.WrapModeClamp:
@@ -187,39 +549,3 @@ func glMipmapLevels(width, height int, mipmapping bool) int32 {
 	}
 	return count
 }
-
-func glInternalFormat(format render.DataFormat, gammaCorrection bool) uint32 {
-	switch format {
-	case render.DataFormatRGBA8:
-		if gammaCorrection {
-			return gl.SRGB8_ALPHA8
-		}
-		return gl.RGBA8
-	case render.DataFormatRGBA16F:
-		return gl.RGBA16F
-	case render.DataFormatRGBA32F:
-		return gl.RGBA32F
-	default:
-		return gl.RGBA8
-	}
-}
-
-func glDataFormat(format render.DataFormat) uint32 {
-	switch format {
-	default:
-		return gl.RGBA
-	}
-}
-
-func glDataComponentType(format render.DataFormat) uint32 {
-	switch format {
-	case render.DataFormatRGBA8:
-		return gl.UNSIGNED_BYTE
-	case render.DataFormatRGBA16F:
-		return gl.HALF_FLOAT
-	case render.DataFormatRGBA32F:
-		return gl.FLOAT
-	default:
-		return gl.UNSIGNED_BYTE
-	}
-}
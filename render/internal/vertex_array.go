@@ -44,6 +44,11 @@ type VertexArray struct {
 	indexFormat uint32
 }
 
+// ID returns the OpenGL name of this vertex array.
+func (a *VertexArray) ID() uint32 {
+	return a.id
+}
+
 func (a *VertexArray) Release() {
 	gl.DeleteVertexArrays(1, &a.id)
 	a.id = 0
@@ -0,0 +1,64 @@
+// Package profile measures the GPU time that is spent on render passes.
+//
+// A Profiler wraps an API and surrounds each render pass with timestamp
+// queries. The results are read back a few frames later, once the GPU
+// has made them available, so profiling does not stall the CPU.
+// Completed frames are delivered as Frame values, which can be exported
+// in the Chrome trace event format and compared offline.
+package profile
+
+import "time"
+
+// Pass holds the GPU timing of a single render pass.
+type Pass struct {
+
+	// Name identifies the render pass. It is either the name that was
+	// specified through Profiler.NamePass or a name derived from the
+	// position of the pass within the frame.
+	Name string
+
+	// Start is the GPU time at which the pass started. It is measured
+	// from an arbitrary point, which is the same for all passes.
+	Start time.Duration
+
+	// Duration is the GPU time that was spent on the pass.
+	Duration time.Duration
+}
+
+// End returns the GPU time at which the pass ended.
+func (p Pass) End() time.Duration {
+	return p.Start + p.Duration
+}
+
+// Frame holds the GPU timings of the render passes of a single frame.
+type Frame struct {
+
+	// Index is the sequence number of the frame, starting from zero.
+	Index int
+
+	// Passes holds the timings of the render passes, in the order in
+	// which they were issued.
+	Passes []Pass
+}
+
+// Start returns the GPU time at which the first pass of the frame
+// started.
+func (f Frame) Start() time.Duration {
+	if len(f.Passes) == 0 {
+		return 0
+	}
+	return f.Passes[0].Start
+}
+
+// Duration returns the GPU time from the start of the first pass to the
+// end of the last one.
+func (f Frame) Duration() time.Duration {
+	if len(f.Passes) == 0 {
+		return 0
+	}
+	return f.Passes[len(f.Passes)-1].End() - f.Start()
+}
+
+// Handler is a function that is called for each frame whose timings
+// have become available.
+type Handler func(frame Frame)
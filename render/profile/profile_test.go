@@ -0,0 +1,263 @@
+package profile
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mokiat/lacking-gl/render/ext"
+	"github.com/mokiat/lacking/render"
+)
+
+func TestWriteChromeTrace(t *testing.T) {
+	testCases := []struct {
+		name     string
+		frames   []Frame
+		expected []string
+	}{
+		{
+			name:     "no frames",
+			frames:   nil,
+			expected: nil,
+		},
+		{
+			name: "multiple frames",
+			frames: []Frame{
+				{
+					Index: 3,
+					Passes: []Pass{
+						{Name: "shadow", Start: 2 * time.Millisecond, Duration: 500 * time.Microsecond},
+						{Name: "main", Start: 2500 * time.Microsecond, Duration: 1250 * time.Microsecond},
+					},
+				},
+				{
+					Index: 4,
+				},
+				{
+					Index: 5,
+					Passes: []Pass{
+						{Name: "shadow", Start: 18 * time.Millisecond, Duration: 750 * time.Microsecond},
+					},
+				},
+			},
+			expected: []string{
+				`{"name":"Frame 3","cat":"frame","ph":"X","ts":0,"dur":1750,"pid":1,"tid":1,"args":{"frame":3}}`,
+				`{"name":"shadow","cat":"pass","ph":"X","ts":0,"dur":500,"pid":1,"tid":1,"args":{"frame":3}}`,
+				`{"name":"main","cat":"pass","ph":"X","ts":500,"dur":1250,"pid":1,"tid":1,"args":{"frame":3}}`,
+				`{"name":"Frame 5","cat":"frame","ph":"X","ts":16000,"dur":750,"pid":1,"tid":1,"args":{"frame":5}}`,
+				`{"name":"shadow","cat":"pass","ph":"X","ts":16000,"dur":750,"pid":1,"tid":1,"args":{"frame":5}}`,
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := WriteChromeTrace(&out, tc.frames); err != nil {
+				t.Fatalf("failed to write trace: %v", err)
+			}
+			expected := fmt.Sprintf(`{"traceEvents":[%s],"displayTimeUnit":"ms"}`+"\n", strings.Join(tc.expected, ","))
+			if actual := out.String(); actual != expected {
+				t.Errorf("expected %s, got %s", expected, actual)
+			}
+		})
+	}
+}
+
+func TestProfiler(t *testing.T) {
+	api := newFakeAPI()
+	var frames []Frame
+	profiler := NewProfiler(api, func(frame Frame) {
+		frames = append(frames, frame)
+	})
+
+	// The results of the first frame are not available by the time that
+	// it ends, so the second frame needs queries of its own.
+	api.delayed = true
+	profiler.NamePass("shadow")
+	profiler.BeginRenderPass(render.RenderPassInfo{})
+	profiler.EndRenderPass()
+	profiler.BeginRenderPass(render.RenderPassInfo{})
+	profiler.EndRenderPass()
+	profiler.EndFrame()
+	if len(frames) != 0 {
+		t.Fatalf("expected no frames, got %v", frames)
+	}
+
+	api.complete()
+	profiler.BeginRenderPass(render.RenderPassInfo{})
+	profiler.EndRenderPass()
+	profiler.EndFrame()
+	expectedFrames := []Frame{
+		{
+			Index: 0,
+			Passes: []Pass{
+				{Name: "shadow", Start: 1000, Duration: 1000},
+				{Name: "Pass 1", Start: 3000, Duration: 1000},
+			},
+		},
+	}
+	if !reflect.DeepEqual(expectedFrames, frames) {
+		t.Fatalf("expected %v, got %v", expectedFrames, frames)
+	}
+
+	// The third frame reuses the queries of the first one, but is only
+	// delivered after the second frame, whose results are still delayed.
+	api.delayed = false
+	profiler.BeginRenderPass(render.RenderPassInfo{})
+	profiler.EndRenderPass()
+	profiler.BeginRenderPass(render.RenderPassInfo{})
+	profiler.EndRenderPass()
+	profiler.EndFrame()
+	if len(frames) != 1 {
+		t.Fatalf("expected one frame, got %v", frames)
+	}
+
+	// Frames without passes are not delivered.
+	api.complete()
+	profiler.EndFrame()
+	expectedFrames = append(expectedFrames,
+		Frame{
+			Index: 1,
+			Passes: []Pass{
+				{Name: "Pass 0", Start: 5000, Duration: 1000},
+			},
+		},
+		Frame{
+			Index: 2,
+			Passes: []Pass{
+				{Name: "Pass 0", Start: 7000, Duration: 1000},
+				{Name: "Pass 1", Start: 9000, Duration: 1000},
+			},
+		},
+	)
+	if !reflect.DeepEqual(expectedFrames, frames) {
+		t.Fatalf("expected %v, got %v", expectedFrames, frames)
+	}
+
+	expectedCalls := []string{
+		"CreateQuery 1", "CreateQuery 2", "WriteTimestamp 1", "BeginRenderPass", "EndRenderPass", "WriteTimestamp 2",
+		"CreateQuery 3", "CreateQuery 4", "WriteTimestamp 3", "BeginRenderPass", "EndRenderPass", "WriteTimestamp 4",
+		"CreateQuery 5", "CreateQuery 6", "WriteTimestamp 5", "BeginRenderPass", "EndRenderPass", "WriteTimestamp 6",
+		"WriteTimestamp 4", "BeginRenderPass", "EndRenderPass", "WriteTimestamp 3",
+		"WriteTimestamp 2", "BeginRenderPass", "EndRenderPass", "WriteTimestamp 1",
+	}
+	if !reflect.DeepEqual(expectedCalls, api.calls) {
+		t.Errorf("expected %v, got %v", expectedCalls, api.calls)
+	}
+}
+
+func TestProfilerDropsStaleFrames(t *testing.T) {
+	api := newFakeAPI()
+	var indices []int
+	profiler := NewProfiler(api, func(frame Frame) {
+		indices = append(indices, frame.Index)
+	})
+
+	api.delayed = true
+	for i := 0; i < maxPendingFrames+2; i++ {
+		profiler.BeginRenderPass(render.RenderPassInfo{})
+		profiler.EndRenderPass()
+		profiler.EndFrame()
+	}
+	api.complete()
+	profiler.EndFrame()
+
+	// The oldest frames are dropped whenever the limit is exceeded and the
+	// last frame reuses the queries of the first one.
+	expectedIndices := []int{2, 3, 4, 5, 6, 7, 8, 9}
+	if !reflect.DeepEqual(expectedIndices, indices) {
+		t.Errorf("expected %v, got %v", expectedIndices, indices)
+	}
+	if expected := 2 * (maxPendingFrames + 1); len(api.queries) != expected {
+		t.Errorf("expected %d queries, got %d", expected, len(api.queries))
+	}
+
+	profiler.Release()
+	for _, query := range api.queries {
+		if !query.released {
+			t.Errorf("expected query %d to be released", query.id)
+		}
+	}
+}
+
+// newFakeAPI creates an ext.API whose GPU clock advances by a microsecond
+// with each timestamp that is written.
+func newFakeAPI() *fakeAPI {
+	return &fakeAPI{}
+}
+
+// fakeAPI is an ext.API that logs the calls that are made to it. Calls
+// that are not implemented panic.
+type fakeAPI struct {
+	ext.API
+	clock   uint64
+	delayed bool
+	queries []*fakeQuery
+	calls   []string
+}
+
+func (a *fakeAPI) log(format string, args ...any) {
+	a.calls = append(a.calls, fmt.Sprintf(format, args...))
+}
+
+// complete makes the results of all written timestamps available.
+func (a *fakeAPI) complete() {
+	for _, query := range a.queries {
+		query.available = true
+	}
+}
+
+func (a *fakeAPI) CreateQuery(info ext.QueryInfo) ext.Query {
+	if info.Kind != ext.QueryKindTimestamp {
+		panic(fmt.Errorf("unexpected query kind: %v", info.Kind))
+	}
+	query := &fakeQuery{
+		id: len(a.queries) + 1,
+	}
+	a.queries = append(a.queries, query)
+	a.log("CreateQuery %d", query.id)
+	return query
+}
+
+func (a *fakeAPI) WriteTimestamp(query ext.Query) {
+	fake := query.(*fakeQuery)
+	a.clock += uint64(time.Microsecond)
+	fake.result = a.clock
+	fake.available = !a.delayed
+	a.log("WriteTimestamp %d", fake.id)
+}
+
+func (a *fakeAPI) BeginRenderPass(info render.RenderPassInfo) {
+	a.log("BeginRenderPass")
+}
+
+func (a *fakeAPI) EndRenderPass() {
+	a.log("EndRenderPass")
+}
+
+// fakeQuery is an ext.Query of fakeAPI.
+type fakeQuery struct {
+	ext.Query
+	id        int
+	result    uint64
+	available bool
+	released  bool
+}
+
+func (q *fakeQuery) Available() bool {
+	return q.available
+}
+
+func (q *fakeQuery) Result() uint64 {
+	if !q.available {
+		panic(fmt.Errorf("result of query %d is not available", q.id))
+	}
+	return q.result
+}
+
+func (q *fakeQuery) Release() {
+	q.released = true
+}
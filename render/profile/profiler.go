@@ -0,0 +1,165 @@
+package profile
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mokiat/lacking-gl/render/ext"
+	"github.com/mokiat/lacking/render"
+)
+
+// maxPendingFrames is the number of frames that can wait for their
+// results. When exceeded, the oldest frame is dropped, instead of
+// waiting for the GPU to catch up.
+const maxPendingFrames = 8
+
+// NewProfiler creates a new Profiler that forwards all calls to the
+// specified API and measures the GPU time of each render pass. The
+// handler is called from EndFrame for each frame whose timings have
+// become available.
+func NewProfiler(delegate ext.API, handler Handler) *Profiler {
+	return &Profiler{
+		API:     delegate,
+		handler: handler,
+	}
+}
+
+var _ ext.API = (*Profiler)(nil)
+
+// Profiler is an ext.API that measures the GPU time of render passes.
+type Profiler struct {
+	ext.API
+	handler Handler
+
+	queries    []ext.Query
+	current    pendingFrame
+	pending    []pendingFrame
+	frameIndex int
+	passName   string
+}
+
+// NamePass specifies the name of the next render pass. Passes that are
+// not named are called after their position within the frame.
+func (p *Profiler) NamePass(name string) {
+	p.passName = name
+}
+
+func (p *Profiler) BeginRenderPass(info render.RenderPassInfo) {
+	name := p.passName
+	if name == "" {
+		name = fmt.Sprintf("Pass %d", len(p.current.passes))
+	}
+	p.passName = ""
+	pass := pendingPass{
+		name:  name,
+		start: p.acquireQuery(),
+		end:   p.acquireQuery(),
+	}
+	p.current.passes = append(p.current.passes, pass)
+	p.API.WriteTimestamp(pass.start)
+	p.API.BeginRenderPass(info)
+}
+
+func (p *Profiler) EndRenderPass() {
+	p.API.EndRenderPass()
+	if count := len(p.current.passes); count > 0 {
+		p.API.WriteTimestamp(p.current.passes[count-1].end)
+	}
+}
+
+// EndFrame marks the end of the current frame and delivers the timings
+// of all earlier frames whose results have become available.
+func (p *Profiler) EndFrame() {
+	p.current.index = p.frameIndex
+	p.frameIndex++
+	if len(p.current.passes) > 0 {
+		p.pending = append(p.pending, p.current)
+	}
+	p.current = pendingFrame{}
+
+	for len(p.pending) > 0 && p.pending[0].available() {
+		frame := p.pending[0]
+		p.pending = p.pending[1:]
+		result := frame.result()
+		p.releaseFrame(frame)
+		if p.handler != nil {
+			p.handler(result)
+		}
+	}
+	for len(p.pending) > maxPendingFrames {
+		p.releaseFrame(p.pending[0])
+		p.pending = p.pending[1:]
+	}
+}
+
+// Release deletes all queries that are used by the profiler. Frames
+// that are still waiting for their results are not delivered.
+func (p *Profiler) Release() {
+	for _, frame := range p.pending {
+		p.releaseFrame(frame)
+	}
+	p.releaseFrame(p.current)
+	for _, query := range p.queries {
+		query.Release()
+	}
+	p.pending = nil
+	p.current = pendingFrame{}
+	p.queries = nil
+}
+
+func (p *Profiler) acquireQuery() ext.Query {
+	if count := len(p.queries); count > 0 {
+		query := p.queries[count-1]
+		p.queries = p.queries[:count-1]
+		return query
+	}
+	return p.API.CreateQuery(ext.QueryInfo{
+		Kind: ext.QueryKindTimestamp,
+	})
+}
+
+// releaseFrame returns the queries of the specified frame to the pool.
+// A query that is still pending can be reused, since issuing it again
+// simply replaces its result.
+func (p *Profiler) releaseFrame(frame pendingFrame) {
+	for _, pass := range frame.passes {
+		p.queries = append(p.queries, pass.start, pass.end)
+	}
+}
+
+type pendingFrame struct {
+	index  int
+	passes []pendingPass
+}
+
+func (f pendingFrame) available() bool {
+	for _, pass := range f.passes {
+		if !pass.start.Available() || !pass.end.Available() {
+			return false
+		}
+	}
+	return true
+}
+
+func (f pendingFrame) result() Frame {
+	passes := make([]Pass, len(f.passes))
+	for i, pass := range f.passes {
+		start := pass.start.Result()
+		end := pass.end.Result()
+		passes[i] = Pass{
+			Name:     pass.name,
+			Start:    time.Duration(start),
+			Duration: time.Duration(end - start),
+		}
+	}
+	return Frame{
+		Index:  f.index,
+		Passes: passes,
+	}
+}
+
+type pendingPass struct {
+	name  string
+	start ext.Query
+	end   ext.Query
+}
@@ -0,0 +1,80 @@
+package profile
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// WriteChromeTrace writes the specified frames in the Chrome trace event
+// JSON format, which can be opened with chrome://tracing or Perfetto.
+//
+// Each frame is written as an event that contains the events of its
+// render passes. Times are relative to the start of the first frame.
+func WriteChromeTrace(out io.Writer, frames []Frame) error {
+	trace := chromeTrace{
+		TraceEvents:     []chromeEvent{},
+		DisplayTimeUnit: "ms",
+	}
+	if len(frames) > 0 {
+		origin := frames[0].Start()
+		for _, frame := range frames {
+			if len(frame.Passes) == 0 {
+				continue
+			}
+			trace.TraceEvents = append(trace.TraceEvents, newChromeEvent(
+				fmt.Sprintf("Frame %d", frame.Index), "frame", frame.Index,
+				frame.Start()-origin, frame.Duration(),
+			))
+			for _, pass := range frame.Passes {
+				trace.TraceEvents = append(trace.TraceEvents, newChromeEvent(
+					pass.Name, "pass", frame.Index,
+					pass.Start-origin, pass.Duration,
+				))
+			}
+		}
+	}
+	if err := json.NewEncoder(out).Encode(trace); err != nil {
+		return fmt.Errorf("failed to encode trace: %w", err)
+	}
+	return nil
+}
+
+type chromeTrace struct {
+	TraceEvents     []chromeEvent `json:"traceEvents"`
+	DisplayTimeUnit string        `json:"displayTimeUnit"`
+}
+
+// chromeEvent is a complete ("X") event of the trace event format.
+type chromeEvent struct {
+	Name      string         `json:"name"`
+	Category  string         `json:"cat"`
+	Phase     string         `json:"ph"`
+	Timestamp float64        `json:"ts"`
+	Duration  float64        `json:"dur"`
+	ProcessID int            `json:"pid"`
+	ThreadID  int            `json:"tid"`
+	Args      map[string]any `json:"args"`
+}
+
+func newChromeEvent(name, category string, frame int, start, duration time.Duration) chromeEvent {
+	return chromeEvent{
+		Name:      name,
+		Category:  category,
+		Phase:     "X",
+		Timestamp: microseconds(start),
+		Duration:  microseconds(duration),
+		ProcessID: 1,
+		ThreadID:  1,
+		Args: map[string]any{
+			"frame": frame,
+		},
+	}
+}
+
+// microseconds converts the duration to the unit of the trace event
+// format.
+func microseconds(duration time.Duration) float64 {
+	return float64(duration) / float64(time.Microsecond)
+}
@@ -0,0 +1,20 @@
+package render
+
+import (
+	"github.com/mokiat/lacking-gl/render/ext"
+	"github.com/mokiat/lacking-gl/render/internal"
+	"github.com/mokiat/lacking/log"
+)
+
+// SetProgramCache specifies a cache for linked program binaries.
+func (a *API) SetProgramCache(cache ext.ProgramCache) {
+	switch {
+	case cache == nil:
+		a.programCache = nil
+	case a.capabilities.ProgramBinaryFormats == 0:
+		log.Warn("Program cache not used, since the driver supports no binary formats")
+		a.programCache = nil
+	default:
+		a.programCache = internal.NewProgramCache(cache, a.capabilities)
+	}
+}
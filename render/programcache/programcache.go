@@ -0,0 +1,233 @@
+// Package programcache provides an on-disk implementation of
+// ext.ProgramCache.
+//
+// Each program binary is stored as a separate file. When the total size
+// of the files exceeds the configured limit, the least recently used
+// ones are removed.
+package programcache
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mokiat/lacking-gl/render/ext"
+	"github.com/mokiat/lacking/log"
+)
+
+// DefaultMaxSize is the total size limit, in bytes, that is used when no
+// limit is specified.
+const DefaultMaxSize = 64 * 1024 * 1024
+
+const fileExtension = ".bin"
+
+var logger = log.Path("/lacking-gl/render/programcache")
+
+var _ ext.ProgramCache = (*DiskCache)(nil)
+
+// NewDiskCache creates a new DiskCache that stores files in the specified
+// directory, which is created if it does not exist. The maxSize parameter
+// limits the total size of the cache in bytes. If it is zero or negative,
+// DefaultMaxSize is used.
+func NewDiskCache(dir string, maxSize int64) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+	return &DiskCache{
+		dir:     dir,
+		maxSize: maxSize,
+	}, nil
+}
+
+// DiskCache is an ext.ProgramCache that stores program binaries as files
+// in a directory. It is safe for concurrent use.
+//
+// Failures to read or write files are logged and treated as cache misses,
+// since the program can always be compiled instead.
+type DiskCache struct {
+	mu      sync.Mutex
+	dir     string
+	maxSize int64
+}
+
+// Load returns the data that is stored under the specified key.
+func (c *DiskCache) Load(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	path := c.path(key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			logger.Warn("Error reading cached program: %v", err)
+		}
+		return nil, false
+	}
+	// The modification time tracks when the entry was last used, which
+	// determines the order in which entries are evicted.
+	now := time.Now()
+	if err := os.Chtimes(path, now, now); err != nil {
+		logger.Warn("Error updating cached program time: %v", err)
+	}
+	return data, true
+}
+
+// Store saves the data under the specified key. Data that is larger than
+// the size limit of the cache is not stored.
+func (c *DiskCache) Store(key string, data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if int64(len(data)) > c.maxSize {
+		return
+	}
+	if err := c.write(key, data); err != nil {
+		logger.Warn("Error storing cached program: %v", err)
+		return
+	}
+	if err := c.prune(); err != nil {
+		logger.Warn("Error pruning program cache: %v", err)
+	}
+}
+
+// Remove deletes the data that is stored under the specified key.
+func (c *DiskCache) Remove(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := os.Remove(c.path(key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		logger.Warn("Error removing cached program: %v", err)
+	}
+}
+
+// Clear removes all entries from the cache. This can be used to force
+// all programs to be compiled again.
+func (c *DiskCache) Clear() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries, err := c.entries()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, entry := range entries {
+		if err := os.Remove(entry.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("failed to remove cache entries: %w", err)
+	}
+	return nil
+}
+
+// Size returns the total size, in bytes, of all entries in the cache.
+func (c *DiskCache) Size() (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries, err := c.entries()
+	if err != nil {
+		return 0, err
+	}
+	var size int64
+	for _, entry := range entries {
+		size += entry.size
+	}
+	return size, nil
+}
+
+func (c *DiskCache) path(key string) string {
+	return filepath.Join(c.dir, key+fileExtension)
+}
+
+// write stores the data through a temporary file, so that a partially
+// written entry is never loaded.
+func (c *DiskCache) write(key string, data []byte) error {
+	file, err := os.CreateTemp(c.dir, key+"-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return fmt.Errorf("failed to close file: %w", err)
+	}
+	if err := os.Rename(file.Name(), c.path(key)); err != nil {
+		os.Remove(file.Name())
+		return fmt.Errorf("failed to rename file: %w", err)
+	}
+	return nil
+}
+
+// prune removes the least recently used entries until the total size is
+// within the limit.
+func (c *DiskCache) prune() error {
+	entries, err := c.entries()
+	if err != nil {
+		return err
+	}
+	var size int64
+	for _, entry := range entries {
+		size += entry.size
+	}
+	slices.SortFunc(entries, func(a, b cacheEntry) int {
+		return a.modTime.Compare(b.modTime)
+	})
+	for _, entry := range entries {
+		if size <= c.maxSize {
+			break
+		}
+		if err := os.Remove(entry.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to remove entry: %w", err)
+		}
+		size -= entry.size
+	}
+	return nil
+}
+
+func (c *DiskCache) entries() ([]cacheEntry, error) {
+	dirEntries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache directory: %w", err)
+	}
+	var result []cacheEntry
+	for _, dirEntry := range dirEntries {
+		if dirEntry.IsDir() || !strings.HasSuffix(dirEntry.Name(), fileExtension) {
+			continue
+		}
+		info, err := dirEntry.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to stat entry: %w", err)
+		}
+		result = append(result, cacheEntry{
+			path:    filepath.Join(c.dir, dirEntry.Name()),
+			size:    info.Size(),
+			modTime: info.ModTime(),
+		})
+	}
+	return result, nil
+}
+
+type cacheEntry struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
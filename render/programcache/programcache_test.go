@@ -0,0 +1,159 @@
+package programcache
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiskCacheStoreLoad(t *testing.T) {
+	cache := newTestCache(t, 0)
+	if cache.maxSize != DefaultMaxSize {
+		t.Errorf("expected %d, got %d", DefaultMaxSize, cache.maxSize)
+	}
+
+	if _, ok := cache.Load("missing"); ok {
+		t.Errorf("expected missing entry not to be loaded")
+	}
+	cache.Store("first", []byte{1, 2, 3})
+	cache.Store("first", []byte{4, 5})
+	assertEntry(t, cache, "first", []byte{4, 5})
+
+	cache.Remove("first")
+	if _, ok := cache.Load("first"); ok {
+		t.Errorf("expected removed entry not to be loaded")
+	}
+	cache.Remove("first")
+
+	names := dirNames(t, cache.dir)
+	if len(names) != 0 {
+		t.Errorf("expected no files, got %v", names)
+	}
+}
+
+func TestDiskCachePrune(t *testing.T) {
+	cache := newTestCache(t, 10)
+	cache.Store("first", []byte{1, 2, 3, 4})
+	cache.Store("second", []byte{1, 2, 3, 4})
+	setModTime(t, cache, "first", time.Now().Add(-2*time.Hour))
+	setModTime(t, cache, "second", time.Now().Add(-time.Hour))
+
+	// Loading the first entry makes it the most recently used one, so
+	// the second entry is evicted instead.
+	assertEntry(t, cache, "first", []byte{1, 2, 3, 4})
+	cache.Store("third", []byte{5, 6, 7, 8})
+	if _, ok := cache.Load("second"); ok {
+		t.Errorf("expected least recently used entry to be evicted")
+	}
+	assertEntry(t, cache, "first", []byte{1, 2, 3, 4})
+	assertEntry(t, cache, "third", []byte{5, 6, 7, 8})
+	assertSize(t, cache, 8)
+}
+
+func TestDiskCacheLoadRefreshesModTime(t *testing.T) {
+	cache := newTestCache(t, 0)
+	cache.Store("first", []byte{1, 2, 3})
+	old := time.Now().Add(-time.Hour)
+	setModTime(t, cache, "first", old)
+
+	assertEntry(t, cache, "first", []byte{1, 2, 3})
+	info, err := os.Stat(cache.path("first"))
+	if err != nil {
+		t.Fatalf("failed to stat entry: %v", err)
+	}
+	if !info.ModTime().After(old) {
+		t.Errorf("expected modification time after %v, got %v", old, info.ModTime())
+	}
+}
+
+func TestDiskCacheRejectsOversizedEntries(t *testing.T) {
+	cache := newTestCache(t, 4)
+	cache.Store("small", []byte{1, 2, 3, 4})
+	cache.Store("large", []byte{1, 2, 3, 4, 5})
+
+	if _, ok := cache.Load("large"); ok {
+		t.Errorf("expected oversized entry not to be stored")
+	}
+	// The existing entry is not evicted to make room.
+	assertEntry(t, cache, "small", []byte{1, 2, 3, 4})
+	assertSize(t, cache, 4)
+}
+
+func TestDiskCacheClear(t *testing.T) {
+	cache := newTestCache(t, 0)
+	assertSize(t, cache, 0)
+	cache.Store("first", []byte{1, 2, 3})
+	cache.Store("second", []byte{1, 2, 3, 4, 5})
+
+	// Files that are not cache entries are neither counted nor removed.
+	otherPath := filepath.Join(cache.dir, "other.txt")
+	if err := os.WriteFile(otherPath, []byte("other"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	assertSize(t, cache, 8)
+
+	if err := cache.Clear(); err != nil {
+		t.Fatalf("failed to clear cache: %v", err)
+	}
+	assertSize(t, cache, 0)
+	if _, ok := cache.Load("first"); ok {
+		t.Errorf("expected cleared entry not to be loaded")
+	}
+	expectedNames := []string{"other.txt"}
+	if names := dirNames(t, cache.dir); !reflect.DeepEqual(expectedNames, names) {
+		t.Errorf("expected %v, got %v", expectedNames, names)
+	}
+}
+
+func newTestCache(t *testing.T, maxSize int64) *DiskCache {
+	t.Helper()
+	cache, err := NewDiskCache(filepath.Join(t.TempDir(), "programs"), maxSize)
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	return cache
+}
+
+func assertEntry(t *testing.T, cache *DiskCache, key string, expected []byte) {
+	t.Helper()
+	data, ok := cache.Load(key)
+	if !ok {
+		t.Fatalf("expected entry %q to be loaded", key)
+	}
+	if !reflect.DeepEqual(expected, data) {
+		t.Errorf("expected %v, got %v", expected, data)
+	}
+}
+
+func assertSize(t *testing.T, cache *DiskCache, expected int64) {
+	t.Helper()
+	size, err := cache.Size()
+	if err != nil {
+		t.Fatalf("failed to get size: %v", err)
+	}
+	if size != expected {
+		t.Errorf("expected %d, got %d", expected, size)
+	}
+}
+
+func setModTime(t *testing.T, cache *DiskCache, key string, modTime time.Time) {
+	t.Helper()
+	if err := os.Chtimes(cache.path(key), modTime, modTime); err != nil {
+		t.Fatalf("failed to change times: %v", err)
+	}
+}
+
+func dirNames(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to read directory: %v", err)
+	}
+	var result []string
+	for _, entry := range entries {
+		result = append(result, entry.Name())
+	}
+	return result
+}
@@ -0,0 +1,63 @@
+package render
+
+import (
+	"image"
+
+	"github.com/mokiat/lacking/render"
+)
+
+// ReadPixels reads back the color content of the specified framebuffer
+// region, starting at the bottom-left corner, and returns it as an image.
+//
+// The content is transferred through a pixel transfer buffer, so this
+// function blocks until all previously submitted rendering has completed.
+// Rows are flipped so that the returned image is top-down.
+func ReadPixels(api render.API, framebuffer render.Framebuffer, width, height int) *image.RGBA {
+	const bytesPerPixel = 4
+	rowSize := width * bytesPerPixel
+
+	buffer := api.CreatePixelTransferBuffer(render.BufferInfo{
+		Dynamic: true,
+		Size:    rowSize * height,
+	})
+	defer buffer.Release()
+
+	queue := api.CreateCommandQueue()
+	defer queue.Release()
+
+	api.BeginRenderPass(render.RenderPassInfo{
+		Framebuffer: framebuffer,
+		Viewport: render.Area{
+			Width:  width,
+			Height: height,
+		},
+		Colors: [4]render.ColorAttachmentInfo{
+			{
+				StoreOp: render.StoreOperationStore,
+			},
+		},
+		DepthStoreOp:   render.StoreOperationStore,
+		StencilStoreOp: render.StoreOperationStore,
+	})
+	queue.CopyContentToBuffer(render.CopyContentToBufferInfo{
+		Buffer: buffer,
+		Width:  width,
+		Height: height,
+		Format: render.DataFormatRGBA8,
+	})
+	api.SubmitQueue(queue)
+	api.EndRenderPass()
+
+	data := make([]byte, rowSize*height)
+	buffer.Fetch(render.BufferFetchInfo{
+		Target: data,
+	})
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		srcOffset := (height - y - 1) * rowSize
+		dstOffset := y * img.Stride
+		copy(img.Pix[dstOffset:dstOffset+rowSize], data[srcOffset:srcOffset+rowSize])
+	}
+	return img
+}
@@ -0,0 +1,13 @@
+package render
+
+import (
+	"github.com/mokiat/lacking-gl/render/ext"
+	"github.com/mokiat/lacking-gl/render/internal"
+	"github.com/mokiat/lacking/render"
+)
+
+// ProgramReflection returns the active interface of the specified
+// program.
+func (a *API) ProgramReflection(program render.Program) ext.ProgramReflection {
+	return program.(*internal.Program).Reflection()
+}
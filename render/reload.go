@@ -0,0 +1,47 @@
+package render
+
+import (
+	"slices"
+
+	"github.com/mokiat/lacking-gl/render/internal"
+)
+
+// ReloadPrograms rebuilds the live programs whose shaders have new source
+// code according to the specified rewrite function.
+func (a *API) ReloadPrograms(rewrite func(source string) (string, bool)) {
+	a.prunePrograms()
+	for _, program := range a.programs {
+		sources := slices.Clone(program.Sources())
+		var changed bool
+		for i, source := range sources {
+			if source.IsSPIRV() {
+				continue
+			}
+			if code, ok := rewrite(source.Code); ok && code != source.Code {
+				sources[i].Code = code
+				changed = true
+			}
+		}
+		if changed {
+			a.reportDiagnostics(program.Rebuild(sources, a.programCache, a.renderer))
+		}
+	}
+}
+
+// trackProgram keeps track of the specified program, so that it can be
+// reloaded later on.
+func (a *API) trackProgram(program *internal.Program) {
+	// Released programs are only dropped when the list has doubled in size
+	// since the last time, which keeps the amortized cost constant.
+	if len(a.programs) >= 2*a.prunedPrograms {
+		a.prunePrograms()
+	}
+	a.programs = append(a.programs, program)
+}
+
+func (a *API) prunePrograms() {
+	a.programs = slices.DeleteFunc(a.programs, func(program *internal.Program) bool {
+		return program.ID() == 0
+	})
+	a.prunedPrograms = len(a.programs)
+}
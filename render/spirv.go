@@ -0,0 +1,26 @@
+package render
+
+import (
+	"github.com/mokiat/lacking-gl/render/ext"
+	"github.com/mokiat/lacking-gl/render/internal"
+	"github.com/mokiat/lacking/render"
+)
+
+// CreateSPIRVShader creates a new shader from a SPIR-V binary.
+func (a *API) CreateSPIRVShader(info ext.SPIRVShaderInfo) render.Shader {
+	shader := internal.NewSPIRVShader(info, a.capabilities)
+	a.reportShaderDiagnostics(shader)
+	return shader
+}
+
+// newShader creates a shader for the specified stage from GLSL source
+// code or, if the source code holds one, from a SPIR-V binary.
+func (a *API) newShader(stage ext.ShaderStage, info render.ShaderInfo) *internal.Shader {
+	if internal.IsSPIRV(info.SourceCode) {
+		return internal.NewSPIRVShader(ext.SPIRVShaderInfo{
+			Stage:  stage,
+			Binary: []byte(info.SourceCode),
+		}, a.capabilities)
+	}
+	return internal.NewShader(stage, info)
+}
@@ -0,0 +1,203 @@
+package texfile
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/mokiat/lacking-gl/render/ext"
+	"github.com/mokiat/lacking/render"
+)
+
+var ddsMagic = []byte{'D', 'D', 'S', ' '}
+
+const (
+	ddsHeaderSize     = 124
+	ddsDX10HeaderSize = 20
+
+	ddsFlagMipmapCount = 0x20000
+	ddsPixelFlagFourCC = 0x4
+	ddsPixelFlagRGB    = 0x40
+	ddsCaps2Cubemap    = 0x200
+	ddsCaps2AllFaces   = 0xFC00
+	ddsCaps2Volume     = 0x200000
+
+	ddsDimensionTexture3D = 4
+	ddsMiscFlagCube       = 0x4
+)
+
+// ddsFourCCFormats holds the formats of legacy files that are identified
+// by a four-character code. Some are identified by a D3DFORMAT value
+// instead.
+var ddsFourCCFormats = map[uint32]render.DataFormat{
+	fourCC("DXT1"): ext.DataFormatBC1,
+	fourCC("DXT5"): ext.DataFormatBC3,
+	fourCC("ATI1"): ext.DataFormatBC4,
+	fourCC("BC4U"): ext.DataFormatBC4,
+	fourCC("ATI2"): ext.DataFormatBC5,
+	fourCC("BC5U"): ext.DataFormatBC5,
+	113:            render.DataFormatRGBA16F, // D3DFMT_A16B16G16R16F
+	116:            render.DataFormatRGBA32F, // D3DFMT_A32B32G32R32F
+}
+
+// ddsDXGIFormat is the render.DataFormat that corresponds to a DXGI
+// format.
+type ddsDXGIFormat struct {
+	format          render.DataFormat
+	gammaCorrection bool
+}
+
+var ddsDXGIFormats = map[uint32]ddsDXGIFormat{
+	2:  {render.DataFormatRGBA32F, false}, // DXGI_FORMAT_R32G32B32A32_FLOAT
+	10: {render.DataFormatRGBA16F, false}, // DXGI_FORMAT_R16G16B16A16_FLOAT
+	24: {ext.DataFormatRGB10A2, false},    // DXGI_FORMAT_R10G10B10A2_UNORM
+	26: {ext.DataFormatR11G11B10F, false}, // DXGI_FORMAT_R11G11B10_FLOAT
+	28: {render.DataFormatRGBA8, false},   // DXGI_FORMAT_R8G8B8A8_UNORM
+	29: {render.DataFormatRGBA8, true},    // DXGI_FORMAT_R8G8B8A8_UNORM_SRGB
+	34: {ext.DataFormatRG16F, false},      // DXGI_FORMAT_R16G16_FLOAT
+	41: {ext.DataFormatR32F, false},       // DXGI_FORMAT_R32_FLOAT
+	42: {ext.DataFormatR32UI, false},      // DXGI_FORMAT_R32_UINT
+	49: {ext.DataFormatRG8, false},        // DXGI_FORMAT_R8G8_UNORM
+	54: {ext.DataFormatR16F, false},       // DXGI_FORMAT_R16_FLOAT
+	61: {ext.DataFormatR8, false},         // DXGI_FORMAT_R8_UNORM
+	71: {ext.DataFormatBC1, false},        // DXGI_FORMAT_BC1_UNORM
+	72: {ext.DataFormatBC1, true},         // DXGI_FORMAT_BC1_UNORM_SRGB
+	77: {ext.DataFormatBC3, false},        // DXGI_FORMAT_BC3_UNORM
+	78: {ext.DataFormatBC3, true},         // DXGI_FORMAT_BC3_UNORM_SRGB
+	80: {ext.DataFormatBC4, false},        // DXGI_FORMAT_BC4_UNORM
+	83: {ext.DataFormatBC5, false},        // DXGI_FORMAT_BC5_UNORM
+	98: {ext.DataFormatBC7, false},        // DXGI_FORMAT_BC7_UNORM
+	99: {ext.DataFormatBC7, true},         // DXGI_FORMAT_BC7_UNORM_SRGB
+}
+
+// parseDDS parses a DDS file. Unlike KTX2, the images are stored layer by
+// layer, with all levels of a layer next to each other.
+func parseDDS(data []byte) (*Image, error) {
+	if len(data) < len(ddsMagic)+ddsHeaderSize {
+		return nil, errors.New("file is too short")
+	}
+	header := data[len(ddsMagic):]
+	flags := binary.LittleEndian.Uint32(header[4:])
+	height := int(binary.LittleEndian.Uint32(header[8:]))
+	width := int(binary.LittleEndian.Uint32(header[12:]))
+	depth := int(binary.LittleEndian.Uint32(header[20:]))
+	levelCount := int(binary.LittleEndian.Uint32(header[24:]))
+	pixelFlags := binary.LittleEndian.Uint32(header[76:])
+	pixelFourCC := binary.LittleEndian.Uint32(header[80:])
+	caps2 := binary.LittleEndian.Uint32(header[108:])
+
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("unsupported size %dx%d", width, height)
+	}
+	image := &Image{
+		Kind:   Kind2D,
+		Width:  width,
+		Height: height,
+		Depth:  1,
+		Layers: 1,
+	}
+	if flags&ddsFlagMipmapCount == 0 || levelCount == 0 {
+		levelCount = 1
+	}
+
+	offset := len(ddsMagic) + ddsHeaderSize
+	switch {
+	case pixelFlags&ddsPixelFlagFourCC != 0 && pixelFourCC == fourCC("DX10"):
+		if len(data) < offset+ddsDX10HeaderSize {
+			return nil, errors.New("DX10 header is truncated")
+		}
+		dx10Header := data[offset:]
+		offset += ddsDX10HeaderSize
+		dxgiFormat := binary.LittleEndian.Uint32(dx10Header[0:])
+		dimension := binary.LittleEndian.Uint32(dx10Header[4:])
+		miscFlags := binary.LittleEndian.Uint32(dx10Header[8:])
+		arraySize := int(binary.LittleEndian.Uint32(dx10Header[12:]))
+
+		format, ok := ddsDXGIFormats[dxgiFormat]
+		if !ok {
+			return nil, fmt.Errorf("unsupported DXGI format %d", dxgiFormat)
+		}
+		image.Format = format.format
+		image.GammaCorrection = format.gammaCorrection
+		switch {
+		case dimension == ddsDimensionTexture3D:
+			image.Kind = Kind3D
+			image.Depth = max(1, depth)
+		case miscFlags&ddsMiscFlagCube != 0 && arraySize > 1:
+			return nil, errors.New("cube array textures are not supported")
+		case miscFlags&ddsMiscFlagCube != 0:
+			image.Kind = KindCube
+			image.Layers = 6
+		case arraySize > 1:
+			image.Kind = Kind2DArray
+			image.Layers = arraySize
+		}
+
+	case pixelFlags&ddsPixelFlagFourCC != 0:
+		format, ok := ddsFourCCFormats[pixelFourCC]
+		if !ok {
+			return nil, fmt.Errorf("unsupported four-character code %q", binary.LittleEndian.AppendUint32(nil, pixelFourCC))
+		}
+		image.Format = format
+
+	case pixelFlags&ddsPixelFlagRGB != 0:
+		bitCount := binary.LittleEndian.Uint32(header[84:])
+		redMask := binary.LittleEndian.Uint32(header[88:])
+		greenMask := binary.LittleEndian.Uint32(header[92:])
+		blueMask := binary.LittleEndian.Uint32(header[96:])
+		if bitCount != 32 || redMask != 0x000000FF || greenMask != 0x0000FF00 || blueMask != 0x00FF0000 {
+			return nil, fmt.Errorf("unsupported %d-bit RGB layout", bitCount)
+		}
+		image.Format = render.DataFormatRGBA8
+
+	default:
+		return nil, errors.New("unsupported pixel format")
+	}
+
+	if image.Kind == Kind2D {
+		switch {
+		case caps2&ddsCaps2Cubemap != 0:
+			if caps2&ddsCaps2AllFaces != ddsCaps2AllFaces {
+				return nil, errors.New("cube textures with missing faces are not supported")
+			}
+			image.Kind = KindCube
+			image.Layers = 6
+		case caps2&ddsCaps2Volume != 0:
+			image.Kind = Kind3D
+			image.Depth = max(1, depth)
+		}
+	}
+
+	levelCount, err := image.checkSize(levelCount)
+	if err != nil {
+		return nil, err
+	}
+
+	// The levels of each layer follow one another, so the offsets of the
+	// first layer are determined first and are then repeated for the rest.
+	levelOffsets := make([]int, levelCount)
+	layerSize := 0
+	for level := range levelOffsets {
+		levelOffsets[level] = layerSize
+		layerSize += image.layerSize(level)
+	}
+	if (len(data)-offset)/image.Layers < layerSize {
+		return nil, errors.New("file is truncated")
+	}
+	err = image.readLevels(levelCount, func(level, levelSize int) ([]byte, error) {
+		result := make([]byte, 0, levelSize*image.Layers)
+		for layer := 0; layer < image.Layers; layer++ {
+			start := offset + layer*layerSize + levelOffsets[level]
+			result = append(result, data[start:start+levelSize]...)
+		}
+		return result, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return image, nil
+}
+
+func fourCC(code string) uint32 {
+	return binary.LittleEndian.Uint32([]byte(code))
+}
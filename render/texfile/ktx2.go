@@ -0,0 +1,133 @@
+package texfile
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/mokiat/lacking-gl/render/ext"
+	"github.com/mokiat/lacking/render"
+)
+
+var ktx2Identifier = []byte{0xAB, 'K', 'T', 'X', ' ', '2', '0', 0xBB, '\r', '\n', 0x1A, '\n'}
+
+const (
+	ktx2HeaderSize     = 68
+	ktx2LevelIndexSize = 24
+)
+
+// ktx2Format is the render.DataFormat that corresponds to a Vulkan format.
+type ktx2Format struct {
+	format          render.DataFormat
+	gammaCorrection bool
+}
+
+var ktx2Formats = map[uint32]ktx2Format{
+	9:   {ext.DataFormatR8, false},         // VK_FORMAT_R8_UNORM
+	16:  {ext.DataFormatRG8, false},        // VK_FORMAT_R8G8_UNORM
+	37:  {render.DataFormatRGBA8, false},   // VK_FORMAT_R8G8B8A8_UNORM
+	43:  {render.DataFormatRGBA8, true},    // VK_FORMAT_R8G8B8A8_SRGB
+	64:  {ext.DataFormatRGB10A2, false},    // VK_FORMAT_A2B10G10R10_UNORM_PACK32
+	76:  {ext.DataFormatR16F, false},       // VK_FORMAT_R16_SFLOAT
+	83:  {ext.DataFormatRG16F, false},      // VK_FORMAT_R16G16_SFLOAT
+	97:  {render.DataFormatRGBA16F, false}, // VK_FORMAT_R16G16B16A16_SFLOAT
+	98:  {ext.DataFormatR32UI, false},      // VK_FORMAT_R32_UINT
+	100: {ext.DataFormatR32F, false},       // VK_FORMAT_R32_SFLOAT
+	109: {render.DataFormatRGBA32F, false}, // VK_FORMAT_R32G32B32A32_SFLOAT
+	122: {ext.DataFormatR11G11B10F, false}, // VK_FORMAT_B10G11R11_UFLOAT_PACK32
+	131: {ext.DataFormatBC1, false},        // VK_FORMAT_BC1_RGB_UNORM_BLOCK
+	132: {ext.DataFormatBC1, true},         // VK_FORMAT_BC1_RGB_SRGB_BLOCK
+	133: {ext.DataFormatBC1, false},        // VK_FORMAT_BC1_RGBA_UNORM_BLOCK
+	134: {ext.DataFormatBC1, true},         // VK_FORMAT_BC1_RGBA_SRGB_BLOCK
+	137: {ext.DataFormatBC3, false},        // VK_FORMAT_BC3_UNORM_BLOCK
+	138: {ext.DataFormatBC3, true},         // VK_FORMAT_BC3_SRGB_BLOCK
+	139: {ext.DataFormatBC4, false},        // VK_FORMAT_BC4_UNORM_BLOCK
+	141: {ext.DataFormatBC5, false},        // VK_FORMAT_BC5_UNORM_BLOCK
+	145: {ext.DataFormatBC7, false},        // VK_FORMAT_BC7_UNORM_BLOCK
+	146: {ext.DataFormatBC7, true},         // VK_FORMAT_BC7_SRGB_BLOCK
+	147: {ext.DataFormatETC2RGB8, false},   // VK_FORMAT_ETC2_R8G8B8_UNORM_BLOCK
+	148: {ext.DataFormatETC2RGB8, true},    // VK_FORMAT_ETC2_R8G8B8_SRGB_BLOCK
+	151: {ext.DataFormatETC2RGBA8, false},  // VK_FORMAT_ETC2_R8G8B8A8_UNORM_BLOCK
+	152: {ext.DataFormatETC2RGBA8, true},   // VK_FORMAT_ETC2_R8G8B8A8_SRGB_BLOCK
+}
+
+// parseKTX2 parses a KTX2 file. Within each level, the images are stored
+// layer by layer and face by face, which matches the order of
+// Level.Layers.
+func parseKTX2(data []byte) (*Image, error) {
+	if len(data) < len(ktx2Identifier)+ktx2HeaderSize {
+		return nil, errors.New("file is too short")
+	}
+	header := data[len(ktx2Identifier):]
+	vkFormat := binary.LittleEndian.Uint32(header[0:])
+	width := int(binary.LittleEndian.Uint32(header[8:]))
+	height := int(binary.LittleEndian.Uint32(header[12:]))
+	depth := int(binary.LittleEndian.Uint32(header[16:]))
+	layerCount := int(binary.LittleEndian.Uint32(header[20:]))
+	faceCount := int(binary.LittleEndian.Uint32(header[24:]))
+	levelCount := int(binary.LittleEndian.Uint32(header[28:]))
+	supercompression := binary.LittleEndian.Uint32(header[32:])
+
+	if supercompression != 0 {
+		return nil, fmt.Errorf("unsupported supercompression scheme %d", supercompression)
+	}
+	format, ok := ktx2Formats[vkFormat]
+	if !ok {
+		return nil, fmt.Errorf("unsupported Vulkan format %d", vkFormat)
+	}
+	if width <= 0 || height < 0 || depth < 0 {
+		return nil, fmt.Errorf("unsupported size %dx%dx%d", width, height, depth)
+	}
+
+	image := &Image{
+		Format:          format.format,
+		GammaCorrection: format.gammaCorrection,
+		Width:           width,
+		Height:          max(1, height),
+		Depth:           max(1, depth),
+		Layers:          1,
+		GenerateMipmaps: levelCount == 0,
+	}
+	switch {
+	case height == 0:
+		return nil, errors.New("1D textures are not supported")
+	case faceCount == 6 && layerCount > 0:
+		return nil, errors.New("cube array textures are not supported")
+	case faceCount == 6:
+		image.Kind = KindCube
+		image.Layers = 6
+	case faceCount != 1:
+		return nil, fmt.Errorf("unsupported face count %d", faceCount)
+	case depth > 0 && layerCount > 0:
+		return nil, errors.New("3D array textures are not supported")
+	case depth > 0:
+		image.Kind = Kind3D
+	case layerCount > 0:
+		image.Kind = Kind2DArray
+		image.Layers = layerCount
+	default:
+		image.Kind = Kind2D
+	}
+
+	levelCount, err := image.checkSize(max(1, levelCount))
+	if err != nil {
+		return nil, err
+	}
+	levelIndex := data[len(ktx2Identifier)+ktx2HeaderSize:]
+	if len(levelIndex) < levelCount*ktx2LevelIndexSize {
+		return nil, errors.New("level index is truncated")
+	}
+	err = image.readLevels(levelCount, func(level, _ int) ([]byte, error) {
+		entry := levelIndex[level*ktx2LevelIndexSize:]
+		offset := binary.LittleEndian.Uint64(entry[0:])
+		length := binary.LittleEndian.Uint64(entry[8:])
+		if offset > uint64(len(data)) || length > uint64(len(data))-offset {
+			return nil, fmt.Errorf("level %d is out of bounds", level)
+		}
+		return data[offset : offset+length], nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return image, nil
+}
@@ -0,0 +1,388 @@
+// Package texfile loads textures from KTX2 and DDS container files.
+//
+// Unlike PNG images, these files can hold pre-built mipmap chains, cube
+// faces, array layers and block-compressed data, which are uploaded as
+// they are instead of being generated at runtime.
+//
+// Rows are kept in the order in which they are stored in the file, which
+// is top to bottom for the files that common tools produce. They are not
+// flipped, since block-compressed data cannot be flipped in general, so
+// texture coordinates need to account for that.
+package texfile
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/mokiat/lacking-gl/render/decompress"
+	"github.com/mokiat/lacking-gl/render/ext"
+	"github.com/mokiat/lacking/render"
+)
+
+const (
+	// maxSize is the largest width, height or depth that is accepted. It
+	// is beyond what drivers support and keeps the size of a single layer
+	// from overflowing.
+	maxSize = 1 << 16
+
+	// maxLayers is the largest number of array layers that is accepted.
+	maxLayers = 1 << 12
+)
+
+// Kind specifies the kind of texture that an Image holds.
+type Kind int
+
+const (
+	// Kind2D is a single 2D image.
+	Kind2D Kind = iota
+
+	// KindCube is a cube with six faces.
+	KindCube
+
+	// Kind2DArray is a sequence of 2D layers.
+	Kind2DArray
+
+	// Kind3D is a volume of slices.
+	Kind3D
+)
+
+// String returns a string representation of the kind.
+func (k Kind) String() string {
+	switch k {
+	case Kind2D:
+		return "2D"
+	case KindCube:
+		return "cube"
+	case Kind2DArray:
+		return "2D array"
+	case Kind3D:
+		return "3D"
+	default:
+		return fmt.Sprintf("Kind(%d)", int(k))
+	}
+}
+
+// Image is the content of a texture container file.
+type Image struct {
+
+	// Kind is the kind of texture.
+	Kind Kind
+
+	// Format is the format of the data. It can be one of the
+	// block-compressed formats of the ext package.
+	Format render.DataFormat
+
+	// GammaCorrection specifies whether the data is in sRGB color space.
+	GammaCorrection bool
+
+	// Width is the width of the first level.
+	Width int
+
+	// Height is the height of the first level.
+	Height int
+
+	// Depth is the number of slices of the first level of 3D textures
+	// and one for all other kinds.
+	Depth int
+
+	// Layers is the number of array layers, six for cube textures and one
+	// for all other kinds.
+	Layers int
+
+	// Levels holds the mipmap levels that are stored in the file,
+	// starting with the largest one.
+	Levels []Level
+
+	// GenerateMipmaps indicates that the file requests mipmaps to be
+	// generated at runtime instead of holding them.
+	GenerateMipmaps bool
+}
+
+// Level is a single mipmap level of an Image.
+type Level struct {
+
+	// Width is the width of the level.
+	Width int
+
+	// Height is the height of the level.
+	Height int
+
+	// Depth is the number of slices of the level.
+	Depth int
+
+	// Layers holds the data of each array layer or cube face, in the
+	// order right, left, bottom, top, front and back. The data of all
+	// slices of a 3D texture is in a single layer.
+	Layers [][]byte
+}
+
+// Decode reads a KTX2 or DDS file, depending on its signature.
+func Decode(r io.Reader) (*Image, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	switch {
+	case bytes.HasPrefix(data, ktx2Identifier):
+		return parseKTX2(data)
+	case bytes.HasPrefix(data, ddsMagic):
+		return parseDDS(data)
+	default:
+		return nil, errors.New("unknown file format")
+	}
+}
+
+// DecodeKTX2 reads a KTX2 file. Supercompressed files are not supported.
+func DecodeKTX2(r io.Reader) (*Image, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	return parseKTX2(data)
+}
+
+// DecodeDDS reads a DDS file, either with the legacy header or with the
+// DX10 one.
+func DecodeDDS(r io.Reader) (*Image, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	return parseDDS(data)
+}
+
+// Mipmapping returns whether textures that are created from the image
+// have mipmaps. Levels that are missing from the file are generated,
+// except for block-compressed formats, which need to hold the full chain.
+func (i *Image) Mipmapping() bool {
+	if ext.BlockSize(i.Format) > 0 {
+		return len(i.Levels) == mipmapLevels(i.Width, i.Height, i.Depth)
+	}
+	return len(i.Levels) > 1 || i.GenerateMipmaps
+}
+
+// ColorTexture2DInfo returns the information needed to create a texture
+// from the first level of a 2D image. The remaining levels are uploaded
+// through UploadLevels.
+func (i *Image) ColorTexture2DInfo(wrapping render.WrapMode, filtering render.FilterMode) render.ColorTexture2DInfo {
+	return render.ColorTexture2DInfo{
+		Width:           i.Width,
+		Height:          i.Height,
+		Wrapping:        wrapping,
+		Filtering:       filtering,
+		Mipmapping:      i.Mipmapping(),
+		GammaCorrection: i.GammaCorrection,
+		Format:          i.Format,
+		Data:            i.Levels[0].Layers[0],
+	}
+}
+
+// ColorTextureCubeInfo returns the information needed to create a texture
+// from the first level of a cube image. The remaining levels are uploaded
+// through UploadLevels.
+//
+// Cube textures cannot have block-compressed formats, so such images
+// need to be decompressed first.
+func (i *Image) ColorTextureCubeInfo(filtering render.FilterMode) render.ColorTextureCubeInfo {
+	faces := i.Levels[0].Layers
+	return render.ColorTextureCubeInfo{
+		Dimension:       i.Width,
+		Filtering:       filtering,
+		Mipmapping:      i.Mipmapping(),
+		GammaCorrection: i.GammaCorrection,
+		Format:          i.Format,
+		RightSideData:   faces[0],
+		LeftSideData:    faces[1],
+		BottomSideData:  faces[2],
+		TopSideData:     faces[3],
+		FrontSideData:   faces[4],
+		BackSideData:    faces[5],
+	}
+}
+
+// ColorTexture2DArrayInfo returns the information needed to create a
+// texture from the first level of a 2D array image. The remaining levels
+// are uploaded through UploadLevels.
+//
+// Array textures cannot have block-compressed formats, so such images
+// need to be decompressed first.
+func (i *Image) ColorTexture2DArrayInfo(wrapping render.WrapMode, filtering render.FilterMode) ext.ColorTexture2DArrayInfo {
+	return ext.ColorTexture2DArrayInfo{
+		Width:           i.Width,
+		Height:          i.Height,
+		Layers:          i.Layers,
+		Wrapping:        wrapping,
+		Filtering:       filtering,
+		Mipmapping:      i.Mipmapping(),
+		GammaCorrection: i.GammaCorrection,
+		Format:          i.Format,
+		Data:            bytes.Join(i.Levels[0].Layers, nil),
+	}
+}
+
+// ColorTexture3DInfo returns the information needed to create a texture
+// from the first level of a 3D image. The remaining levels are uploaded
+// through UploadLevels.
+//
+// 3D textures cannot have block-compressed formats, so such images need
+// to be decompressed first.
+func (i *Image) ColorTexture3DInfo(wrapping render.WrapMode, filtering render.FilterMode) ext.ColorTexture3DInfo {
+	return ext.ColorTexture3DInfo{
+		Width:           i.Width,
+		Height:          i.Height,
+		Depth:           i.Depth,
+		Wrapping:        wrapping,
+		Filtering:       filtering,
+		Mipmapping:      i.Mipmapping(),
+		GammaCorrection: i.GammaCorrection,
+		Format:          i.Format,
+		Data:            i.Levels[0].Layers[0],
+	}
+}
+
+// UploadLevels replaces the levels of the texture, apart from the first
+// one, with the ones of the image. It has no effect if the texture has
+// no mipmaps (see Mipmapping).
+func (i *Image) UploadLevels(texture ext.Texture) {
+	if !i.Mipmapping() {
+		return
+	}
+	for index, level := range i.Levels[1:] {
+		texture.Update(ext.TextureUpdateInfo{
+			Level:  index + 1,
+			Layers: max(len(level.Layers), level.Depth),
+			Format: i.Format,
+			Data:   bytes.Join(level.Layers, nil),
+		})
+	}
+}
+
+// Decompress converts block-compressed data to RGBA8. It has no effect
+// on images with other formats.
+func (i *Image) Decompress() error {
+	if ext.BlockSize(i.Format) == 0 {
+		return nil
+	}
+	for levelIndex := range i.Levels {
+		level := &i.Levels[levelIndex]
+		sliceSize := dataSize(i.Format, level.Width, level.Height)
+		for layerIndex, data := range level.Layers {
+			var result []byte
+			for slice := 0; slice < level.Depth; slice++ {
+				pixels, err := decompress.RGBA8(i.Format, level.Width, level.Height, data[slice*sliceSize:(slice+1)*sliceSize])
+				if err != nil {
+					return fmt.Errorf("failed to decompress level %d: %w", levelIndex, err)
+				}
+				result = append(result, pixels...)
+			}
+			level.Layers[layerIndex] = result
+		}
+	}
+	i.Format = render.DataFormatRGBA8
+	return nil
+}
+
+// CreateTexture creates a texture of the matching kind from the image and
+// uploads all of its levels. Block-compressed images of kinds other than
+// 2D are decompressed first.
+func (i *Image) CreateTexture(api ext.API, wrapping render.WrapMode, filtering render.FilterMode) (render.Texture, error) {
+	if i.Kind != Kind2D {
+		if err := i.Decompress(); err != nil {
+			return nil, err
+		}
+	}
+	var texture render.Texture
+	switch i.Kind {
+	case Kind2D:
+		texture = api.CreateColorTexture2D(i.ColorTexture2DInfo(wrapping, filtering))
+	case KindCube:
+		texture = api.CreateColorTextureCube(i.ColorTextureCubeInfo(filtering))
+	case Kind2DArray:
+		texture = api.CreateColorTexture2DArray(i.ColorTexture2DArrayInfo(wrapping, filtering))
+	case Kind3D:
+		texture = api.CreateColorTexture3D(i.ColorTexture3DInfo(wrapping, filtering))
+	default:
+		return nil, fmt.Errorf("unsupported texture kind %v", i.Kind)
+	}
+	if i.Kind == KindCube && i.Mipmapping() {
+		// Cube mipmaps are not generated on creation, so the ones that
+		// the file does not hold are generated before uploading the rest.
+		texture.(ext.Texture).GenerateMipmaps()
+	}
+	i.UploadLevels(texture.(ext.Texture))
+	return texture, nil
+}
+
+// checkSize returns an error if the size of the image is beyond the
+// limits. Otherwise, it returns the specified level count, limited to the
+// length of a full mipmap chain.
+func (i *Image) checkSize(levelCount int) (int, error) {
+	if i.Width > maxSize || i.Height > maxSize || i.Depth > maxSize {
+		return 0, fmt.Errorf("unsupported size %dx%dx%d", i.Width, i.Height, i.Depth)
+	}
+	if i.Layers > maxLayers {
+		return 0, fmt.Errorf("unsupported layer count %d", i.Layers)
+	}
+	return min(levelCount, mipmapLevels(i.Width, i.Height, i.Depth)), nil
+}
+
+// layerSize returns the size, in bytes, of a single layer of the specified
+// level, including all of its slices. The size of the image needs to have
+// been checked through checkSize.
+func (i *Image) layerSize(level int) int {
+	width := max(1, i.Width>>level)
+	height := max(1, i.Height>>level)
+	depth := max(1, i.Depth>>level)
+	return dataSize(i.Format, width, height) * depth
+}
+
+// readLevels splits the data of the levels of an image, where read returns
+// the data of the specified level and the size of each of its layers. The
+// size of the image and the level count need to have been checked through
+// checkSize.
+func (i *Image) readLevels(count int, read func(level, layerSize int) ([]byte, error)) error {
+	i.Levels = make([]Level, count)
+	for index := range i.Levels {
+		layerSize := i.layerSize(index)
+		data, err := read(index, layerSize)
+		if err != nil {
+			return err
+		}
+		if len(data)/i.Layers < layerSize {
+			return fmt.Errorf("level %d is truncated", index)
+		}
+		layers := make([][]byte, i.Layers)
+		for layer := range layers {
+			layers[layer] = data[layer*layerSize : (layer+1)*layerSize]
+		}
+		i.Levels[index] = Level{
+			Width:  max(1, i.Width>>index),
+			Height: max(1, i.Height>>index),
+			Depth:  max(1, i.Depth>>index),
+			Layers: layers,
+		}
+	}
+	return nil
+}
+
+// dataSize returns the size, in bytes, of a single image with the
+// specified format and size.
+func dataSize(format render.DataFormat, width, height int) int {
+	if blockSize := ext.BlockSize(format); blockSize > 0 {
+		return ((width + 3) / 4) * ((height + 3) / 4) * blockSize
+	}
+	return width * height * ext.PixelSize(format)
+}
+
+func mipmapLevels(width, height, depth int) int {
+	count := 1
+	for width > 1 || height > 1 || depth > 1 {
+		width /= 2
+		height /= 2
+		depth /= 2
+		count++
+	}
+	return count
+}
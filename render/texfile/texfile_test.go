@@ -0,0 +1,407 @@
+package texfile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+
+	"github.com/mokiat/lacking-gl/render/ext"
+	"github.com/mokiat/lacking/render"
+)
+
+func TestDecodeKTX2(t *testing.T) {
+	testCases := []struct {
+		name     string
+		data     []byte
+		expected *Image
+	}{
+		{
+			name: "2D",
+			data: encodeKTX2(ktx2Header{format: 37, width: 2, height: 2, faces: 1, levels: 1}, sequence(0, 16)),
+			expected: &Image{
+				Kind:   Kind2D,
+				Format: render.DataFormatRGBA8,
+				Width:  2, Height: 2, Depth: 1, Layers: 1,
+				Levels: []Level{
+					{Width: 2, Height: 2, Depth: 1, Layers: [][]byte{sequence(0, 16)}},
+				},
+			},
+		},
+		{
+			name: "2D without levels",
+			data: encodeKTX2(ktx2Header{format: 43, width: 1, height: 1, faces: 1}, sequence(0, 4)),
+			expected: &Image{
+				Kind:            Kind2D,
+				Format:          render.DataFormatRGBA8,
+				GammaCorrection: true,
+				Width:           1, Height: 1, Depth: 1, Layers: 1,
+				Levels: []Level{
+					{Width: 1, Height: 1, Depth: 1, Layers: [][]byte{sequence(0, 4)}},
+				},
+				GenerateMipmaps: true,
+			},
+		},
+		{
+			name: "cube",
+			data: encodeKTX2(ktx2Header{format: 37, width: 1, height: 1, faces: 6, levels: 1}, sequence(0, 24)),
+			expected: &Image{
+				Kind:   KindCube,
+				Format: render.DataFormatRGBA8,
+				Width:  1, Height: 1, Depth: 1, Layers: 6,
+				Levels: []Level{
+					{Width: 1, Height: 1, Depth: 1, Layers: [][]byte{
+						sequence(0, 4), sequence(4, 4), sequence(8, 4),
+						sequence(12, 4), sequence(16, 4), sequence(20, 4),
+					}},
+				},
+			},
+		},
+		{
+			name: "array",
+			data: encodeKTX2(ktx2Header{format: 9, width: 2, height: 1, layers: 3, faces: 1, levels: 1}, sequence(0, 6)),
+			expected: &Image{
+				Kind:   Kind2DArray,
+				Format: ext.DataFormatR8,
+				Width:  2, Height: 1, Depth: 1, Layers: 3,
+				Levels: []Level{
+					{Width: 2, Height: 1, Depth: 1, Layers: [][]byte{sequence(0, 2), sequence(2, 2), sequence(4, 2)}},
+				},
+			},
+		},
+		{
+			name: "3D",
+			data: encodeKTX2(ktx2Header{format: 9, width: 2, height: 2, depth: 2, faces: 1, levels: 2}, sequence(0, 8), sequence(8, 1)),
+			expected: &Image{
+				Kind:   Kind3D,
+				Format: ext.DataFormatR8,
+				Width:  2, Height: 2, Depth: 2, Layers: 1,
+				Levels: []Level{
+					{Width: 2, Height: 2, Depth: 2, Layers: [][]byte{sequence(0, 8)}},
+					{Width: 1, Height: 1, Depth: 1, Layers: [][]byte{sequence(8, 1)}},
+				},
+			},
+		},
+		{
+			name: "BC1 with mipmaps",
+			data: encodeKTX2(ktx2Header{format: 131, width: 8, height: 8, faces: 1, levels: 4}, sequence(0, 32), sequence(32, 8), sequence(40, 8), sequence(48, 8)),
+			expected: &Image{
+				Kind:   Kind2D,
+				Format: ext.DataFormatBC1,
+				Width:  8, Height: 8, Depth: 1, Layers: 1,
+				Levels: []Level{
+					{Width: 8, Height: 8, Depth: 1, Layers: [][]byte{sequence(0, 32)}},
+					{Width: 4, Height: 4, Depth: 1, Layers: [][]byte{sequence(32, 8)}},
+					{Width: 2, Height: 2, Depth: 1, Layers: [][]byte{sequence(40, 8)}},
+					{Width: 1, Height: 1, Depth: 1, Layers: [][]byte{sequence(48, 8)}},
+				},
+			},
+		},
+		{
+			// Levels beyond the full mipmap chain are ignored.
+			name: "overflowing level count",
+			data: encodeKTX2(ktx2Header{format: 37, width: 1, height: 1, faces: 1, levels: 0xFFFFFFFF}, sequence(0, 4)),
+			expected: &Image{
+				Kind:   Kind2D,
+				Format: render.DataFormatRGBA8,
+				Width:  1, Height: 1, Depth: 1, Layers: 1,
+				Levels: []Level{
+					{Width: 1, Height: 1, Depth: 1, Layers: [][]byte{sequence(0, 4)}},
+				},
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			image, err := Decode(bytes.NewReader(testCase.data))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(image, testCase.expected) {
+				t.Errorf("expected %#v, got %#v", testCase.expected, image)
+			}
+		})
+	}
+}
+
+func TestDecodeKTX2Errors(t *testing.T) {
+	valid := encodeKTX2(ktx2Header{format: 37, width: 2, height: 2, faces: 1, levels: 1}, sequence(0, 16))
+	testCases := []struct {
+		name string
+		data []byte
+	}{
+		{
+			name: "truncated header",
+			data: valid[:40],
+		},
+		{
+			name: "truncated level index",
+			data: valid[:len(ktx2Identifier)+ktx2HeaderSize+8],
+		},
+		{
+			name: "truncated level",
+			data: valid[:len(valid)-1],
+		},
+		{
+			name: "overflowing size",
+			data: encodeKTX2(ktx2Header{format: 109, width: 0xFFFFFFFF, height: 0xFFFFFFFF, depth: 0xFFFFFFFF, faces: 1, levels: 1}, sequence(0, 16)),
+		},
+		{
+			name: "overflowing layer count",
+			data: encodeKTX2(ktx2Header{format: 37, width: 1, height: 1, layers: 0xFFFFFFFF, faces: 1, levels: 1}, sequence(0, 4)),
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if _, err := DecodeKTX2(bytes.NewReader(testCase.data)); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
+
+func TestDecodeDDS(t *testing.T) {
+	testCases := []struct {
+		name     string
+		data     []byte
+		expected *Image
+	}{
+		{
+			name: "2D",
+			data: encodeDDS(ddsHeader{width: 2, height: 1, rgb: true}, sequence(0, 8)),
+			expected: &Image{
+				Kind:   Kind2D,
+				Format: render.DataFormatRGBA8,
+				Width:  2, Height: 1, Depth: 1, Layers: 1,
+				Levels: []Level{
+					{Width: 2, Height: 1, Depth: 1, Layers: [][]byte{sequence(0, 8)}},
+				},
+			},
+		},
+		{
+			name: "cube",
+			data: encodeDDS(ddsHeader{width: 1, height: 1, rgb: true, caps2: ddsCaps2Cubemap | ddsCaps2AllFaces}, sequence(0, 24)),
+			expected: &Image{
+				Kind:   KindCube,
+				Format: render.DataFormatRGBA8,
+				Width:  1, Height: 1, Depth: 1, Layers: 6,
+				Levels: []Level{
+					{Width: 1, Height: 1, Depth: 1, Layers: [][]byte{
+						sequence(0, 4), sequence(4, 4), sequence(8, 4),
+						sequence(12, 4), sequence(16, 4), sequence(20, 4),
+					}},
+				},
+			},
+		},
+		{
+			// The levels of each layer follow one another.
+			name: "array with mipmaps",
+			data: encodeDDS(ddsHeader{width: 2, height: 2, levels: 2, dx10: []uint32{61, 3, 0, 2}}, sequence(0, 10)),
+			expected: &Image{
+				Kind:   Kind2DArray,
+				Format: ext.DataFormatR8,
+				Width:  2, Height: 2, Depth: 1, Layers: 2,
+				Levels: []Level{
+					{Width: 2, Height: 2, Depth: 1, Layers: [][]byte{sequence(0, 4), sequence(5, 4)}},
+					{Width: 1, Height: 1, Depth: 1, Layers: [][]byte{sequence(4, 1), sequence(9, 1)}},
+				},
+			},
+		},
+		{
+			name: "3D",
+			data: encodeDDS(ddsHeader{width: 2, height: 2, depth: 2, levels: 2, dx10: []uint32{61, ddsDimensionTexture3D, 0, 1}}, sequence(0, 9)),
+			expected: &Image{
+				Kind:   Kind3D,
+				Format: ext.DataFormatR8,
+				Width:  2, Height: 2, Depth: 2, Layers: 1,
+				Levels: []Level{
+					{Width: 2, Height: 2, Depth: 2, Layers: [][]byte{sequence(0, 8)}},
+					{Width: 1, Height: 1, Depth: 1, Layers: [][]byte{sequence(8, 1)}},
+				},
+			},
+		},
+		{
+			name: "BC1 with mipmaps",
+			data: encodeDDS(ddsHeader{width: 8, height: 8, levels: 4, fourCC: "DXT1"}, sequence(0, 56)),
+			expected: &Image{
+				Kind:   Kind2D,
+				Format: ext.DataFormatBC1,
+				Width:  8, Height: 8, Depth: 1, Layers: 1,
+				Levels: []Level{
+					{Width: 8, Height: 8, Depth: 1, Layers: [][]byte{sequence(0, 32)}},
+					{Width: 4, Height: 4, Depth: 1, Layers: [][]byte{sequence(32, 8)}},
+					{Width: 2, Height: 2, Depth: 1, Layers: [][]byte{sequence(40, 8)}},
+					{Width: 1, Height: 1, Depth: 1, Layers: [][]byte{sequence(48, 8)}},
+				},
+			},
+		},
+		{
+			// Levels beyond the full mipmap chain are ignored.
+			name: "overflowing level count",
+			data: encodeDDS(ddsHeader{width: 1, height: 1, levels: 0xFFFFFFF0, rgb: true}, sequence(0, 4)),
+			expected: &Image{
+				Kind:   Kind2D,
+				Format: render.DataFormatRGBA8,
+				Width:  1, Height: 1, Depth: 1, Layers: 1,
+				Levels: []Level{
+					{Width: 1, Height: 1, Depth: 1, Layers: [][]byte{sequence(0, 4)}},
+				},
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			image, err := Decode(bytes.NewReader(testCase.data))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(image, testCase.expected) {
+				t.Errorf("expected %#v, got %#v", testCase.expected, image)
+			}
+		})
+	}
+}
+
+func TestDecodeDDSErrors(t *testing.T) {
+	valid := encodeDDS(ddsHeader{width: 8, height: 8, levels: 4, fourCC: "DXT1"}, sequence(0, 56))
+	testCases := []struct {
+		name string
+		data []byte
+	}{
+		{
+			name: "truncated header",
+			data: valid[:100],
+		},
+		{
+			name: "truncated DX10 header",
+			data: encodeDDS(ddsHeader{width: 1, height: 1, dx10: []uint32{61, 3, 0, 1}}, nil)[:len(ddsMagic)+ddsHeaderSize+10],
+		},
+		{
+			name: "truncated data",
+			data: valid[:len(valid)-1],
+		},
+		{
+			name: "overflowing size",
+			data: encodeDDS(ddsHeader{width: 0xFFFFFFFF, height: 0xFFFFFFFF, rgb: true}, sequence(0, 16)),
+		},
+		{
+			name: "overflowing depth",
+			data: encodeDDS(ddsHeader{width: 1, height: 1, depth: 0xFFFFFFFF, rgb: true, caps2: ddsCaps2Volume}, sequence(0, 16)),
+		},
+		{
+			name: "overflowing array size",
+			data: encodeDDS(ddsHeader{width: 1, height: 1, dx10: []uint32{28, 3, 0, 0xFFFFFFFF}}, sequence(0, 16)),
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if _, err := DecodeDDS(bytes.NewReader(testCase.data)); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
+
+type ktx2Header struct {
+	format uint32
+	width  uint32
+	height uint32
+	depth  uint32
+	layers uint32
+	faces  uint32
+	levels uint32
+}
+
+// encodeKTX2 returns a KTX2 file with the specified header and data of
+// each level, regardless of the level count of the header.
+func encodeKTX2(header ktx2Header, levels ...[]byte) []byte {
+	result := append([]byte(nil), ktx2Identifier...)
+	fields := make([]uint32, ktx2HeaderSize/4)
+	fields[0] = header.format
+	fields[2] = header.width
+	fields[3] = header.height
+	fields[4] = header.depth
+	fields[5] = header.layers
+	fields[6] = header.faces
+	fields[7] = header.levels
+	for _, field := range fields {
+		result = binary.LittleEndian.AppendUint32(result, field)
+	}
+	offset := len(result) + len(levels)*ktx2LevelIndexSize
+	for _, level := range levels {
+		result = binary.LittleEndian.AppendUint64(result, uint64(offset))
+		result = binary.LittleEndian.AppendUint64(result, uint64(len(level)))
+		result = binary.LittleEndian.AppendUint64(result, uint64(len(level)))
+		offset += len(level)
+	}
+	for _, level := range levels {
+		result = append(result, level...)
+	}
+	return result
+}
+
+type ddsHeader struct {
+	width  uint32
+	height uint32
+	depth  uint32
+	levels uint32
+	fourCC string
+	rgb    bool
+	caps2  uint32
+	dx10   []uint32
+}
+
+// encodeDDS returns a DDS file with the specified header and data. The
+// pixel format is the four-character code, 32-bit RGBA or the DX10 header
+// fields, depending on which of them is specified.
+func encodeDDS(header ddsHeader, data []byte) []byte {
+	fields := make([]uint32, ddsHeaderSize/4)
+	fields[0] = ddsHeaderSize
+	fields[2] = header.height
+	fields[3] = header.width
+	fields[5] = header.depth
+	if header.levels > 0 {
+		fields[1] = ddsFlagMipmapCount
+		fields[6] = header.levels
+	}
+	fields[18] = 32
+	switch {
+	case header.dx10 != nil:
+		fields[19] = ddsPixelFlagFourCC
+		fields[20] = fourCC("DX10")
+	case header.rgb:
+		fields[19] = ddsPixelFlagRGB
+		fields[21] = 32
+		fields[22] = 0x000000FF
+		fields[23] = 0x0000FF00
+		fields[24] = 0x00FF0000
+		fields[25] = 0xFF000000
+	default:
+		fields[19] = ddsPixelFlagFourCC
+		fields[20] = fourCC(header.fourCC)
+	}
+	fields[27] = header.caps2
+
+	result := append([]byte(nil), ddsMagic...)
+	for _, field := range fields {
+		result = binary.LittleEndian.AppendUint32(result, field)
+	}
+	if header.dx10 != nil {
+		dx10 := make([]uint32, ddsDX10HeaderSize/4)
+		copy(dx10, header.dx10)
+		for _, field := range dx10 {
+			result = binary.LittleEndian.AppendUint32(result, field)
+		}
+	}
+	return append(result, data...)
+}
+
+// sequence returns count bytes that start with the specified value and
+// increase by one, so that slices of the data can be told apart.
+func sequence(start, count int) []byte {
+	result := make([]byte, count)
+	for i := range result {
+		result[i] = byte(start + i)
+	}
+	return result
+}
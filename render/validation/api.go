@@ -0,0 +1,697 @@
+package validation
+
+import (
+	"fmt"
+
+	"github.com/mokiat/lacking-gl/render/ext"
+	"github.com/mokiat/lacking-gl/render/internal"
+	"github.com/mokiat/lacking/render"
+)
+
+// NewAPI creates a new render.API that validates all calls before
+// forwarding them to the delegate API. The handler is called for each
+// detected problem. If it is nil, problems are logged.
+//
+// Calls that fail validation are not forwarded. Resource creation calls
+// that fail validation return a resource that reports an error when used.
+// Commands are forwarded to the delegate queue only once the queue is
+// submitted, since some problems (e.g. a queued draw while no render pass
+// is active) can only be detected at that point. They are still reported
+// with the stack of the caller that queued them.
+//
+// All resources need to be created through the returned API. This
+// function needs to be called from the rendering thread.
+func NewAPI(delegate render.API, handler ErrorHandler) render.API {
+	if handler == nil {
+		handler = LogError
+	}
+	result := &API{
+		delegate:         delegate,
+		handler:          handler,
+		uniformAlignment: uniformBufferOffsetAlignment(),
+	}
+	result.defaultFramebuffer = &validatedFramebuffer{
+		Framebuffer: delegate.DefaultFramebuffer(),
+		api:         result,
+		isDefault:   true,
+	}
+	return result
+}
+
+// uniformBufferOffsetAlignment is a variable, so that tests can replace
+// it and run without an OpenGL context.
+var uniformBufferOffsetAlignment = internal.UniformBufferOffsetAlignment
+
+var _ render.API = (*API)(nil)
+
+// API is a render.API implementation that validates calls. Use NewAPI to
+// create an instance.
+type API struct {
+	delegate           render.API
+	handler            ErrorHandler
+	uniformAlignment   int
+	defaultFramebuffer *validatedFramebuffer
+
+	passActive      bool
+	passFramebuffer *validatedFramebuffer
+	pipeline        *validatedPipeline
+}
+
+func (a *API) Capabilities() render.Capabilities {
+	return a.delegate.Capabilities()
+}
+
+func (a *API) DefaultFramebuffer() render.Framebuffer {
+	return a.defaultFramebuffer
+}
+
+func (a *API) DetermineContentFormat(framebuffer render.Framebuffer) render.DataFormat {
+	c := &call{name: "DetermineContentFormat"}
+	fb, ok := checkResource[*validatedFramebuffer](a, c, "framebuffer", framebuffer)
+	if !ok {
+		return render.DataFormatUnsupported
+	}
+	return a.delegate.DetermineContentFormat(fb.Framebuffer)
+}
+
+func (a *API) CreateFramebuffer(info render.FramebufferInfo) render.Framebuffer {
+	c := &call{name: "CreateFramebuffer"}
+	result := &validatedFramebuffer{
+		api: a,
+	}
+	delegateInfo := render.FramebufferInfo{}
+	hasAttachments := false
+	for i, attachment := range info.ColorAttachments {
+		texture, ok := checkOptionalResource[*validatedTexture](a, c, "color attachment", attachment)
+		if ok && texture != nil {
+			if texture.kind != textureKindColor {
+				a.fail(c, "color attachment %d is not a color texture", i)
+			}
+			result.attachments = append(result.attachments, texture)
+			hasAttachments = true
+		}
+		delegateInfo.ColorAttachments[i] = delegateTexture(texture)
+	}
+	depthTexture, ok := checkOptionalResource[*validatedTexture](a, c, "depth attachment", info.DepthAttachment)
+	if ok && depthTexture != nil {
+		if depthTexture.kind != textureKindDepth {
+			a.fail(c, "depth attachment is not a depth texture")
+		}
+		result.attachments = append(result.attachments, depthTexture)
+		hasAttachments = true
+	}
+	delegateInfo.DepthAttachment = delegateTexture(depthTexture)
+	stencilTexture, ok := checkOptionalResource[*validatedTexture](a, c, "stencil attachment", info.StencilAttachment)
+	if ok && stencilTexture != nil {
+		if stencilTexture.kind != textureKindStencil {
+			a.fail(c, "stencil attachment is not a stencil texture")
+		}
+		result.attachments = append(result.attachments, stencilTexture)
+		hasAttachments = true
+	}
+	delegateInfo.StencilAttachment = delegateTexture(stencilTexture)
+	depthStencilTexture, ok := checkOptionalResource[*validatedTexture](a, c, "depth-stencil attachment", info.DepthStencilAttachment)
+	if ok && depthStencilTexture != nil {
+		if depthStencilTexture.kind != textureKindDepthStencil {
+			a.fail(c, "depth-stencil attachment is not a depth-stencil texture")
+		}
+		if depthTexture != nil || stencilTexture != nil {
+			a.fail(c, "depth-stencil attachment cannot be combined with depth or stencil attachments")
+		}
+		result.attachments = append(result.attachments, depthStencilTexture)
+		hasAttachments = true
+	}
+	delegateInfo.DepthStencilAttachment = delegateTexture(depthStencilTexture)
+	if !hasAttachments && !c.failed {
+		a.fail(c, "framebuffer has no attachments")
+	}
+	if c.failed {
+		result.invalid = true
+		return result
+	}
+	result.Framebuffer = a.delegate.CreateFramebuffer(delegateInfo)
+	return result
+}
+
+func (a *API) CreateColorTexture2D(info render.ColorTexture2DInfo) render.Texture {
+	c := &call{name: "CreateColorTexture2D"}
+	a.checkSize(c, info.Width, info.Height)
+	return a.newTexture(c, textureKindColor, func() render.Texture {
+		return a.delegate.CreateColorTexture2D(info)
+	})
+}
+
+func (a *API) CreateColorTextureCube(info render.ColorTextureCubeInfo) render.Texture {
+	c := &call{name: "CreateColorTextureCube"}
+	a.checkSize(c, info.Dimension, info.Dimension)
+	return a.newTexture(c, textureKindColor, func() render.Texture {
+		return a.delegate.CreateColorTextureCube(info)
+	})
+}
+
+func (a *API) CreateDepthTexture2D(info render.DepthTexture2DInfo) render.Texture {
+	c := &call{name: "CreateDepthTexture2D"}
+	a.checkSize(c, info.Width, info.Height)
+	return a.newTexture(c, textureKindDepth, func() render.Texture {
+		return a.delegate.CreateDepthTexture2D(info)
+	})
+}
+
+func (a *API) CreateStencilTexture2D(info render.StencilTexture2DInfo) render.Texture {
+	c := &call{name: "CreateStencilTexture2D"}
+	a.checkSize(c, info.Width, info.Height)
+	return a.newTexture(c, textureKindStencil, func() render.Texture {
+		return a.delegate.CreateStencilTexture2D(info)
+	})
+}
+
+func (a *API) CreateDepthStencilTexture2D(info render.DepthStencilTexture2DInfo) render.Texture {
+	c := &call{name: "CreateDepthStencilTexture2D"}
+	a.checkSize(c, info.Width, info.Height)
+	return a.newTexture(c, textureKindDepthStencil, func() render.Texture {
+		return a.delegate.CreateDepthStencilTexture2D(info)
+	})
+}
+
+func (a *API) CreateVertexShader(info render.ShaderInfo) render.Shader {
+	c := &call{name: "CreateVertexShader"}
+	return a.newShader(c, shaderStageVertex, info, a.delegate.CreateVertexShader)
+}
+
+func (a *API) CreateFragmentShader(info render.ShaderInfo) render.Shader {
+	c := &call{name: "CreateFragmentShader"}
+	return a.newShader(c, shaderStageFragment, info, a.delegate.CreateFragmentShader)
+}
+
+func (a *API) CreateProgram(info render.ProgramInfo) render.Program {
+	c := &call{name: "CreateProgram"}
+	result := &validatedProgram{
+		api: a,
+	}
+	vertexShader, ok := checkResource[*validatedShader](a, c, "vertex shader", info.VertexShader)
+	if ok && vertexShader.stage != shaderStageVertex {
+		a.fail(c, "vertex shader is not a vertex stage shader")
+	}
+	fragmentShader, ok := checkResource[*validatedShader](a, c, "fragment shader", info.FragmentShader)
+	if ok && fragmentShader.stage != shaderStageFragment {
+		a.fail(c, "fragment shader is not a fragment stage shader")
+	}
+	if c.failed {
+		result.invalid = true
+		return result
+	}
+	result.Program = a.delegate.CreateProgram(render.ProgramInfo{
+		VertexShader:   vertexShader.Shader,
+		FragmentShader: fragmentShader.Shader,
+	})
+	return result
+}
+
+func (a *API) CreateVertexBuffer(info render.BufferInfo) render.Buffer {
+	c := &call{name: "CreateVertexBuffer"}
+	return a.newBuffer(c, bufferKindVertex, info, a.delegate.CreateVertexBuffer)
+}
+
+func (a *API) CreateIndexBuffer(info render.BufferInfo) render.Buffer {
+	c := &call{name: "CreateIndexBuffer"}
+	return a.newBuffer(c, bufferKindIndex, info, a.delegate.CreateIndexBuffer)
+}
+
+func (a *API) CreatePixelTransferBuffer(info render.BufferInfo) render.Buffer {
+	c := &call{name: "CreatePixelTransferBuffer"}
+	return a.newBuffer(c, bufferKindPixelTransfer, info, a.delegate.CreatePixelTransferBuffer)
+}
+
+func (a *API) CreateUniformBuffer(info render.BufferInfo) render.Buffer {
+	c := &call{name: "CreateUniformBuffer"}
+	return a.newBuffer(c, bufferKindUniform, info, a.delegate.CreateUniformBuffer)
+}
+
+func (a *API) CreateVertexArray(info render.VertexArrayInfo) render.VertexArray {
+	c := &call{name: "CreateVertexArray"}
+	result := &validatedVertexArray{
+		api: a,
+	}
+	delegateInfo := info
+	delegateInfo.Bindings = make([]render.VertexArrayBindingInfo, len(info.Bindings))
+	for i, binding := range info.Bindings {
+		buffer, ok := checkResource[*validatedBuffer](a, c, "vertex buffer", binding.VertexBuffer)
+		if ok {
+			if buffer.kind != bufferKindVertex {
+				a.fail(c, "binding %d uses a %s buffer instead of a vertex buffer", i, buffer.kind)
+			}
+			result.buffers = append(result.buffers, buffer)
+		}
+		delegateInfo.Bindings[i] = render.VertexArrayBindingInfo{
+			VertexBuffer: delegateBuffer(buffer),
+			Stride:       binding.Stride,
+		}
+	}
+	for i, attribute := range info.Attributes {
+		if attribute.Binding < 0 || attribute.Binding >= len(info.Bindings) {
+			a.fail(c, "attribute %d references missing binding %d", i, attribute.Binding)
+		}
+	}
+	indexBuffer, ok := checkOptionalResource[*validatedBuffer](a, c, "index buffer", info.IndexBuffer)
+	if ok && indexBuffer != nil {
+		if indexBuffer.kind != bufferKindIndex {
+			a.fail(c, "index buffer is a %s buffer", indexBuffer.kind)
+		}
+		result.indexBuffer = indexBuffer
+		result.buffers = append(result.buffers, indexBuffer)
+	}
+	delegateInfo.IndexBuffer = delegateBuffer(indexBuffer)
+	if c.failed {
+		result.invalid = true
+		return result
+	}
+	result.VertexArray = a.delegate.CreateVertexArray(delegateInfo)
+	return result
+}
+
+func (a *API) CreatePipeline(info render.PipelineInfo) render.Pipeline {
+	c := &call{name: "CreatePipeline"}
+	result := &validatedPipeline{
+		api: a,
+	}
+	program, _ := checkResource[*validatedProgram](a, c, "program", info.Program)
+	vertexArray, _ := checkResource[*validatedVertexArray](a, c, "vertex array", info.VertexArray)
+	if c.failed {
+		result.invalid = true
+		return result
+	}
+	result.program = program
+	result.vertexArray = vertexArray
+	delegateInfo := info
+	delegateInfo.Program = program.Program
+	delegateInfo.VertexArray = vertexArray.VertexArray
+	result.Pipeline = a.delegate.CreatePipeline(delegateInfo)
+	return result
+}
+
+func (a *API) CreateCommandQueue() render.CommandQueue {
+	return &validatedQueue{
+		CommandQueue: a.delegate.CreateCommandQueue(),
+		api:          a,
+	}
+}
+
+func (a *API) CreateFence() render.Fence {
+	return a.delegate.CreateFence()
+}
+
+func (a *API) BeginRenderPass(info render.RenderPassInfo) {
+	c := &call{name: "BeginRenderPass"}
+	if a.passActive {
+		a.fail(c, "a render pass is already active")
+		return
+	}
+	framebuffer, ok := checkResource[*validatedFramebuffer](a, c, "framebuffer", info.Framebuffer)
+	if !ok {
+		return
+	}
+	for _, attachment := range framebuffer.attachments {
+		if problem := attachment.problem(); problem != "" {
+			a.fail(c, "framebuffer attachment %s", problem)
+			return
+		}
+	}
+	if info.Viewport.Width < 0 || info.Viewport.Height < 0 {
+		a.fail(c, "viewport has negative size %dx%d", info.Viewport.Width, info.Viewport.Height)
+		return
+	}
+	a.passActive = true
+	a.passFramebuffer = framebuffer
+	a.pipeline = nil
+
+	delegateInfo := info
+	delegateInfo.Framebuffer = framebuffer.Framebuffer
+	a.delegate.BeginRenderPass(delegateInfo)
+}
+
+func (a *API) EndRenderPass() {
+	c := &call{name: "EndRenderPass"}
+	if !a.passActive {
+		a.fail(c, "no render pass is active")
+		return
+	}
+	a.passActive = false
+	a.passFramebuffer = nil
+	a.pipeline = nil
+	a.delegate.EndRenderPass()
+}
+
+func (a *API) Invalidate() {
+	a.pipeline = nil
+	a.delegate.Invalidate()
+}
+
+func (a *API) BindPipeline(pipeline render.Pipeline) {
+	c := &call{name: "BindPipeline"}
+	validated, ok := a.checkBindPipelineArgs(c, pipeline)
+	if !ok {
+		return
+	}
+	if a.checkBindPipelineState(c, validated) {
+		a.delegate.BindPipeline(validated.Pipeline)
+	}
+}
+
+func (a *API) Uniform1f(location render.UniformLocation, value float32) {
+	c := &call{name: "Uniform1f"}
+	if a.checkUniformArgs(c, location) && a.checkUniformState(c) {
+		a.delegate.Uniform1f(location, value)
+	}
+}
+
+func (a *API) Uniform1i(location render.UniformLocation, value int) {
+	c := &call{name: "Uniform1i"}
+	if a.checkUniformArgs(c, location) && a.checkUniformState(c) {
+		a.delegate.Uniform1i(location, value)
+	}
+}
+
+func (a *API) Uniform3f(location render.UniformLocation, values [3]float32) {
+	c := &call{name: "Uniform3f"}
+	if a.checkUniformArgs(c, location) && a.checkUniformState(c) {
+		a.delegate.Uniform3f(location, values)
+	}
+}
+
+func (a *API) Uniform4f(location render.UniformLocation, values [4]float32) {
+	c := &call{name: "Uniform4f"}
+	if a.checkUniformArgs(c, location) && a.checkUniformState(c) {
+		a.delegate.Uniform4f(location, values)
+	}
+}
+
+func (a *API) UniformMatrix4f(location render.UniformLocation, values [16]float32) {
+	c := &call{name: "UniformMatrix4f"}
+	if a.checkUniformArgs(c, location) && a.checkUniformState(c) {
+		a.delegate.UniformMatrix4f(location, values)
+	}
+}
+
+func (a *API) UniformBufferUnit(index int, buffer render.Buffer) {
+	c := &call{name: "UniformBufferUnit"}
+	validated, ok := a.checkUniformBufferArgs(c, index, buffer)
+	if ok {
+		a.delegate.UniformBufferUnit(index, validated.Buffer)
+	}
+}
+
+func (a *API) UniformBufferUnitRange(index int, buffer render.Buffer, offset, size int) {
+	c := &call{name: "UniformBufferUnitRange"}
+	validated, ok := a.checkUniformBufferRangeArgs(c, index, buffer, offset, size)
+	if ok {
+		a.delegate.UniformBufferUnitRange(index, validated.Buffer, offset, size)
+	}
+}
+
+func (a *API) TextureUnit(index int, texture render.Texture) {
+	c := &call{name: "TextureUnit"}
+	validated, ok := a.checkTextureUnitArgs(c, index, texture)
+	if ok {
+		a.delegate.TextureUnit(index, validated.Texture)
+	}
+}
+
+func (a *API) Draw(vertexOffset, vertexCount, instanceCount int) {
+	c := &call{name: "Draw"}
+	if a.checkDrawArgs(c, vertexOffset, vertexCount, instanceCount) && a.checkDrawState(c, false) {
+		a.delegate.Draw(vertexOffset, vertexCount, instanceCount)
+	}
+}
+
+func (a *API) DrawIndexed(indexOffset, indexCount, instanceCount int) {
+	c := &call{name: "DrawIndexed"}
+	if a.checkDrawArgs(c, indexOffset, indexCount, instanceCount) && a.checkDrawState(c, true) {
+		a.delegate.DrawIndexed(indexOffset, indexCount, instanceCount)
+	}
+}
+
+func (a *API) CopyContentToTexture(info render.CopyContentToTextureInfo) {
+	c := &call{name: "CopyContentToTexture"}
+	texture, ok := checkResource[*validatedTexture](a, c, "texture", info.Texture)
+	if !ok {
+		return
+	}
+	if info.Width <= 0 || info.Height <= 0 {
+		a.fail(c, "invalid copy size %dx%d", info.Width, info.Height)
+		return
+	}
+	if !a.checkPassState(c) {
+		return
+	}
+	delegateInfo := info
+	delegateInfo.Texture = texture.Texture
+	a.delegate.CopyContentToTexture(delegateInfo)
+}
+
+func (a *API) SubmitQueue(queue render.CommandQueue) {
+	c := &call{name: "SubmitQueue"}
+	validated, ok := queue.(*validatedQueue)
+	if !ok || validated == nil {
+		a.fail(c, "queue (%T) was not created by the validation API", queue)
+		return
+	}
+	if validated.released {
+		a.fail(c, "queue has been released")
+		return
+	}
+	validated.flush()
+	a.delegate.SubmitQueue(validated.CommandQueue)
+}
+
+// fail reports a problem with the specified call.
+func (a *API) fail(c *call, format string, args ...any) {
+	c.failed = true
+	if c.pcs == nil {
+		c.captureStack()
+	}
+	a.handler(&Error{
+		Call:    c.name,
+		Message: fmt.Sprintf(format, args...),
+		Stack:   formatStack(c.pcs),
+	})
+}
+
+func (a *API) newTexture(c *call, kind textureKind, create func() render.Texture) render.Texture {
+	result := &validatedTexture{
+		api:  a,
+		kind: kind,
+	}
+	if c.failed {
+		result.invalid = true
+		return result
+	}
+	result.Texture = create()
+	return result
+}
+
+func (a *API) newShader(c *call, stage shaderStage, info render.ShaderInfo, create func(render.ShaderInfo) render.Shader) render.Shader {
+	result := &validatedShader{
+		api:   a,
+		stage: stage,
+	}
+	if info.SourceCode == "" {
+		a.fail(c, "source code is empty")
+		result.invalid = true
+		return result
+	}
+	result.Shader = create(info)
+	return result
+}
+
+func (a *API) newBuffer(c *call, kind bufferKind, info render.BufferInfo, create func(render.BufferInfo) render.Buffer) render.Buffer {
+	result := &validatedBuffer{
+		api:  a,
+		kind: kind,
+		size: info.Size,
+	}
+	if info.Data != nil {
+		result.size = len(info.Data)
+		if len(info.Data) == 0 {
+			a.fail(c, "data is empty")
+		}
+	} else if info.Size <= 0 {
+		a.fail(c, "invalid size %d", info.Size)
+	}
+	if c.failed {
+		result.invalid = true
+		return result
+	}
+	result.Buffer = create(info)
+	return result
+}
+
+func (a *API) checkSize(c *call, width, height int) {
+	if width <= 0 || height <= 0 {
+		a.fail(c, "invalid size %dx%d", width, height)
+	}
+}
+
+func (a *API) checkBufferRange(c *call, buffer *validatedBuffer, offset, size int) bool {
+	if size <= 0 {
+		a.fail(c, "data is empty")
+		return false
+	}
+	if offset < 0 || offset+size > buffer.size {
+		a.fail(c, "range [%d, %d) is outside of buffer with size %d", offset, offset+size, buffer.size)
+		return false
+	}
+	return true
+}
+
+func (a *API) checkPassState(c *call) bool {
+	if !a.passActive {
+		a.fail(c, "no render pass is active")
+		return false
+	}
+	if problem := a.passFramebuffer.problem(); problem != "" {
+		a.fail(c, "render pass framebuffer %s", problem)
+		return false
+	}
+	return true
+}
+
+func (a *API) checkPipelineState(c *call) bool {
+	if !a.checkPassState(c) {
+		return false
+	}
+	if a.pipeline == nil {
+		a.fail(c, "no pipeline is bound in the current render pass")
+		return false
+	}
+	if problem := a.pipeline.problem(); problem != "" {
+		a.fail(c, "bound pipeline %s", problem)
+		return false
+	}
+	return true
+}
+
+func (a *API) checkBindPipelineArgs(c *call, pipeline render.Pipeline) (*validatedPipeline, bool) {
+	return checkResource[*validatedPipeline](a, c, "pipeline", pipeline)
+}
+
+func (a *API) checkBindPipelineState(c *call, pipeline *validatedPipeline) bool {
+	if !a.checkPassState(c) {
+		return false
+	}
+	if problem := pipeline.problem(); problem != "" {
+		a.fail(c, "pipeline %s", problem)
+		return false
+	}
+	if problem := pipeline.program.problem(); problem != "" {
+		a.fail(c, "pipeline program %s", problem)
+		return false
+	}
+	if problem := pipeline.vertexArray.problem(); problem != "" {
+		a.fail(c, "pipeline vertex array %s", problem)
+		return false
+	}
+	a.pipeline = pipeline
+	return true
+}
+
+func (a *API) checkUniformArgs(c *call, location render.UniformLocation) bool {
+	if location == nil {
+		a.fail(c, "uniform location is nil")
+		return false
+	}
+	return true
+}
+
+func (a *API) checkUniformState(c *call) bool {
+	return a.checkPipelineState(c)
+}
+
+func (a *API) checkUniformBufferArgs(c *call, index int, buffer render.Buffer) (*validatedBuffer, bool) {
+	if index < 0 {
+		a.fail(c, "invalid binding index %d", index)
+		return nil, false
+	}
+	validated, ok := checkResource[*validatedBuffer](a, c, "buffer", buffer)
+	if !ok {
+		return nil, false
+	}
+	if validated.kind != bufferKindUniform {
+		a.fail(c, "buffer is a %s buffer instead of a uniform buffer", validated.kind)
+		return nil, false
+	}
+	return validated, true
+}
+
+func (a *API) checkUniformBufferRangeArgs(c *call, index int, buffer render.Buffer, offset, size int) (*validatedBuffer, bool) {
+	validated, ok := a.checkUniformBufferArgs(c, index, buffer)
+	if !ok {
+		return nil, false
+	}
+	if a.uniformAlignment > 0 && offset%a.uniformAlignment != 0 {
+		a.fail(c, "offset %d is not a multiple of the uniform buffer offset alignment %d", offset, a.uniformAlignment)
+		return nil, false
+	}
+	if !a.checkBufferRange(c, validated, offset, size) {
+		return nil, false
+	}
+	return validated, true
+}
+
+func (a *API) checkTextureUnitArgs(c *call, index int, texture render.Texture) (*validatedTexture, bool) {
+	if index < 0 {
+		a.fail(c, "invalid texture unit %d", index)
+		return nil, false
+	}
+	return checkResource[*validatedTexture](a, c, "texture", texture)
+}
+
+func (a *API) checkDrawArgs(c *call, offset, count, instanceCount int) bool {
+	if offset < 0 || count < 0 || instanceCount < 0 {
+		a.fail(c, "invalid draw range (offset %d, count %d, instances %d)", offset, count, instanceCount)
+		return false
+	}
+	return true
+}
+
+func (a *API) checkDrawState(c *call, indexed bool) bool {
+	if !a.checkPipelineState(c) {
+		return false
+	}
+	vertexArray := a.pipeline.vertexArray
+	if problem := vertexArray.problem(); problem != "" {
+		a.fail(c, "pipeline vertex array %s", problem)
+		return false
+	}
+	for _, buffer := range vertexArray.buffers {
+		if problem := buffer.problem(); problem != "" {
+			a.fail(c, "vertex array buffer %s", problem)
+			return false
+		}
+	}
+	if indexed && vertexArray.indexBuffer == nil {
+		a.fail(c, "pipeline vertex array has no index buffer")
+		return false
+	}
+	return true
+}
+
+func (a *API) checkCopyContentToBufferArgs(c *call, info render.CopyContentToBufferInfo) (*validatedBuffer, bool) {
+	buffer, ok := checkResource[*validatedBuffer](a, c, "buffer", info.Buffer)
+	if !ok {
+		return nil, false
+	}
+	if buffer.kind != bufferKindPixelTransfer {
+		a.fail(c, "buffer is a %s buffer instead of a pixel transfer buffer", buffer.kind)
+		return nil, false
+	}
+	if info.Width <= 0 || info.Height <= 0 {
+		a.fail(c, "invalid copy size %dx%d", info.Width, info.Height)
+		return nil, false
+	}
+	if pixelSize := ext.PixelSize(info.Format); pixelSize > 0 {
+		if !a.checkBufferRange(c, buffer, info.Offset, info.Width*info.Height*pixelSize) {
+			return nil, false
+		}
+	}
+	return buffer, true
+}
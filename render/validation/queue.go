@@ -0,0 +1,226 @@
+package validation
+
+import (
+	"github.com/mokiat/lacking/render"
+)
+
+// queuedCommand is a command that is forwarded to the delegate queue
+// once the queue is submitted and the command passes its state checks.
+type queuedCommand struct {
+	call  *call
+	check func(c *call) bool
+	apply func()
+}
+
+// validatedQueue records commands and validates them when the queue is
+// submitted, since only then is the render pass and pipeline state known.
+type validatedQueue struct {
+	render.CommandQueue
+	api      *API
+	commands []queuedCommand
+	released bool
+}
+
+func (q *validatedQueue) BindPipeline(pipeline render.Pipeline) {
+	c := q.record("CommandQueue.BindPipeline")
+	validated, ok := q.api.checkBindPipelineArgs(c, pipeline)
+	if !ok {
+		return
+	}
+	q.enqueue(c, func(c *call) bool {
+		return q.api.checkBindPipelineState(c, validated)
+	}, func() {
+		q.CommandQueue.BindPipeline(validated.Pipeline)
+	})
+}
+
+func (q *validatedQueue) Uniform1f(location render.UniformLocation, value float32) {
+	c := q.record("CommandQueue.Uniform1f")
+	q.enqueueUniform(c, location, func() {
+		q.CommandQueue.Uniform1f(location, value)
+	})
+}
+
+func (q *validatedQueue) Uniform1i(location render.UniformLocation, value int) {
+	c := q.record("CommandQueue.Uniform1i")
+	q.enqueueUniform(c, location, func() {
+		q.CommandQueue.Uniform1i(location, value)
+	})
+}
+
+func (q *validatedQueue) Uniform3f(location render.UniformLocation, values [3]float32) {
+	c := q.record("CommandQueue.Uniform3f")
+	q.enqueueUniform(c, location, func() {
+		q.CommandQueue.Uniform3f(location, values)
+	})
+}
+
+func (q *validatedQueue) Uniform4f(location render.UniformLocation, values [4]float32) {
+	c := q.record("CommandQueue.Uniform4f")
+	q.enqueueUniform(c, location, func() {
+		q.CommandQueue.Uniform4f(location, values)
+	})
+}
+
+func (q *validatedQueue) UniformMatrix4f(location render.UniformLocation, values [16]float32) {
+	c := q.record("CommandQueue.UniformMatrix4f")
+	q.enqueueUniform(c, location, func() {
+		q.CommandQueue.UniformMatrix4f(location, values)
+	})
+}
+
+func (q *validatedQueue) UniformBufferUnit(index int, buffer render.Buffer) {
+	c := q.record("CommandQueue.UniformBufferUnit")
+	validated, ok := q.api.checkUniformBufferArgs(c, index, buffer)
+	if !ok {
+		return
+	}
+	q.enqueueLive(c, "buffer", &validated.tracked, func() {
+		q.CommandQueue.UniformBufferUnit(index, validated.Buffer)
+	})
+}
+
+func (q *validatedQueue) UniformBufferUnitRange(index int, buffer render.Buffer, offset, size int) {
+	c := q.record("CommandQueue.UniformBufferUnitRange")
+	validated, ok := q.api.checkUniformBufferRangeArgs(c, index, buffer, offset, size)
+	if !ok {
+		return
+	}
+	q.enqueueLive(c, "buffer", &validated.tracked, func() {
+		q.CommandQueue.UniformBufferUnitRange(index, validated.Buffer, offset, size)
+	})
+}
+
+func (q *validatedQueue) TextureUnit(index int, texture render.Texture) {
+	c := q.record("CommandQueue.TextureUnit")
+	validated, ok := q.api.checkTextureUnitArgs(c, index, texture)
+	if !ok {
+		return
+	}
+	q.enqueueLive(c, "texture", &validated.tracked, func() {
+		q.CommandQueue.TextureUnit(index, validated.Texture)
+	})
+}
+
+func (q *validatedQueue) Draw(vertexOffset, vertexCount, instanceCount int) {
+	c := q.record("CommandQueue.Draw")
+	if !q.api.checkDrawArgs(c, vertexOffset, vertexCount, instanceCount) {
+		return
+	}
+	q.enqueue(c, func(c *call) bool {
+		return q.api.checkDrawState(c, false)
+	}, func() {
+		q.CommandQueue.Draw(vertexOffset, vertexCount, instanceCount)
+	})
+}
+
+func (q *validatedQueue) DrawIndexed(indexOffset, indexCount, instanceCount int) {
+	c := q.record("CommandQueue.DrawIndexed")
+	if !q.api.checkDrawArgs(c, indexOffset, indexCount, instanceCount) {
+		return
+	}
+	q.enqueue(c, func(c *call) bool {
+		return q.api.checkDrawState(c, true)
+	}, func() {
+		q.CommandQueue.DrawIndexed(indexOffset, indexCount, instanceCount)
+	})
+}
+
+func (q *validatedQueue) CopyContentToBuffer(info render.CopyContentToBufferInfo) {
+	c := q.record("CommandQueue.CopyContentToBuffer")
+	buffer, ok := q.api.checkCopyContentToBufferArgs(c, info)
+	if !ok {
+		return
+	}
+	q.enqueue(c, func(c *call) bool {
+		if problem := buffer.problem(); problem != "" {
+			q.api.fail(c, "buffer %s", problem)
+			return false
+		}
+		return q.api.checkPassState(c)
+	}, func() {
+		delegateInfo := info
+		delegateInfo.Buffer = buffer.Buffer
+		q.CommandQueue.CopyContentToBuffer(delegateInfo)
+	})
+}
+
+func (q *validatedQueue) UpdateBufferData(buffer render.Buffer, info render.BufferUpdateInfo) {
+	c := q.record("CommandQueue.UpdateBufferData")
+	validated, ok := checkResource[*validatedBuffer](q.api, c, "buffer", buffer)
+	if !ok {
+		return
+	}
+	if !q.api.checkBufferRange(c, validated, info.Offset, len(info.Data)) {
+		return
+	}
+	// The data needs to be copied, since the caller is free to change it
+	// once this method returns.
+	info.Data = append([]byte(nil), info.Data...)
+	q.enqueueLive(c, "buffer", &validated.tracked, func() {
+		q.CommandQueue.UpdateBufferData(validated.Buffer, info)
+	})
+}
+
+func (q *validatedQueue) Release() {
+	if q.released {
+		q.api.fail(&call{name: "CommandQueue.Release"}, "queue has already been released")
+		return
+	}
+	q.released = true
+	q.commands = nil
+	q.CommandQueue.Release()
+}
+
+// flush validates all recorded commands against the current state and
+// forwards the valid ones to the delegate queue.
+func (q *validatedQueue) flush() {
+	for _, command := range q.commands {
+		if command.check(command.call) {
+			command.apply()
+		}
+	}
+	clear(q.commands)
+	q.commands = q.commands[:0]
+}
+
+// record starts tracking a queued call. The stack is captured eagerly,
+// since problems may only be detected once the queue is submitted.
+func (q *validatedQueue) record(name string) *call {
+	c := &call{name: name}
+	c.captureStack()
+	if q.released {
+		q.api.fail(c, "queue has been released")
+	}
+	return c
+}
+
+func (q *validatedQueue) enqueue(c *call, check func(c *call) bool, apply func()) {
+	if c.failed {
+		return
+	}
+	q.commands = append(q.commands, queuedCommand{
+		call:  c,
+		check: check,
+		apply: apply,
+	})
+}
+
+func (q *validatedQueue) enqueueUniform(c *call, location render.UniformLocation, apply func()) {
+	if !q.api.checkUniformArgs(c, location) {
+		return
+	}
+	q.enqueue(c, q.api.checkUniformState, apply)
+}
+
+// enqueueLive enqueues a command that only requires the specified
+// resource to not have been released by the time the queue is submitted.
+func (q *validatedQueue) enqueueLive(c *call, name string, t *tracked, apply func()) {
+	q.enqueue(c, func(c *call) bool {
+		if problem := t.problem(); problem != "" {
+			q.api.fail(c, "%s %s", name, problem)
+			return false
+		}
+		return true
+	}, apply)
+}
@@ -0,0 +1,276 @@
+package validation
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/mokiat/lacking/render"
+)
+
+// tracked holds the lifecycle state of a resource.
+type tracked struct {
+	// invalid indicates that the creation of the resource failed
+	// validation, in which case there is no delegate resource.
+	invalid bool
+
+	released   bool
+	releasePCs []uintptr
+}
+
+func (t *tracked) state() *tracked {
+	return t
+}
+
+// problem returns a description of why the resource cannot be used or
+// an empty string if it can be used.
+func (t *tracked) problem() string {
+	switch {
+	case t.invalid:
+		return "was not created successfully"
+	case t.released:
+		return fmt.Sprintf("has been released at:\n%s", formatStack(t.releasePCs))
+	default:
+		return ""
+	}
+}
+
+type resource interface {
+	state() *tracked
+}
+
+// checkResource verifies that the specified value is a live resource that
+// was created through the validation API and returns it.
+func checkResource[T resource](a *API, c *call, name string, value any) (T, bool) {
+	var zero T
+	if isNil(value) {
+		a.fail(c, "%s is nil", name)
+		return zero, false
+	}
+	result, ok := value.(T)
+	if !ok {
+		a.fail(c, "%s (%T) was not created by the validation API", name, value)
+		return zero, false
+	}
+	if problem := result.state().problem(); problem != "" {
+		a.fail(c, "%s %s", name, problem)
+		return zero, false
+	}
+	return result, true
+}
+
+// checkOptionalResource is like checkResource, except that nil values
+// are allowed.
+func checkOptionalResource[T resource](a *API, c *call, name string, value any) (T, bool) {
+	var zero T
+	if isNil(value) {
+		return zero, true
+	}
+	return checkResource[T](a, c, name, value)
+}
+
+// checkRelease marks the resource as released, unless it is already
+// released, in which case an error is reported.
+func checkRelease(a *API, name string, t *tracked) bool {
+	c := &call{name: name}
+	if t.released {
+		a.fail(c, "resource %s", t.problem())
+		return false
+	}
+	t.released = true
+	c.captureStack()
+	t.releasePCs = c.pcs
+	return !t.invalid
+}
+
+func isNil(value any) bool {
+	if value == nil {
+		return true
+	}
+	reflectValue := reflect.ValueOf(value)
+	return reflectValue.Kind() == reflect.Pointer && reflectValue.IsNil()
+}
+
+func delegateTexture(texture *validatedTexture) render.Texture {
+	if texture == nil {
+		return nil
+	}
+	return texture.Texture
+}
+
+func delegateBuffer(buffer *validatedBuffer) render.Buffer {
+	if buffer == nil {
+		return nil
+	}
+	return buffer.Buffer
+}
+
+type textureKind uint8
+
+const (
+	textureKindColor textureKind = iota
+	textureKindDepth
+	textureKindStencil
+	textureKindDepthStencil
+)
+
+type validatedTexture struct {
+	render.Texture
+	tracked
+	api  *API
+	kind textureKind
+}
+
+func (t *validatedTexture) Release() {
+	if checkRelease(t.api, "Texture.Release", &t.tracked) {
+		t.Texture.Release()
+	}
+}
+
+type validatedFramebuffer struct {
+	render.Framebuffer
+	tracked
+	api         *API
+	isDefault   bool
+	attachments []*validatedTexture
+}
+
+func (f *validatedFramebuffer) Release() {
+	if f.isDefault {
+		f.api.fail(&call{name: "Framebuffer.Release"}, "the default framebuffer cannot be released")
+		return
+	}
+	if checkRelease(f.api, "Framebuffer.Release", &f.tracked) {
+		f.Framebuffer.Release()
+	}
+}
+
+type shaderStage uint8
+
+const (
+	shaderStageVertex shaderStage = iota
+	shaderStageFragment
+)
+
+type validatedShader struct {
+	render.Shader
+	tracked
+	api   *API
+	stage shaderStage
+}
+
+func (s *validatedShader) Release() {
+	if checkRelease(s.api, "Shader.Release", &s.tracked) {
+		s.Shader.Release()
+	}
+}
+
+type validatedProgram struct {
+	render.Program
+	tracked
+	api *API
+}
+
+func (p *validatedProgram) UniformLocation(name string) render.UniformLocation {
+	c := &call{name: "Program.UniformLocation"}
+	if problem := p.problem(); problem != "" {
+		p.api.fail(c, "program %s", problem)
+		return nil
+	}
+	return p.Program.UniformLocation(name)
+}
+
+func (p *validatedProgram) Release() {
+	if checkRelease(p.api, "Program.Release", &p.tracked) {
+		p.Program.Release()
+	}
+}
+
+type bufferKind uint8
+
+const (
+	bufferKindVertex bufferKind = iota
+	bufferKindIndex
+	bufferKindPixelTransfer
+	bufferKindUniform
+)
+
+func (k bufferKind) String() string {
+	switch k {
+	case bufferKindVertex:
+		return "vertex"
+	case bufferKindIndex:
+		return "index"
+	case bufferKindPixelTransfer:
+		return "pixel transfer"
+	case bufferKindUniform:
+		return "uniform"
+	default:
+		return "unknown"
+	}
+}
+
+type validatedBuffer struct {
+	render.Buffer
+	tracked
+	api  *API
+	kind bufferKind
+	size int
+}
+
+func (b *validatedBuffer) Update(info render.BufferUpdateInfo) {
+	c := &call{name: "Buffer.Update"}
+	if problem := b.problem(); problem != "" {
+		b.api.fail(c, "buffer %s", problem)
+		return
+	}
+	if !b.api.checkBufferRange(c, b, info.Offset, len(info.Data)) {
+		return
+	}
+	b.Buffer.Update(info)
+}
+
+func (b *validatedBuffer) Fetch(info render.BufferFetchInfo) {
+	c := &call{name: "Buffer.Fetch"}
+	if problem := b.problem(); problem != "" {
+		b.api.fail(c, "buffer %s", problem)
+		return
+	}
+	if !b.api.checkBufferRange(c, b, info.Offset, len(info.Target)) {
+		return
+	}
+	b.Buffer.Fetch(info)
+}
+
+func (b *validatedBuffer) Release() {
+	if checkRelease(b.api, "Buffer.Release", &b.tracked) {
+		b.Buffer.Release()
+	}
+}
+
+type validatedVertexArray struct {
+	render.VertexArray
+	tracked
+	api         *API
+	buffers     []*validatedBuffer
+	indexBuffer *validatedBuffer
+}
+
+func (v *validatedVertexArray) Release() {
+	if checkRelease(v.api, "VertexArray.Release", &v.tracked) {
+		v.VertexArray.Release()
+	}
+}
+
+type validatedPipeline struct {
+	render.Pipeline
+	tracked
+	api         *API
+	program     *validatedProgram
+	vertexArray *validatedVertexArray
+}
+
+func (p *validatedPipeline) Release() {
+	if checkRelease(p.api, "Pipeline.Release", &p.tracked) {
+		p.Pipeline.Release()
+	}
+}
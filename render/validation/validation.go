@@ -0,0 +1,92 @@
+// Package validation provides a render.API implementation that checks how
+// the API is used before forwarding calls to another implementation.
+//
+// It tracks whether a render pass is active and which pipeline is bound,
+// whether resources have been released and whether call arguments are
+// valid (e.g. nil resources, out of range buffer regions or misaligned
+// uniform buffer offsets). Any misuse is reported as an *Error that holds
+// the stack of the offending caller.
+//
+// Validation adds overhead to every call and is meant to be used during
+// development only.
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+
+	"github.com/mokiat/lacking/log"
+)
+
+var logger = log.Path("/lacking-gl/render/validation")
+
+var packagePath = reflect.TypeOf(API{}).PkgPath()
+
+// Error describes an incorrect usage of the render API.
+type Error struct {
+
+	// Call is the name of the call that was made incorrectly
+	// (e.g. "Draw" or "CommandQueue.Draw").
+	Call string
+
+	// Message describes the problem.
+	Message string
+
+	// Stack is a formatted stack trace of the caller that made the
+	// incorrect call.
+	Stack string
+}
+
+// Error returns a description of the problem, including the stack trace
+// of the caller.
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s\n%s", e.Call, e.Message, e.Stack)
+}
+
+// ErrorHandler is a function that is called for each incorrect usage
+// that is detected.
+type ErrorHandler func(err *Error)
+
+// LogError is an ErrorHandler that logs errors. It is used when no
+// handler is specified.
+func LogError(err *Error) {
+	logger.Error("Render API misuse: %v", err)
+}
+
+// PanicOnError is an ErrorHandler that panics with the error, which
+// stops the application at the first incorrect usage.
+func PanicOnError(err *Error) {
+	panic(err)
+}
+
+// call tracks a single invocation of an API or command queue method.
+type call struct {
+	name   string
+	pcs    []uintptr
+	failed bool
+}
+
+// captureStack stores the program counters of the current caller,
+// skipping all frames that are part of this package.
+func (c *call) captureStack() {
+	pcs := make([]uintptr, 64)
+	count := runtime.Callers(2, pcs)
+	c.pcs = pcs[:count]
+}
+
+func formatStack(pcs []uintptr) string {
+	var builder strings.Builder
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, packagePath+".") {
+			fmt.Fprintf(&builder, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		}
+		if !more {
+			break
+		}
+	}
+	return builder.String()
+}
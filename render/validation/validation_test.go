@@ -0,0 +1,488 @@
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mokiat/lacking/render"
+)
+
+func TestAPI(t *testing.T) {
+	uniformBufferOffsetAlignment = func() int {
+		return 256
+	}
+
+	testCases := []struct {
+		name           string
+		run            func(api render.API)
+		expectedErrors []string
+		expectedCalls  []string
+	}{
+		{
+			name: "valid vertex array",
+			run: func(api render.API) {
+				vertexBuffer := api.CreateVertexBuffer(render.BufferInfo{Size: 16})
+				indexBuffer := api.CreateIndexBuffer(render.BufferInfo{Size: 4})
+				api.CreateVertexArray(render.VertexArrayInfo{
+					Bindings: []render.VertexArrayBindingInfo{
+						{VertexBuffer: vertexBuffer, Stride: 8},
+					},
+					Attributes: []render.VertexArrayAttributeInfo{
+						{Binding: 0, Location: 0, Format: render.VertexAttributeFormatRG32F},
+					},
+					IndexBuffer: indexBuffer,
+				})
+			},
+			expectedCalls: []string{
+				"CreateVertexBuffer 2 16",
+				"CreateIndexBuffer 3 4",
+				"CreateVertexArray 4 [2/8] 3",
+			},
+		},
+		{
+			name: "vertex array with wrong buffer kind",
+			run: func(api render.API) {
+				indexBuffer := api.CreateIndexBuffer(render.BufferInfo{Size: 4})
+				api.CreateVertexArray(render.VertexArrayInfo{
+					Bindings: []render.VertexArrayBindingInfo{
+						{VertexBuffer: indexBuffer, Stride: 8},
+					},
+				})
+			},
+			expectedErrors: []string{
+				"CreateVertexArray: binding 0 uses a index buffer instead of a vertex buffer",
+			},
+			expectedCalls: []string{
+				"CreateIndexBuffer 2 4",
+			},
+		},
+		{
+			name: "vertex array with missing binding",
+			run: func(api render.API) {
+				vertexBuffer := api.CreateVertexBuffer(render.BufferInfo{Size: 16})
+				api.CreateVertexArray(render.VertexArrayInfo{
+					Bindings: []render.VertexArrayBindingInfo{
+						{VertexBuffer: vertexBuffer, Stride: 8},
+					},
+					Attributes: []render.VertexArrayAttributeInfo{
+						{Binding: 1, Location: 0, Format: render.VertexAttributeFormatRG32F},
+					},
+				})
+			},
+			expectedErrors: []string{
+				"CreateVertexArray: attribute 0 references missing binding 1",
+			},
+			expectedCalls: []string{
+				"CreateVertexBuffer 2 16",
+			},
+		},
+		{
+			name: "vertex array with released buffer",
+			run: func(api render.API) {
+				vertexBuffer := api.CreateVertexBuffer(render.BufferInfo{Size: 16})
+				vertexBuffer.Release()
+				api.CreateVertexArray(render.VertexArrayInfo{
+					Bindings: []render.VertexArrayBindingInfo{
+						{VertexBuffer: vertexBuffer, Stride: 8},
+					},
+				})
+			},
+			expectedErrors: []string{
+				"CreateVertexArray: vertex buffer has been released at:",
+			},
+			expectedCalls: []string{
+				"CreateVertexBuffer 2 16",
+				"Release 2",
+			},
+		},
+		{
+			name: "buffer released twice",
+			run: func(api render.API) {
+				vertexBuffer := api.CreateVertexBuffer(render.BufferInfo{Size: 16})
+				vertexBuffer.Release()
+				vertexBuffer.Release()
+			},
+			expectedErrors: []string{
+				"Buffer.Release: resource has been released at:",
+			},
+			expectedCalls: []string{
+				"CreateVertexBuffer 2 16",
+				"Release 2",
+			},
+		},
+		{
+			name: "buffer update outside of range",
+			run: func(api render.API) {
+				vertexBuffer := api.CreateVertexBuffer(render.BufferInfo{Size: 16})
+				vertexBuffer.Update(render.BufferUpdateInfo{Data: make([]byte, 8), Offset: 12})
+			},
+			expectedErrors: []string{
+				"Buffer.Update: range [12, 20) is outside of buffer with size 16",
+			},
+			expectedCalls: []string{
+				"CreateVertexBuffer 2 16",
+			},
+		},
+		{
+			name: "uniform buffer unit with wrong buffer kind",
+			run: func(api render.API) {
+				vertexBuffer := api.CreateVertexBuffer(render.BufferInfo{Size: 16})
+				api.UniformBufferUnit(0, vertexBuffer)
+			},
+			expectedErrors: []string{
+				"UniformBufferUnit: buffer is a vertex buffer instead of a uniform buffer",
+			},
+			expectedCalls: []string{
+				"CreateVertexBuffer 2 16",
+			},
+		},
+		{
+			name: "uniform buffer range with misaligned offset",
+			run: func(api render.API) {
+				uniformBuffer := api.CreateUniformBuffer(render.BufferInfo{Size: 1024})
+				api.UniformBufferUnitRange(0, uniformBuffer, 128, 64)
+				api.UniformBufferUnitRange(1, uniformBuffer, 256, 64)
+			},
+			expectedErrors: []string{
+				"UniformBufferUnitRange: offset 128 is not a multiple of the uniform buffer offset alignment 256",
+			},
+			expectedCalls: []string{
+				"CreateUniformBuffer 2 1024",
+				"UniformBufferUnitRange 1 2 256 64",
+			},
+		},
+		{
+			name: "program with wrong shader stage",
+			run: func(api render.API) {
+				fragmentShader := api.CreateFragmentShader(render.ShaderInfo{SourceCode: "fragment"})
+				api.CreateProgram(render.ProgramInfo{
+					VertexShader:   fragmentShader,
+					FragmentShader: fragmentShader,
+				})
+			},
+			expectedErrors: []string{
+				"CreateProgram: vertex shader is not a vertex stage shader",
+			},
+			expectedCalls: []string{
+				"CreateFragmentShader 2 fragment",
+			},
+		},
+		{
+			name: "draw outside of render pass",
+			run: func(api render.API) {
+				api.Draw(0, 3, 1)
+			},
+			expectedErrors: []string{
+				"Draw: no render pass is active",
+			},
+		},
+		{
+			name: "draw without pipeline",
+			run: func(api render.API) {
+				api.BeginRenderPass(render.RenderPassInfo{
+					Framebuffer: api.DefaultFramebuffer(),
+				})
+				api.Draw(0, 3, 1)
+				api.EndRenderPass()
+			},
+			expectedErrors: []string{
+				"Draw: no pipeline is bound in the current render pass",
+			},
+			expectedCalls: []string{
+				"BeginRenderPass 1",
+				"EndRenderPass",
+			},
+		},
+		{
+			name: "indexed draw without index buffer",
+			run: func(api render.API) {
+				pipeline := createPipeline(api)
+				api.BeginRenderPass(render.RenderPassInfo{
+					Framebuffer: api.DefaultFramebuffer(),
+				})
+				api.BindPipeline(pipeline)
+				api.DrawIndexed(0, 3, 1)
+				api.EndRenderPass()
+			},
+			expectedErrors: []string{
+				"DrawIndexed: pipeline vertex array has no index buffer",
+			},
+			expectedCalls: []string{
+				"CreateVertexShader 2 vertex",
+				"CreateFragmentShader 3 fragment",
+				"CreateProgram 4 2 3",
+				"CreateVertexBuffer 5 16",
+				"CreateVertexArray 6 [5/8] 0",
+				"CreatePipeline 7 4 6",
+				"BeginRenderPass 1",
+				"BindPipeline 7",
+				"EndRenderPass",
+			},
+		},
+		{
+			name: "draw with released pipeline",
+			run: func(api render.API) {
+				pipeline := createPipeline(api)
+				pipeline.Release()
+				api.BeginRenderPass(render.RenderPassInfo{
+					Framebuffer: api.DefaultFramebuffer(),
+				})
+				api.BindPipeline(pipeline)
+				api.Draw(0, 3, 1)
+				api.EndRenderPass()
+			},
+			expectedErrors: []string{
+				"BindPipeline: pipeline has been released at:",
+				"Draw: no pipeline is bound in the current render pass",
+			},
+			expectedCalls: []string{
+				"CreateVertexShader 2 vertex",
+				"CreateFragmentShader 3 fragment",
+				"CreateProgram 4 2 3",
+				"CreateVertexBuffer 5 16",
+				"CreateVertexArray 6 [5/8] 0",
+				"CreatePipeline 7 4 6",
+				"Release 7",
+				"BeginRenderPass 1",
+				"EndRenderPass",
+			},
+		},
+		{
+			name: "valid draw",
+			run: func(api render.API) {
+				pipeline := createPipeline(api)
+				api.BeginRenderPass(render.RenderPassInfo{
+					Framebuffer: api.DefaultFramebuffer(),
+					Viewport: render.Area{
+						Width:  640,
+						Height: 480,
+					},
+				})
+				api.BindPipeline(pipeline)
+				api.Draw(0, 3, 1)
+				api.EndRenderPass()
+			},
+			expectedCalls: []string{
+				"CreateVertexShader 2 vertex",
+				"CreateFragmentShader 3 fragment",
+				"CreateProgram 4 2 3",
+				"CreateVertexBuffer 5 16",
+				"CreateVertexArray 6 [5/8] 0",
+				"CreatePipeline 7 4 6",
+				"BeginRenderPass 1",
+				"BindPipeline 7",
+				"Draw 0 3 1",
+				"EndRenderPass",
+			},
+		},
+		{
+			name: "nested render pass",
+			run: func(api render.API) {
+				api.BeginRenderPass(render.RenderPassInfo{
+					Framebuffer: api.DefaultFramebuffer(),
+				})
+				api.BeginRenderPass(render.RenderPassInfo{
+					Framebuffer: api.DefaultFramebuffer(),
+				})
+				api.EndRenderPass()
+				api.EndRenderPass()
+			},
+			expectedErrors: []string{
+				"BeginRenderPass: a render pass is already active",
+				"EndRenderPass: no render pass is active",
+			},
+			expectedCalls: []string{
+				"BeginRenderPass 1",
+				"EndRenderPass",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var errors []string
+			delegate := newFakeAPI()
+			api := NewAPI(delegate, func(err *Error) {
+				// Only the first line is compared, since messages about
+				// released resources continue with a stack trace.
+				message, _, _ := strings.Cut(err.Message, "\n")
+				errors = append(errors, fmt.Sprintf("%s: %s", err.Call, message))
+			})
+			tc.run(api)
+			if !reflect.DeepEqual(tc.expectedErrors, errors) {
+				t.Errorf("expected %v, got %v", tc.expectedErrors, errors)
+			}
+			if !reflect.DeepEqual(tc.expectedCalls, delegate.calls) {
+				t.Errorf("expected %v, got %v", tc.expectedCalls, delegate.calls)
+			}
+		})
+	}
+}
+
+func createPipeline(api render.API) render.Pipeline {
+	vertexShader := api.CreateVertexShader(render.ShaderInfo{SourceCode: "vertex"})
+	fragmentShader := api.CreateFragmentShader(render.ShaderInfo{SourceCode: "fragment"})
+	program := api.CreateProgram(render.ProgramInfo{
+		VertexShader:   vertexShader,
+		FragmentShader: fragmentShader,
+	})
+	vertexBuffer := api.CreateVertexBuffer(render.BufferInfo{Size: 16})
+	vertexArray := api.CreateVertexArray(render.VertexArrayInfo{
+		Bindings: []render.VertexArrayBindingInfo{
+			{VertexBuffer: vertexBuffer, Stride: 8},
+		},
+	})
+	return api.CreatePipeline(render.PipelineInfo{
+		Program:     program,
+		VertexArray: vertexArray,
+	})
+}
+
+// newFakeAPI creates a render.API whose default framebuffer has the name 1
+// and that assigns consecutive names to the objects that it creates.
+func newFakeAPI() *fakeAPI {
+	result := &fakeAPI{
+		nextID: 1,
+	}
+	result.defaultFramebuffer = result.newObject()
+	return result
+}
+
+// fakeAPI is a render.API that logs the calls that are made to it. Calls
+// that are not implemented panic.
+type fakeAPI struct {
+	render.API
+	nextID             uint32
+	defaultFramebuffer *fakeObject
+	calls              []string
+}
+
+func (a *fakeAPI) log(format string, args ...any) {
+	a.calls = append(a.calls, fmt.Sprintf(format, args...))
+}
+
+func (a *fakeAPI) newObject() *fakeObject {
+	object := &fakeObject{
+		api: a,
+		id:  a.nextID,
+	}
+	a.nextID++
+	return object
+}
+
+func (a *fakeAPI) DefaultFramebuffer() render.Framebuffer {
+	return a.defaultFramebuffer
+}
+
+func (a *fakeAPI) CreateVertexShader(info render.ShaderInfo) render.Shader {
+	shader := a.newObject()
+	a.log("CreateVertexShader %d %s", shader.id, info.SourceCode)
+	return shader
+}
+
+func (a *fakeAPI) CreateFragmentShader(info render.ShaderInfo) render.Shader {
+	shader := a.newObject()
+	a.log("CreateFragmentShader %d %s", shader.id, info.SourceCode)
+	return shader
+}
+
+func (a *fakeAPI) CreateProgram(info render.ProgramInfo) render.Program {
+	program := a.newObject()
+	a.log("CreateProgram %d %d %d", program.id, fakeID(info.VertexShader), fakeID(info.FragmentShader))
+	return program
+}
+
+func (a *fakeAPI) CreateVertexBuffer(info render.BufferInfo) render.Buffer {
+	buffer := a.newObject()
+	a.log("CreateVertexBuffer %d %d", buffer.id, info.Size)
+	return buffer
+}
+
+func (a *fakeAPI) CreateIndexBuffer(info render.BufferInfo) render.Buffer {
+	buffer := a.newObject()
+	a.log("CreateIndexBuffer %d %d", buffer.id, info.Size)
+	return buffer
+}
+
+func (a *fakeAPI) CreateUniformBuffer(info render.BufferInfo) render.Buffer {
+	buffer := a.newObject()
+	a.log("CreateUniformBuffer %d %d", buffer.id, info.Size)
+	return buffer
+}
+
+func (a *fakeAPI) CreateVertexArray(info render.VertexArrayInfo) render.VertexArray {
+	vertexArray := a.newObject()
+	bindings := make([]string, len(info.Bindings))
+	for i, binding := range info.Bindings {
+		bindings[i] = fmt.Sprintf("%d/%d", fakeID(binding.VertexBuffer), binding.Stride)
+	}
+	a.log("CreateVertexArray %d %v %d", vertexArray.id, bindings, fakeID(info.IndexBuffer))
+	return vertexArray
+}
+
+func (a *fakeAPI) CreatePipeline(info render.PipelineInfo) render.Pipeline {
+	pipeline := a.newObject()
+	a.log("CreatePipeline %d %d %d", pipeline.id, fakeID(info.Program), fakeID(info.VertexArray))
+	return pipeline
+}
+
+func (a *fakeAPI) BeginRenderPass(info render.RenderPassInfo) {
+	a.log("BeginRenderPass %d", fakeID(info.Framebuffer))
+}
+
+func (a *fakeAPI) EndRenderPass() {
+	a.log("EndRenderPass")
+}
+
+func (a *fakeAPI) BindPipeline(pipeline render.Pipeline) {
+	a.log("BindPipeline %d", fakeID(pipeline))
+}
+
+func (a *fakeAPI) UniformBufferUnitRange(index int, buffer render.Buffer, offset, size int) {
+	a.log("UniformBufferUnitRange %d %d %d %d", index, fakeID(buffer), offset, size)
+}
+
+func (a *fakeAPI) Draw(vertexOffset, vertexCount, instanceCount int) {
+	a.log("Draw %d %d %d", vertexOffset, vertexCount, instanceCount)
+}
+
+// fakeObject is a resource of fakeAPI that can act as any kind of
+// resource.
+type fakeObject struct {
+	render.FramebufferObject
+	render.ShaderObject
+	render.ProgramObject
+	render.BufferObject
+	render.VertexArrayObject
+	render.PipelineObject
+	api *fakeAPI
+	id  uint32
+}
+
+func (o *fakeObject) UniformLocation(name string) render.UniformLocation {
+	panic("not implemented")
+}
+
+func (o *fakeObject) Update(info render.BufferUpdateInfo) {
+	o.api.log("Update %d %d", o.id, info.Offset)
+}
+
+func (o *fakeObject) Fetch(info render.BufferFetchInfo) {
+	panic("not implemented")
+}
+
+func (o *fakeObject) Release() {
+	o.api.log("Release %d", o.id)
+}
+
+// fakeID returns the name of the specified fakeObject, which panics when
+// the validation API forwards one of its own wrappers instead, or 0 for
+// nil values.
+func fakeID(value any) uint32 {
+	if isNil(value) {
+		return 0
+	}
+	return value.(*fakeObject).id
+}
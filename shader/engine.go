@@ -0,0 +1,252 @@
+package shader
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// NewEngine creates a new Engine that uses the templates that are
+// embedded into this package, overridden by the templates of the
+// specified sets, in order.
+func NewEngine(sets ...fs.FS) (*Engine, error) {
+	engine := &Engine{
+		sets:    append([]fs.FS{sources}, sets...),
+		results: make(map[resultKey]string),
+	}
+	if err := engine.reload(); err != nil {
+		return nil, err
+	}
+	return engine, nil
+}
+
+// Engine generates shader source code from templates. It is safe for
+// concurrent use.
+//
+// Templates are loaded from a stack of template sets, where a template in
+// a set replaces any template with the same name in the sets below it.
+// All *.glsl files at the root of a set are loaded as templates.
+//
+// Generated source code is memoized by template name and data, as long
+// as the data is comparable. Pointers are compared by identity, so data
+// that is reachable through pointers should not change between calls.
+type Engine struct {
+	mu      sync.RWMutex
+	sets    []fs.FS
+	state   *templateState
+	results map[resultKey]string
+
+	// runs holds the template invocations that produced each generated
+	// source code. It is only populated while a directory is watched.
+	runs     map[string]templateRun
+	watching bool
+}
+
+// AddTemplates registers an additional set of templates on top of the
+// existing ones. Templates in it override existing templates with the
+// same name. Nothing is changed if the set fails to load.
+func (e *Engine) AddTemplates(set fs.FS) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.sets = append(e.sets, set)
+	if err := e.reload(); err != nil {
+		e.sets = e.sets[:len(e.sets)-1]
+		return err
+	}
+	return nil
+}
+
+// Run executes the template with the specified name and returns the
+// generated source code.
+func (e *Engine) Run(name string, data any) (string, error) {
+	key, memoizable := newResultKey(name, data)
+
+	e.mu.RLock()
+	state := e.state
+	var result string
+	var ok bool
+	if memoizable {
+		result, ok = e.results[key]
+	}
+	tracking := e.runs != nil
+	e.mu.RUnlock()
+
+	if !ok {
+		var err error
+		result, err = state.run(name, data)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	// The write lock is only needed when there is something to store,
+	// so that memoized results can be returned concurrently.
+	store := memoizable && !ok
+	if !store && !tracking {
+		return result, nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if store && e.state == state {
+		e.results[key] = result
+	}
+	if e.runs != nil {
+		e.runs[result] = templateRun{
+			name: name,
+			data: data,
+		}
+	}
+	return result, nil
+}
+
+// Source executes the template with the specified name and returns the
+// generated source code. If that fails, the error is logged and source
+// code that fails to compile with the error message is returned instead.
+// This way, the problem is reported through the shader diagnostics of the
+// render API, instead of crashing the application.
+func (e *Engine) Source(name string, data any) string {
+	result, err := e.Run(name, data)
+	if err != nil {
+		logger.Error("Failed to generate shader from %q: %v", name, err)
+		result = errorSource(err)
+		// The invocation is tracked, so that a fix can be picked up
+		// while a directory is watched.
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		if e.runs != nil {
+			e.runs[result] = templateRun{
+				name: name,
+				data: data,
+			}
+		}
+	}
+	return result
+}
+
+// Regenerate returns the source code that the template invocation which
+// produced the specified source code produces with the current templates.
+// It returns false if the source code was not produced while a directory
+// was watched (see WatchDir) or if the template fails to execute, in which
+// case the error is logged.
+func (e *Engine) Regenerate(source string) (string, bool) {
+	e.mu.RLock()
+	run, ok := e.runs[source]
+	e.mu.RUnlock()
+	if !ok {
+		return "", false
+	}
+
+	result, err := e.Run(run.name, run.data)
+	if err != nil {
+		logger.Error("Failed to regenerate shader from %q: %v", run.name, err)
+		return "", false
+	}
+	// NOTE: The previous source code is kept as well, since it remains in
+	// use by programs that fail to build with the new one.
+	return result, true
+}
+
+// reload parses the templates of all sets and, if that succeeds, makes
+// them current. The caller needs to hold the lock, unless the engine is
+// not yet shared.
+func (e *Engine) reload() error {
+	state, err := parseTemplateSets(e.sets)
+	if err != nil {
+		return err
+	}
+	e.state = state
+	clear(e.results)
+	return nil
+}
+
+// templateState is an immutable snapshot of the parsed templates.
+type templateState struct {
+
+	// names holds the names of all templates. The source string number
+	// of a template, as used in #line directives, is its index plus one.
+	names []string
+
+	root *template.Template
+}
+
+func (s *templateState) run(name string, data any) (string, error) {
+	tmpl := s.root.Lookup(name)
+	if tmpl == nil {
+		return "", fmt.Errorf("template %q not found", name)
+	}
+
+	var buffer bytes.Buffer
+	if err := tmpl.Execute(&buffer, data); err != nil {
+		return "", fmt.Errorf("template exec error: %w", err)
+	}
+	writeSourceNames(&buffer, s.names)
+	return buffer.String(), nil
+}
+
+// errorSource returns source code that fails to compile with the
+// message of the specified error.
+func errorSource(err error) string {
+	message := strings.Join(strings.Fields(err.Error()), " ")
+	return fmt.Sprintf("#version 460\n#error %s\n", message)
+}
+
+func parseTemplateSets(sets []fs.FS) (*templateState, error) {
+	origins := make(map[string]fs.FS)
+	for _, set := range sets {
+		names, err := fs.Glob(set, "*.glsl")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list templates: %w", err)
+		}
+		for _, name := range names {
+			origins[name] = set
+		}
+	}
+
+	state := &templateState{
+		root: template.New("root").Delims("/*", "*/"),
+	}
+	for name := range origins {
+		state.names = append(state.names, name)
+	}
+	slices.Sort(state.names)
+
+	for i, name := range state.names {
+		content, err := fs.ReadFile(origins[name], name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template %q: %w", name, err)
+		}
+		text := addLineDirectives(string(content), i+1)
+		if _, err := state.root.New(name).Parse(text); err != nil {
+			return nil, fmt.Errorf("failed to parse template %q: %w", name, err)
+		}
+	}
+	return state, nil
+}
+
+// resultKey identifies a template invocation.
+type resultKey struct {
+	name string
+	data any
+}
+
+// newResultKey returns the key of the specified template invocation and
+// whether it can be used as a map key.
+func newResultKey(name string, data any) (resultKey, bool) {
+	key := resultKey{
+		name: name,
+		data: data,
+	}
+	return key, data == nil || reflect.ValueOf(data).Comparable()
+}
+
+// templateRun is a single invocation of a template.
+type templateRun struct {
+	name string
+	data any
+}
@@ -4,29 +4,78 @@ import (
 	"bytes"
 	"embed"
 	"fmt"
-	"text/template"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 )
 
 //go:embed *.glsl
 var sources embed.FS
 
-var rootTemplate = template.Must(template.
-	New("root").
-	Delims("/*", "*/").
-	ParseFS(sources, "*.glsl"),
-)
+var lineDirectivePattern = regexp.MustCompile(`(?m)^#line \d+ (\d+)$`)
 
-var buffer = new(bytes.Buffer)
+// Default is the engine that is used by the package-level functions.
+var Default = must(NewEngine())
 
+// RunTemplate executes the template with the specified name using the
+// Default engine and panics if that fails. Use Default.Run to handle
+// errors instead.
 func RunTemplate(name string, data any) string {
-	tmpl := rootTemplate.Lookup(name)
-	if tmpl == nil {
-		panic(fmt.Errorf("template %q not found", name))
+	return must(Default.Run(name, data))
+}
+
+// addLineDirectives inserts #line directives into the specified template
+// text, so that driver diagnostics refer to lines of the template file
+// instead of lines of the generated source code.
+//
+// A directive is placed at the start of the file and after each line
+// that contains a template action, since actions can include other
+// templates or omit lines. The directive at the start is skipped when
+// the first line contains an action or a #version directive, since no
+// directive may precede #version.
+func addLineDirectives(text string, sourceString int) string {
+	var builder strings.Builder
+	lines := strings.Split(text, "\n")
+	if firstLine := lines[0]; !strings.Contains(firstLine, "/*") && !strings.HasPrefix(strings.TrimSpace(firstLine), "#version") {
+		fmt.Fprintf(&builder, "#line 1 %d\n", sourceString)
 	}
+	for i, line := range lines {
+		builder.WriteString(line)
+		if i == len(lines)-1 {
+			break
+		}
+		builder.WriteString("\n")
+		if strings.Contains(line, "/*") {
+			fmt.Fprintf(&builder, "#line %d %d\n", i+2, sourceString)
+		}
+	}
+	return builder.String()
+}
+
+// writeSourceNames appends a comment for each source string that is
+// referenced by the generated source code, naming the file that it
+// originates from. The render API uses these to map diagnostics back
+// to template files.
+func writeSourceNames(buffer *bytes.Buffer, sourceNames []string) {
+	var numbers []int
+	for _, match := range lineDirectivePattern.FindAllSubmatch(buffer.Bytes(), -1) {
+		number, _ := strconv.Atoi(string(match[1]))
+		if !slices.Contains(numbers, number) {
+			numbers = append(numbers, number)
+		}
+	}
+	slices.Sort(numbers)
+	for _, number := range numbers {
+		if number > 0 && number <= len(sourceNames) {
+			fmt.Fprintf(buffer, "\n// source-string %d: %s", number, sourceNames[number-1])
+		}
+	}
+}
 
-	buffer.Reset()
-	if err := tmpl.Execute(buffer, data); err != nil {
-		panic(fmt.Errorf("template exec error: %w", err))
+func must[T any](value T, err error) T {
+	if err != nil {
+		panic(err)
 	}
-	return buffer.String()
+	return value
 }
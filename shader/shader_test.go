@@ -0,0 +1,263 @@
+package shader
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestAddLineDirectives(t *testing.T) {
+	testCases := []struct {
+		name         string
+		text         string
+		sourceString int
+		expected     string
+	}{
+		{
+			name:         "plain text",
+			text:         "uniform float a;\nuniform float b;",
+			sourceString: 3,
+			expected:     "#line 1 3\nuniform float a;\nuniform float b;",
+		},
+		{
+			name:         "version first",
+			text:         "#version 460 core\nvoid main() {}",
+			sourceString: 1,
+			expected:     "#version 460 core\nvoid main() {}",
+		},
+		{
+			name:         "action first",
+			text:         "/* template \"header\" */\nvoid main() {}",
+			sourceString: 2,
+			expected:     "/* template \"header\" */\n#line 2 2\nvoid main() {}",
+		},
+		{
+			name: "actions in the middle",
+			text: "float a;\n" +
+				"/* if .Shadows */\n" +
+				"float b;\n" +
+				"/* end */\n" +
+				"float c;",
+			sourceString: 4,
+			expected: "#line 1 4\n" +
+				"float a;\n" +
+				"/* if .Shadows */\n" +
+				"#line 3 4\n" +
+				"float b;\n" +
+				"/* end */\n" +
+				"#line 5 4\n" +
+				"float c;",
+		},
+		{
+			name:         "action on last line",
+			text:         "float a;\n/* end */",
+			sourceString: 1,
+			expected:     "#line 1 1\nfloat a;\n/* end */",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			actual := addLineDirectives(testCase.text, testCase.sourceString)
+			if actual != testCase.expected {
+				t.Errorf("expected %q, got %q", testCase.expected, actual)
+			}
+		})
+	}
+}
+
+func TestWriteSourceNames(t *testing.T) {
+	var buffer bytes.Buffer
+	buffer.WriteString("#version 460\n" +
+		"#line 1 3\n" +
+		"float a;\n" +
+		"#line 1 1\n" +
+		"float b;\n" +
+		"#line 4 3\n" +
+		"float c;\n" +
+		"#line 1 9\n" +
+		"void main() {}",
+	)
+	writeSourceNames(&buffer, []string{"main.frag.glsl", "unused.glsl", "common.glsl"})
+
+	expected := "#version 460\n" +
+		"#line 1 3\n" +
+		"float a;\n" +
+		"#line 1 1\n" +
+		"float b;\n" +
+		"#line 4 3\n" +
+		"float c;\n" +
+		"#line 1 9\n" +
+		"void main() {}\n" +
+		"// source-string 1: main.frag.glsl\n" +
+		"// source-string 3: common.glsl"
+	if actual := buffer.String(); actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+}
+
+func TestEngineMemoization(t *testing.T) {
+	engine := newTestEngine(t, fstest.MapFS{
+		"count.glsl": {Data: []byte("count /* .Counter.Next */")},
+	})
+
+	// Comparable data is memoized.
+	comparableCounter := &counter{}
+	comparableData := struct{ Counter *counter }{comparableCounter}
+	assertRun(t, engine, "count.glsl", comparableData, "count 1")
+	assertRun(t, engine, "count.glsl", comparableData, "count 1")
+
+	// Data that cannot be used as a map key is not.
+	mapCounter := &counter{}
+	mapData := map[string]any{"Counter": mapCounter}
+	assertRun(t, engine, "count.glsl", mapData, "count 1")
+	assertRun(t, engine, "count.glsl", mapData, "count 2")
+
+	// Adding templates discards memoized results.
+	if err := engine.AddTemplates(fstest.MapFS{}); err != nil {
+		t.Fatalf("failed to add templates: %v", err)
+	}
+	assertRun(t, engine, "count.glsl", comparableData, "count 2")
+}
+
+func TestEngineAddTemplates(t *testing.T) {
+	engine := newTestEngine(t, fstest.MapFS{
+		"main.glsl": {Data: []byte("main /* template \"part.glsl\" */")},
+		"part.glsl": {Data: []byte("part one")},
+	})
+	assertRunContains(t, engine, "main.glsl", "part one")
+
+	err := engine.AddTemplates(fstest.MapFS{
+		"part.glsl": {Data: []byte("part two")},
+	})
+	if err != nil {
+		t.Fatalf("failed to add templates: %v", err)
+	}
+	assertRunContains(t, engine, "main.glsl", "part two")
+
+	// A set that fails to parse leaves the earlier templates in place.
+	err = engine.AddTemplates(fstest.MapFS{
+		"part.glsl":  {Data: []byte("part three")},
+		"other.glsl": {Data: []byte("/* if */")},
+	})
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	assertRunContains(t, engine, "main.glsl", "part two")
+	if _, err := engine.Run("other.glsl", nil); err == nil {
+		t.Errorf("expected an error")
+	}
+	if expected := 3; len(engine.sets) != expected {
+		t.Errorf("expected %d, got %d", expected, len(engine.sets))
+	}
+}
+
+func TestEngineRunErrors(t *testing.T) {
+	engine := newTestEngine(t, fstest.MapFS{
+		"field.glsl": {Data: []byte("/* .Missing */")},
+	})
+
+	testCases := []struct {
+		name     string
+		template string
+		data     any
+		expected string
+	}{
+		{
+			name:     "missing template",
+			template: "missing.glsl",
+			expected: `template "missing.glsl" not found`,
+		},
+		{
+			name:     "execution failure",
+			template: "field.glsl",
+			data:     struct{}{},
+			expected: "template exec error: ",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			result, err := engine.Run(testCase.template, testCase.data)
+			if err == nil {
+				t.Fatalf("expected an error, got %q", result)
+			}
+			if !strings.HasPrefix(err.Error(), testCase.expected) {
+				t.Errorf("expected %q, got %q", testCase.expected, err.Error())
+			}
+		})
+	}
+}
+
+func TestEngineSource(t *testing.T) {
+	engine := newTestEngine(t, fstest.MapFS{
+		"main.glsl": {Data: []byte("/* .Value */")},
+	})
+	if actual := engine.Source("main.glsl", map[string]any{"Value": 5}); actual != "5" {
+		t.Errorf("expected %q, got %q", "5", actual)
+	}
+
+	// The failed invocation is tracked while a directory is watched.
+	engine.runs = make(map[string]templateRun)
+	expected := "#version 460\n#error template \"fixed.glsl\" not found\n"
+	actual := engine.Source("fixed.glsl", nil)
+	if actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+	if _, ok := engine.Regenerate(actual); ok {
+		t.Errorf("expected regeneration to fail")
+	}
+	err := engine.AddTemplates(fstest.MapFS{
+		"fixed.glsl": {Data: []byte("fixed")},
+	})
+	if err != nil {
+		t.Fatalf("failed to add templates: %v", err)
+	}
+	regenerated, ok := engine.Regenerate(actual)
+	if !ok {
+		t.Fatalf("expected regeneration to succeed")
+	}
+	if !strings.Contains(regenerated, "fixed") {
+		t.Errorf("expected %q to contain %q", regenerated, "fixed")
+	}
+}
+
+func newTestEngine(t *testing.T, set fstest.MapFS) *Engine {
+	t.Helper()
+	engine, err := NewEngine(set)
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	return engine
+}
+
+func assertRun(t *testing.T, engine *Engine, name string, data any, expected string) {
+	t.Helper()
+	actual, err := engine.Run(name, data)
+	if err != nil {
+		t.Fatalf("failed to run template: %v", err)
+	}
+	if actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+}
+
+func assertRunContains(t *testing.T, engine *Engine, name, expected string) {
+	t.Helper()
+	actual, err := engine.Run(name, nil)
+	if err != nil {
+		t.Fatalf("failed to run template: %v", err)
+	}
+	if !strings.Contains(actual, expected) {
+		t.Errorf("expected %q to contain %q", actual, expected)
+	}
+}
+
+// counter is template data whose output changes with each execution.
+type counter struct {
+	count int
+}
+
+func (c *counter) Next() int {
+	c.count++
+	return c.count
+}
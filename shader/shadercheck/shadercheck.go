@@ -0,0 +1,164 @@
+// Package shadercheck verifies that the shader variants which the game
+// and UI shader collections can produce compile and link.
+//
+// The variants are built through a headless context. Unless configured
+// otherwise through the environment, Mesa's software rasterizer is used
+// with its OpenGL version raised to 4.6, which is the version that the
+// templates target, so no GPU or display is required.
+//
+// The check can be run from a test:
+//
+//	func TestShaderVariants(t *testing.T) {
+//		shadercheck.Assert(t, shadercheck.GameVariants(game.NewShaderCollection()))
+//	}
+//
+// or through the lacking-gl-shadercheck command.
+package shadercheck
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	glapp "github.com/mokiat/lacking-gl/app"
+	"github.com/mokiat/lacking-gl/render/ext"
+	"github.com/mokiat/lacking/app"
+	"github.com/mokiat/lacking/render"
+)
+
+// softwareEnv holds the environment variables that are set, unless
+// already present, before the headless context is created.
+var softwareEnv = [][2]string{
+	{"LIBGL_ALWAYS_SOFTWARE", "1"},
+	{"MESA_GL_VERSION_OVERRIDE", "4.6"},
+	{"MESA_GLSL_VERSION_OVERRIDE", "460"},
+}
+
+// Failure describes a variant that failed to build.
+type Failure struct {
+
+	// Variant is the variant that failed.
+	Variant Variant
+
+	// Diagnostics holds all messages that were reported by the driver
+	// while building the variant, including warnings.
+	Diagnostics []ext.Diagnostic
+
+	// Err is the compilation or link error.
+	Err error
+}
+
+// String returns the name of the variant followed by its error
+// diagnostics, one per line.
+func (f Failure) String() string {
+	var builder strings.Builder
+	builder.WriteString(f.Variant.Name)
+	var hasErrors bool
+	for _, diagnostic := range f.Diagnostics {
+		if diagnostic.Severity == ext.DiagnosticSeverityError {
+			builder.WriteString("\n\t")
+			builder.WriteString(diagnostic.String())
+			hasErrors = true
+		}
+	}
+	if !hasErrors {
+		builder.WriteString("\n\t")
+		builder.WriteString(strings.ReplaceAll(f.Err.Error(), "\n", "\n\t"))
+	}
+	return builder.String()
+}
+
+// Check builds each of the specified variants in a new headless context
+// and returns the ones that failed. An error is returned only if the check
+// itself could not be performed.
+func Check(variants []Variant) ([]Failure, error) {
+	for _, env := range softwareEnv {
+		if _, ok := os.LookupEnv(env[0]); !ok {
+			os.Setenv(env[0], env[1])
+		}
+	}
+	controller := &checkController{
+		variants: variants,
+	}
+	cfg := glapp.NewConfig("shadercheck", 1, 1)
+	if err := glapp.RunHeadless(cfg, controller); err != nil {
+		return nil, fmt.Errorf("failed to run headless context: %w", err)
+	}
+	if !controller.checked {
+		return nil, errors.New("variants were not checked")
+	}
+	return controller.failures, nil
+}
+
+// T is the subset of testing.TB that is used by Assert.
+type T interface {
+	Helper()
+	Errorf(format string, args ...any)
+	Fatalf(format string, args ...any)
+}
+
+// Assert checks the specified variants and reports each one that fails
+// to build to t, along with its diagnostics.
+func Assert(t T, variants []Variant) {
+	t.Helper()
+	failures, err := Check(variants)
+	if err != nil {
+		t.Fatalf("failed to check shader variants: %v", err)
+		return
+	}
+	for _, failure := range failures {
+		t.Errorf("shader variant failed to build: %s", failure)
+	}
+}
+
+type checkController struct {
+	app.NopController
+	variants []Variant
+	failures []Failure
+	checked  bool
+}
+
+func (c *checkController) OnRender(window app.Window) {
+	defer window.Close()
+
+	api := window.RenderAPI().(ext.API)
+	var diagnostics []ext.Diagnostic
+	var errs []error
+	api.SetDiagnosticsHandler(func(d []ext.Diagnostic, err error) {
+		diagnostics = append(diagnostics, d...)
+		errs = append(errs, err)
+	})
+	defer api.SetDiagnosticsHandler(nil)
+
+	for _, variant := range c.variants {
+		diagnostics, errs = nil, nil
+		buildVariant(api, variant)
+		if err := errors.Join(errs...); err != nil {
+			c.failures = append(c.failures, Failure{
+				Variant:     variant,
+				Diagnostics: diagnostics,
+				Err:         err,
+			})
+		}
+	}
+	c.checked = true
+}
+
+func buildVariant(api ext.API, variant Variant) {
+	vertexShader := api.CreateVertexShader(render.ShaderInfo{
+		SourceCode: variant.VertexShader,
+	})
+	defer vertexShader.Release()
+
+	fragmentShader := api.CreateFragmentShader(render.ShaderInfo{
+		SourceCode: variant.FragmentShader,
+	})
+	defer fragmentShader.Release()
+
+	program := api.CreateProgram(render.ProgramInfo{
+		VertexShader:   vertexShader,
+		FragmentShader: fragmentShader,
+	})
+	defer program.Release()
+}
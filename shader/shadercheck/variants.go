@@ -0,0 +1,98 @@
+package shadercheck
+
+import (
+	"fmt"
+
+	"github.com/mokiat/lacking/game/graphics"
+	"github.com/mokiat/lacking/ui"
+)
+
+// Variant is a single combination of settings of a shader collection,
+// along with the source code that it produces.
+type Variant struct {
+
+	// Name identifies the variant, including its settings.
+	Name string
+
+	// VertexShader is the generated vertex shader source code.
+	VertexShader string
+
+	// FragmentShader is the generated fragment shader source code.
+	FragmentShader string
+}
+
+// GameVariants returns all variants that the specified game shader
+// collection can produce.
+//
+// The directional light variant is produced with shadow mapping, since
+// graphics.ShaderCollection has no setting for it. Collections that can
+// also produce the variant without it need to check that separately.
+func GameVariants(collection graphics.ShaderCollection) []Variant {
+	var result []Variant
+	for _, hasArmature := range []bool{false, true} {
+		cfg := graphics.ShadowMappingShaderConfig{
+			HasArmature: hasArmature,
+		}
+		result = append(result, gameVariant("ShadowMapping", cfg, collection.ShadowMappingSet(cfg)))
+	}
+	for mask := 0; mask < 1<<4; mask++ {
+		cfg := graphics.PBRGeometryShaderConfig{
+			HasArmature:      mask&(1<<0) != 0,
+			HasAlphaTesting:  mask&(1<<1) != 0,
+			HasVertexColors:  mask&(1<<2) != 0,
+			HasAlbedoTexture: mask&(1<<3) != 0,
+		}
+		result = append(result, gameVariant("PBRGeometry", cfg, collection.PBRGeometrySet(cfg)))
+	}
+	result = append(result,
+		gameVariant("DirectionalLight", nil, collection.DirectionalLightSet()),
+		gameVariant("AmbientLight", nil, collection.AmbientLightSet()),
+		gameVariant("PointLight", nil, collection.PointLightSet()),
+		gameVariant("SpotLight", nil, collection.SpotLightSet()),
+		gameVariant("Skybox", nil, collection.SkyboxSet()),
+		gameVariant("Skycolor", nil, collection.SkycolorSet()),
+		gameVariant("Debug", nil, collection.DebugSet()),
+		gameVariant("Exposure", nil, collection.ExposureSet()),
+	)
+	for _, toneMapping := range []graphics.ToneMapping{graphics.ReinhardToneMapping, graphics.ExponentialToneMapping} {
+		cfg := graphics.PostprocessingShaderConfig{
+			ToneMapping: toneMapping,
+		}
+		result = append(result, gameVariant("Postprocessing", cfg, collection.PostprocessingSet(cfg)))
+	}
+	return result
+}
+
+// UIVariants returns all variants that the specified UI shader
+// collection can produce.
+func UIVariants(collection ui.ShaderCollection) []Variant {
+	return []Variant{
+		uiVariant("ShapeShaded", collection.ShapeShadedSet()),
+		uiVariant("ShapeBlank", collection.ShapeBlankSet()),
+		uiVariant("Contour", collection.ContourSet()),
+		uiVariant("Text", collection.TextSet()),
+	}
+}
+
+func gameVariant(name string, cfg any, set graphics.ShaderSet) Variant {
+	return Variant{
+		Name:           variantName("game", name, cfg),
+		VertexShader:   set.VertexShader,
+		FragmentShader: set.FragmentShader,
+	}
+}
+
+func uiVariant(name string, set ui.ShaderSet) Variant {
+	return Variant{
+		Name:           variantName("ui", name, nil),
+		VertexShader:   set.VertexShader,
+		FragmentShader: set.FragmentShader,
+	}
+}
+
+func variantName(collection, name string, cfg any) string {
+	if cfg == nil {
+		return fmt.Sprintf("%s/%s", collection, name)
+	}
+	return fmt.Sprintf("%s/%s%+v", collection, name, cfg)
+}
@@ -0,0 +1,170 @@
+package shader
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mokiat/lacking/log"
+)
+
+const watchInterval = 500 * time.Millisecond
+
+var logger = log.Path("/lacking-gl/shader")
+
+var errAlreadyWatching = errors.New("a directory is already being watched")
+
+// WatchDir makes the Default engine load its templates from the
+// specified directory. See Engine.WatchDir.
+func WatchDir(dir string, onReload func()) (*Watcher, error) {
+	return Default.WatchDir(dir, onReload)
+}
+
+// Regenerate returns the source code that the template invocation which
+// produced the specified source code through the Default engine produces
+// with its current templates. See Engine.Regenerate.
+func Regenerate(source string) (string, bool) {
+	return Default.Regenerate(source)
+}
+
+// WatchDir makes the templates be loaded from the specified directory
+// instead of the ones that are embedded into the binary. Templates that
+// were registered through AddTemplates still override them. The
+// directory is polled for changes and the templates are reloaded
+// whenever a template file is modified, added or removed.
+//
+// The onReload function is called, from a separate goroutine, after each
+// successful reload. The new source code of previously generated shaders
+// can then be obtained through Regenerate. Templates that fail to parse
+// are logged and the previous ones are kept.
+//
+// Only a single directory can be watched at a time.
+func (e *Engine) WatchDir(dir string, onReload func()) (*Watcher, error) {
+	fsys := os.DirFS(dir)
+	stamps, err := stampTemplates(fsys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat templates: %w", err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.watching {
+		return nil, errAlreadyWatching
+	}
+	embedded := e.sets[0]
+	e.sets[0] = fsys
+	if err := e.reload(); err != nil {
+		e.sets[0] = embedded
+		return nil, fmt.Errorf("failed to load templates: %w", err)
+	}
+	e.watching = true
+	e.runs = make(map[string]templateRun)
+
+	watcher := &Watcher{
+		engine:   e,
+		fsys:     fsys,
+		stamps:   stamps,
+		onReload: onReload,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go watcher.run()
+	return watcher, nil
+}
+
+// Watcher watches a directory of templates for changes.
+type Watcher struct {
+	engine   *Engine
+	fsys     fs.FS
+	stamps   map[string]fileStamp
+	onReload func()
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// Close stops watching the directory. The templates that were loaded
+// last remain in use. Calling Close more than once has no effect.
+func (w *Watcher) Close() {
+	w.stopOnce.Do(w.close)
+}
+
+func (w *Watcher) close() {
+	close(w.stop)
+	<-w.done
+
+	w.engine.mu.Lock()
+	defer w.engine.mu.Unlock()
+	w.engine.watching = false
+	w.engine.runs = nil
+}
+
+func (w *Watcher) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			if w.poll() && w.onReload != nil {
+				w.onReload()
+			}
+		}
+	}
+}
+
+// poll reloads the templates if any of the files have changed and
+// returns whether that was successful.
+func (w *Watcher) poll() bool {
+	stamps, err := stampTemplates(w.fsys)
+	if err != nil {
+		logger.Error("Failed to stat shader templates: %v", err)
+		return false
+	}
+	if maps.Equal(stamps, w.stamps) {
+		return false
+	}
+	w.stamps = stamps
+
+	w.engine.mu.Lock()
+	defer w.engine.mu.Unlock()
+	if err := w.engine.reload(); err != nil {
+		logger.Error("Failed to reload shader templates: %v", err)
+		return false
+	}
+	logger.Info("Reloaded shader templates")
+	return true
+}
+
+// fileStamp identifies a version of a file.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+func stampTemplates(fsys fs.FS) (map[string]fileStamp, error) {
+	names, err := fs.Glob(fsys, "*.glsl")
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]fileStamp, len(names))
+	for _, name := range names {
+		info, err := fs.Stat(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		result[name] = fileStamp{
+			modTime: info.ModTime(),
+			size:    info.Size(),
+		}
+	}
+	return result, nil
+}
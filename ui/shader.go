@@ -5,39 +5,56 @@ import (
 	"github.com/mokiat/lacking/ui"
 )
 
+// NewShaderCollection creates a shader collection that uses the
+// templates of the default shader engine.
 func NewShaderCollection() ui.ShaderCollection {
+	return NewEngineShaderCollection(shader.Default)
+}
+
+// NewEngineShaderCollection creates a shader collection that uses the
+// templates of the specified shader engine, which allows templates to be
+// overridden. Templates that fail to execute produce shaders that fail
+// to compile, instead of crashing the application.
+func NewEngineShaderCollection(engine *shader.Engine) ui.ShaderCollection {
+	sets := shaderSets{
+		engine: engine,
+	}
 	return ui.ShaderCollection{
-		ShapeShadedSet: newShadedShapeShaderSet,
-		ShapeBlankSet:  newBlankShapeShaderSet,
-		ContourSet:     newContourShaderSet,
-		TextSet:        newTextShaderSet,
+		ShapeShadedSet: sets.newShadedShapeShaderSet,
+		ShapeBlankSet:  sets.newBlankShapeShaderSet,
+		ContourSet:     sets.newContourShaderSet,
+		TextSet:        sets.newTextShaderSet,
 	}
 }
 
-func newShadedShapeShaderSet() ui.ShaderSet {
+type shaderSets struct {
+	engine *shader.Engine
+}
+
+func (sets shaderSets) newShadedShapeShaderSet() ui.ShaderSet {
 	return ui.ShaderSet{
-		VertexShader:   shader.RunTemplate("shaded_shape.vert.glsl", struct{}{}),
-		FragmentShader: shader.RunTemplate("shaded_shape.frag.glsl", struct{}{}),
+		VertexShader:   sets.engine.Source("shaded_shape.vert.glsl", struct{}{}),
+		FragmentShader: sets.engine.Source("shaded_shape.frag.glsl", struct{}{}),
 	}
 }
 
-func newBlankShapeShaderSet() ui.ShaderSet {
+func (sets shaderSets) newBlankShapeShaderSet() ui.ShaderSet {
 	return ui.ShaderSet{
-		VertexShader:   shader.RunTemplate("blank_shape.vert.glsl", struct{}{}),
-		FragmentShader: shader.RunTemplate("blank_shape.frag.glsl", struct{}{}),
+		VertexShader:   sets.engine.Source("blank_shape.vert.glsl", struct{}{}),
+		FragmentShader: sets.engine.Source("blank_shape.frag.glsl", struct{}{}),
 	}
 }
 
-func newContourShaderSet() ui.ShaderSet {
+func (sets shaderSets) newContourShaderSet() ui.ShaderSet {
 	return ui.ShaderSet{
-		VertexShader:   shader.RunTemplate("contour.vert.glsl", struct{}{}),
-		FragmentShader: shader.RunTemplate("contour.frag.glsl", struct{}{}),
+		VertexShader:   sets.engine.Source("contour.vert.glsl", struct{}{}),
+		FragmentShader: sets.engine.Source("contour.frag.glsl", struct{}{}),
 	}
 }
 
-func newTextShaderSet() ui.ShaderSet {
+func (sets shaderSets) newTextShaderSet() ui.ShaderSet {
 	return ui.ShaderSet{
-		VertexShader:   shader.RunTemplate("text.vert.glsl", struct{}{}),
-		FragmentShader: shader.RunTemplate("text.frag.glsl", struct{}{}),
+		VertexShader:   sets.engine.Source("text.vert.glsl", struct{}{}),
+		FragmentShader: sets.engine.Source("text.frag.glsl", struct{}{}),
 	}
 }
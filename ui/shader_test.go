@@ -0,0 +1,17 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/mokiat/lacking-gl/shader/shadercheck"
+)
+
+func TestShaderVariants(t *testing.T) {
+	failures, err := shadercheck.Check(shadercheck.UIVariants(NewShaderCollection()))
+	if err != nil {
+		t.Skipf("shader variants cannot be checked: %v", err)
+	}
+	for _, failure := range failures {
+		t.Errorf("shader variant failed to build: %s", failure)
+	}
+}